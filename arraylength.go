@@ -0,0 +1,174 @@
+package colorisediff
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithArrayLengthSummaries adds a summary line for every array whose length differs below the colorized output,
+// e.g. `array "animals": length 3 → 4, inserted at index 2`, and records it in the LengthChanges of the diff, so the
+// most important fact about the array is not buried in the differences of its elements. The inserted and removed
+// elements are found by lining up the equal elements of both sides in order.
+func WithArrayLengthSummaries() Option {
+	return func(o *options) {
+		o.lengthSummaries = true
+	}
+}
+
+// LengthChange records an array whose length differs between the documents.
+// Path: The path of the array, in the form used by the entries.
+// Expected, Actual: The lengths of the array on each side.
+// Inserted: The indexes of the actual elements without an equal expected element.
+// Removed: The indexes of the expected elements without an equal actual element.
+type LengthChange struct {
+	Path     string `json:"path"`
+	Expected int    `json:"expected"`
+	Actual   int    `json:"actual"`
+	Inserted []int  `json:"inserted,omitempty"`
+	Removed  []int  `json:"removed,omitempty"`
+}
+
+// String describes the change, e.g. `array "animals": length 3 → 4, inserted at index 2`.
+func (c LengthChange) String() string {
+	text := fmt.Sprintf("array %s: length %d → %d", quotePath(c.Path), c.Expected, c.Actual)
+	if len(c.Inserted) > 0 {
+		text += ", inserted at " + describeIndexes(c.Inserted)
+	}
+	if len(c.Removed) > 0 {
+		text += ", removed at " + describeIndexes(c.Removed)
+	}
+	return text
+}
+
+// describeIndexes lists array indexes, e.g. "index 2" or "indexes 2, 5".
+func describeIndexes(indexes []int) string {
+	if len(indexes) == 1 {
+		return "index " + strconv.Itoa(indexes[0])
+	}
+	listed := make([]string, len(indexes))
+	for i, index := range indexes {
+		listed[i] = strconv.Itoa(index)
+	}
+	return "indexes " + strings.Join(listed, ", ")
+}
+
+// lengthChanges returns the changes of the arrays whose length differs, in the order of their paths, or nil if no
+// summaries are asked for. Noised arrays and arrays whose extra elements are tolerated are left out.
+func (o *options) lengthChanges(expected, actual interface{}, noise map[string][]string) []LengthChange {
+	if !o.lengthSummaries {
+		return nil
+	}
+	var changes []LengthChange
+	var walk func(path, noisePath string, expected, actual interface{})
+	walk = func(path, noisePath string, expected, actual interface{}) {
+		if checkNoise(noisePath, noise) {
+			return
+		}
+		switch e := expected.(type) {
+		case map[string]interface{}:
+			a, ok := actual.(map[string]interface{})
+			if !ok {
+				return
+			}
+			for _, key := range unionKeys(e, nil) {
+				if actualValue, ok := a[key]; ok {
+					walk(joinPath(path, escapePathKey(key)), noisePath+"."+key, e[key], actualValue)
+				}
+			}
+		case []interface{}:
+			a, ok := actual.([]interface{})
+			if !ok {
+				return
+			}
+			tolerated := o.ignoreExtraElements && len(a) > len(e)
+			if len(e) != len(a) && !tolerated {
+				inserted, removed := unmatchedElements(e, a)
+				changes = append(changes, LengthChange{Path: path, Expected: len(e), Actual: len(a), Inserted: inserted, Removed: removed})
+			}
+			for i := 0; i < len(e) && i < len(a); i++ {
+				walk(joinPath(path, strconv.Itoa(i)), noisePath+"["+strconv.Itoa(i)+"]", e[i], a[i])
+			}
+		}
+	}
+	walk("", "", expected, actual)
+	return changes
+}
+
+// unmatchedElements lines up the equal elements of two arrays in order, by their longest common subsequence, and
+// returns the indexes of the actual and expected elements left over. Arrays too long to line up are reported as
+// having their extra elements at the end.
+func unmatchedElements(expected, actual []interface{}) ([]int, []int) {
+	expectedKeys, actualKeys := canonicalKeys(expected), canonicalKeys(actual)
+	start := 0
+	for start < len(expectedKeys) && start < len(actualKeys) && expectedKeys[start] == actualKeys[start] {
+		start++
+	}
+	expectedEnd, actualEnd := len(expectedKeys), len(actualKeys)
+	for expectedEnd > start && actualEnd > start && expectedKeys[expectedEnd-1] == actualKeys[actualEnd-1] {
+		expectedEnd, actualEnd = expectedEnd-1, actualEnd-1
+	}
+	e, a := expectedKeys[start:expectedEnd], actualKeys[start:actualEnd]
+
+	var inserted, removed []int
+	if len(e)*len(a) > maxEditCells {
+		for i := len(expected); i < len(actual); i++ {
+			inserted = append(inserted, i)
+		}
+		for i := len(actual); i < len(expected); i++ {
+			removed = append(removed, i)
+		}
+		return inserted, removed
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of e[i:] and a[j:].
+	lengths := make([][]int, len(e)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(a)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(a) - 1; j >= 0; j-- {
+			if e[i] == a[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(e) || j < len(a) {
+		switch {
+		case i < len(e) && j < len(a) && e[i] == a[j]:
+			i, j = i+1, j+1
+		case j < len(a) && (i == len(e) || lengths[i][j+1] >= lengths[i+1][j]):
+			inserted = append(inserted, start+j)
+			j++
+		default:
+			removed = append(removed, start+i)
+			i++
+		}
+	}
+	return inserted, removed
+}
+
+// canonicalKeys returns the canonical forms of the elements of an array, for comparing them by equality.
+func canonicalKeys(elements []interface{}) []string {
+	keys := make([]string, len(elements))
+	for i, element := range elements {
+		var buffer bytes.Buffer
+		// Decoded JSON values always have a canonical form.
+		_ = writeCanonical(&buffer, element)
+		keys[i] = buffer.String()
+	}
+	return keys
+}
+
+// renderLengthChanges renders the length changes as lines to be appended below a rendered side.
+func renderLengthChanges(changes []LengthChange) string {
+	var builder strings.Builder
+	for _, change := range changes {
+		builder.WriteString(breakLines(change.String()) + "\n")
+	}
+	return builder.String()
+}
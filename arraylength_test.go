@@ -0,0 +1,65 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmatchedElements(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual []interface{}
+		wantInserted     []int
+		wantRemoved      []int
+	}{
+		{name: "inserted", expected: []interface{}{"cat", "dog", "owl"}, actual: []interface{}{"cat", "dog", "eel", "owl"}, wantInserted: []int{2}},
+		{name: "removed", expected: []interface{}{"cat", "dog", "owl"}, actual: []interface{}{"dog", "owl"}, wantRemoved: []int{0}},
+		{name: "appended", expected: []interface{}{1.0}, actual: []interface{}{1.0, 2.0, 3.0}, wantInserted: []int{1, 2}},
+		{name: "changed and inserted", expected: []interface{}{"a", "b"}, actual: []interface{}{"x", "b", "c"}, wantInserted: []int{0, 2}, wantRemoved: []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inserted, removed := unmatchedElements(tt.expected, tt.actual)
+			if !reflect.DeepEqual(inserted, tt.wantInserted) || !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("unmatchedElements() = %v, %v, want %v, %v", inserted, removed, tt.wantInserted, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestWithArrayLengthSummaries(t *testing.T) {
+	expected := []byte(`{"zoo":{"animals":["cat","dog","owl"]},"tags":["a"],"ids":[1]}`)
+	actual := []byte(`{"zoo":{"animals":["cat","dog","eel","owl"]},"tags":["a","b"],"ids":[1,2]}`)
+
+	resp, err := CompareJSON(expected, actual, map[string][]string{"ids": {}}, true, WithArrayLengthSummaries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LengthChange{
+		{Path: "tags", Expected: 1, Actual: 2, Inserted: []int{1}},
+		{Path: "zoo.animals", Expected: 3, Actual: 4, Inserted: []int{2}},
+	}
+	if !reflect.DeepEqual(resp.LengthChanges, want) {
+		t.Errorf("LengthChanges = %+v, want %+v", resp.LengthChanges, want)
+	}
+	line := `array "zoo.animals": length 3 → 4, inserted at index 2`
+	for _, side := range []string{resp.Expected, resp.Actual} {
+		if !strings.Contains(strings.ReplaceAll(side, "\n", ""), line) {
+			t.Errorf("expected %q in:\n%s", line, side)
+		}
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored Diff
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.LengthChanges, want) {
+		t.Errorf("restored LengthChanges = %+v, want %+v", restored.LengthChanges, want)
+	}
+}
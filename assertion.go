@@ -0,0 +1,284 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// assertionOperators lists the comparison operators of assertions, longer operators first so "<=" is not read as "<".
+var assertionOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// WithAssertions evaluates assertion expressions against the actual document during the comparison, e.g.
+// WithAssertions("total >= 0", "len(items) == count", `status != "failed"`), turning the comparison into a
+// lightweight response validator. An expression compares two operands with one of ==, !=, <, <=, > or >=. Operands
+// are gjson-style paths into the actual document, len(path) for the number of elements, members or characters of
+// the value at a path, or JSON literals such as 0, "failed", true or null. Failed assertions are recorded in the
+// AssertionFailures of the diff and listed below the colorized output; expressions that cannot be parsed make the
+// comparison fail.
+func WithAssertions(expressions ...string) Option {
+	return func(o *options) {
+		o.assertions = append(o.assertions, expressions...)
+	}
+}
+
+// AssertionFailure records an assertion given with WithAssertions that does not hold for the actual document.
+// Expression: The assertion, e.g. "len(items) == count".
+// Reason: The values the assertion was evaluated with, or why it could not be, e.g. "len(items) is 3, count is 2".
+type AssertionFailure struct {
+	Expression string `json:"expression"`
+	Reason     string `json:"reason"`
+}
+
+// String describes the failure, e.g. "assertion failed: len(items) == count (len(items) is 3, count is 2)".
+func (f AssertionFailure) String() string {
+	return fmt.Sprintf("assertion failed: %s (%s)", f.Expression, f.Reason)
+}
+
+// assertion is a parsed assertion expression.
+type assertion struct {
+	expression  string
+	left, right assertionOperand
+	operator    string
+}
+
+// assertionOperand is one side of an assertion: a literal, the value at a path or the length of that value.
+type assertionOperand struct {
+	text    string      // text is the operand as written, used in the reasons of failures.
+	path    string      // path is the gjson-style path of the value, empty for literals.
+	length  bool        // length takes the length of the value at the path instead of the value.
+	literal interface{} // literal is the decoded value of a literal operand.
+}
+
+// failedAssertions evaluates the assertions given with WithAssertions against the actual document and returns the
+// failures in the order of the assertions. The reasons show redacted values as <redacted>.
+func (o *options) failedAssertions(actual interface{}) ([]AssertionFailure, error) {
+	var failures []AssertionFailure
+	for _, expression := range o.assertions {
+		a, err := parseAssertion(expression)
+		if err != nil {
+			return nil, err
+		}
+		if reason, ok := a.evaluate(actual, o.valueRenderers.redacted); !ok {
+			failures = append(failures, AssertionFailure{Expression: a.expression, Reason: reason})
+		}
+	}
+	return failures, nil
+}
+
+// parseAssertion parses an assertion expression.
+func parseAssertion(expression string) (assertion, error) {
+	expression = strings.TrimSpace(expression)
+	at, operator := findOperator(expression)
+	if at < 0 {
+		return assertion{}, fmt.Errorf("assertion %q: expected a comparison such as \"total >= 0\"", expression)
+	}
+	left, err := parseOperand(expression[:at])
+	if err != nil {
+		return assertion{}, fmt.Errorf("assertion %q: %w", expression, err)
+	}
+	right, err := parseOperand(expression[at+len(operator):])
+	if err != nil {
+		return assertion{}, fmt.Errorf("assertion %q: %w", expression, err)
+	}
+	return assertion{expression: expression, left: left, right: right, operator: operator}, nil
+}
+
+// findOperator returns the position and text of the first comparison operator outside string literals, or -1.
+func findOperator(expression string) (int, string) {
+	quoted := false
+	for i := 0; i < len(expression); i++ {
+		switch {
+		case quoted && expression[i] == '\\':
+			i++
+		case expression[i] == '"':
+			quoted = !quoted
+		case !quoted:
+			for _, operator := range assertionOperators {
+				if strings.HasPrefix(expression[i:], operator) {
+					return i, operator
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+// parseOperand parses one side of an assertion.
+func parseOperand(text string) (assertionOperand, error) {
+	text = strings.TrimSpace(text)
+	operand := assertionOperand{text: text}
+	switch {
+	case text == "":
+		return operand, fmt.Errorf("missing operand")
+	case strings.HasPrefix(text, "len(") && strings.HasSuffix(text, ")"):
+		operand.path = strings.TrimSpace(text[len("len(") : len(text)-1])
+		operand.length = true
+		if operand.path == "" {
+			return operand, fmt.Errorf("missing path in %s", text)
+		}
+	case text == "true" || text == "false" || text == "null" || strings.HasPrefix(text, `"`) || isNumberLiteral(text):
+		if err := json.Unmarshal([]byte(text), &operand.literal); err != nil {
+			return operand, fmt.Errorf("invalid literal %s", text)
+		}
+	default:
+		operand.path = text
+	}
+	return operand, nil
+}
+
+// isNumberLiteral reports whether text is a number rather than a path, e.g. "-1.5" but not "items.0".
+func isNumberLiteral(text string) bool {
+	if text[0] != '-' && (text[0] < '0' || text[0] > '9') {
+		return false
+	}
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
+
+// evaluate evaluates the assertion against the actual document. It returns the reason of the failure and false if
+// the assertion does not hold. The reason shows the values for which redacted returns true as <redacted>.
+func (a assertion) evaluate(actual interface{}, redacted func(path string, value interface{}) bool) (string, bool) {
+	left, err := a.left.value(actual, redacted)
+	if err != nil {
+		return err.Error(), false
+	}
+	right, err := a.right.value(actual, redacted)
+	if err != nil {
+		return err.Error(), false
+	}
+	holds, err := compareOperands(left, right, a.operator)
+	if err != nil {
+		return err.Error(), false
+	}
+	if holds {
+		return "", true
+	}
+	var described []string
+	for _, operand := range []struct {
+		assertionOperand
+		value interface{}
+	}{{a.left, left}, {a.right, right}} {
+		if operand.path != "" {
+			described = append(described, fmt.Sprintf("%s is %s", operand.text, operand.describe(operand.value, redacted)))
+		}
+	}
+	return strings.Join(described, ", "), false
+}
+
+// describe formats the value of the operand for the reason of a failure, as <redacted> if the value at its path is
+// redacted. Lengths are shown as they are.
+func (o assertionOperand) describe(value interface{}, redacted func(path string, value interface{}) bool) string {
+	if !o.length && redacted(o.path, value) {
+		return redactedValue
+	}
+	return formatValue(value)
+}
+
+// value returns the value of the operand in the actual document.
+func (o assertionOperand) value(actual interface{}, redacted func(path string, value interface{}) bool) (interface{}, error) {
+	if o.path == "" {
+		return o.literal, nil
+	}
+	value, ok := lookupPath(actual, o.path)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", o.path)
+	}
+	if !o.length {
+		return value, nil
+	}
+	length, ok := valueLength(value)
+	if !ok {
+		return nil, fmt.Errorf("%s has no length, it is %s", o.path, assertionOperand{path: o.path}.describe(value, redacted))
+	}
+	return length, nil
+}
+
+// valueLength returns the number of elements of an array, members of an object or characters of a string.
+func valueLength(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return float64(len(v)), true
+	case map[string]interface{}:
+		return float64(len(v)), true
+	case string:
+		return float64(utf8.RuneCountInString(v)), true
+	}
+	return 0, false
+}
+
+// compareOperands applies a comparison operator to two values. Equality compares any values; the other operators
+// compare two numbers or two strings.
+func compareOperands(left, right interface{}, operator string) (bool, error) {
+	switch operator {
+	case "==":
+		return canonicalEqual(left, right), nil
+	case "!=":
+		return !canonicalEqual(left, right), nil
+	}
+	var order int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number with %s", jsonTypeName(right))
+		}
+		if l < r {
+			order = -1
+		} else if l > r {
+			order = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare string with %s", jsonTypeName(right))
+		}
+		order = strings.Compare(l, r)
+	default:
+		return false, fmt.Errorf("cannot order %s values", jsonTypeName(left))
+	}
+	switch operator {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	}
+	return order >= 0, nil
+}
+
+// lookupPath returns the value at a gjson-style path of a decoded document.
+func lookupPath(root interface{}, path string) (interface{}, bool) {
+	current := root
+	for _, component := range splitPath(path) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			child, ok := v[component]
+			if !ok {
+				return nil, false
+			}
+			current = child
+		case []interface{}:
+			i, err := strconv.Atoi(component)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// renderAssertionFailures renders the failed assertions as lines to be appended below a rendered side.
+func renderAssertionFailures(failures []AssertionFailure) string {
+	var builder strings.Builder
+	for _, failure := range failures {
+		builder.WriteString(breakLines(failure.String()) + "\n")
+	}
+	return builder.String()
+}
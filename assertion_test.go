@@ -0,0 +1,97 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithAssertions(t *testing.T) {
+	actual := `{"total":-1,"count":2,"items":[{"id":1},{"id":2},{"id":3}],"status":"ok","name":"Dog"}`
+	tests := []struct {
+		expression string
+		reason     string
+	}{
+		{expression: "count >= 0"},
+		{expression: "total >= 0", reason: "total is -1"},
+		{expression: "len(items) == count", reason: "len(items) is 3, count is 2"},
+		{expression: "len(items) > 2"},
+		{expression: "items.1.id == 2"},
+		{expression: `status != "failed"`},
+		{expression: `status == "a<b"`, reason: `status is "ok"`},
+		{expression: "len(name) == 3"},
+		{expression: "missing == 1", reason: "missing not found"},
+		{expression: "len(total) == 1", reason: "total has no length, it is -1"},
+		{expression: "status < 1", reason: "cannot compare string with number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(actual), []byte(actual), nil, true, WithAssertions(tt.expression))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []AssertionFailure
+			if tt.reason != "" {
+				want = []AssertionFailure{{Expression: tt.expression, Reason: tt.reason}}
+			}
+			if !reflect.DeepEqual(resp.AssertionFailures, want) {
+				t.Errorf("got failures %+v, want %+v", resp.AssertionFailures, want)
+			}
+			if tt.reason != "" && !strings.Contains(resp.Actual, "assertion failed: "+tt.expression) {
+				t.Errorf("expected the failure in the output, got:\n%s", resp.Actual)
+			}
+		})
+	}
+}
+
+func TestWithAssertionsInvalid(t *testing.T) {
+	for _, expression := range []string{"total", "== 1", "len() == 1", `status == "open`} {
+		_, err := CompareJSON([]byte(`{}`), []byte(`{}`), nil, true, WithAssertions(expression))
+		if err == nil {
+			t.Errorf("expected an error for %q", expression)
+		}
+	}
+}
+
+func TestAssertionFailuresRoundTrip(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"a":1}`), []byte(`{"a":2}`), nil, true, WithAssertions("a <= 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"assertionFailures":[{"expression":"a \u003c= 1","reason":"a is 2"}]`) {
+		t.Errorf("expected the failures in the serialization, got %s", data)
+	}
+	var decoded Diff
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.AssertionFailures, resp.AssertionFailures) {
+		t.Errorf("got %+v after a round trip, want %+v", decoded.AssertionFailures, resp.AssertionFailures)
+	}
+}
+
+func TestWithAssertionsRedacted(t *testing.T) {
+	data := []byte(`{"pw":"hunter3secret","token":{"value":"abc123secret"}}`)
+	resp, err := CompareJSON(data, data, nil, true,
+		WithRedact("pw", "token"),
+		WithAssertions(`pw == "other"`, "len(pw) > 20", "len(token.value) > 20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.AssertionFailures) != 3 {
+		t.Fatalf("got failures %+v", resp.AssertionFailures)
+	}
+	if got := resp.AssertionFailures[0].Reason; got != "pw is <redacted>" {
+		t.Errorf("unexpected reason %q", got)
+	}
+	for _, output := range []string{resp.Expected, resp.Actual, resp.AssertionFailures[1].Reason} {
+		if strings.Contains(output, "secret") {
+			t.Errorf("output leaks a redacted value:\n%s", output)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// batchSummaryVersion is the version of the JSON schema written by BatchSummary.WriteJSON.
+const batchSummaryVersion = 1
+
+// Statuses of the files of a batch.
+const (
+	BatchPassed = "passed"
+	BatchFailed = "failed"
+	BatchError  = "error"
+)
+
+// FilePair names an expected and an actual JSON file compared by CompareBatch.
+type FilePair struct {
+	Name     string
+	Expected string
+	Actual   string
+}
+
+// BatchFileResult is the outcome of comparing one file pair of a batch.
+// Status: BatchPassed if the diff is OK, BatchFailed if it has differences that are not noised, failed
+// assertions or inconsistencies, BatchError if the files could not be read or compared.
+// Diff: The diff of the files, for rendering it alongside the summary. It is not part of the JSON summary.
+type BatchFileResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Stats     Stats  `json:"stats"`
+	Error     string `json:"error,omitempty"`
+	ElapsedMS int64  `json:"elapsedMs"`
+	Diff      Diff   `json:"-"`
+}
+
+// BatchSummary is the machine-readable summary of a batch of comparisons, for pipelines gating on structured results
+// rather than on the rendered diffs.
+type BatchSummary struct {
+	Version   int               `json:"version"`
+	Total     int               `json:"total"`
+	Passed    int               `json:"passed"`
+	Failed    int               `json:"failed"`
+	Errors    int               `json:"errors"`
+	ElapsedMS int64             `json:"elapsedMs"`
+	Noise     []string          `json:"noise"`
+	Files     []BatchFileResult `json:"files"`
+}
+
+// CompareBatch compares the expected and actual JSON files of every pair with CompareJSON and summarizes the
+// outcome. A pair whose files cannot be read or compared is reported with the BatchError status; the remaining pairs
+// are compared regardless. The summary lists the noise rules in effect, including those added by the options.
+func CompareBatch(pairs []FilePair, noise map[string][]string, disableColor bool, opts ...Option) BatchSummary {
+	start := time.Now()
+	o := newOptions(opts)
+	summary := BatchSummary{Version: batchSummaryVersion, Total: len(pairs), Noise: []string{}, Files: []BatchFileResult{}}
+	for key := range o.mergeNoise(noise) {
+		summary.Noise = append(summary.Noise, key)
+	}
+	sort.Strings(summary.Noise)
+
+	for _, pair := range pairs {
+		result := comparePair(pair, noise, disableColor, opts)
+		switch result.Status {
+		case BatchPassed:
+			summary.Passed++
+		case BatchFailed:
+			summary.Failed++
+		default:
+			summary.Errors++
+		}
+		summary.Files = append(summary.Files, result)
+	}
+	summary.ElapsedMS = time.Since(start).Milliseconds()
+	return summary
+}
+
+// comparePair compares the files of a single pair of a batch.
+func comparePair(pair FilePair, noise map[string][]string, disableColor bool, opts []Option) BatchFileResult {
+	start := time.Now()
+	result := BatchFileResult{Name: pair.Name}
+	if result.Name == "" {
+		result.Name = pair.Expected
+	}
+	fail := func(err error) BatchFileResult {
+		result.Status = BatchError
+		result.Error = err.Error()
+		result.ElapsedMS = time.Since(start).Milliseconds()
+		return result
+	}
+
+	diff, err := compareFiles(pair, noise, disableColor, opts)
+	if err != nil {
+		return fail(err)
+	}
+	result.Diff = diff
+	result.Stats = diff.Stats()
+	result.Status = BatchPassed
+	if result.Stats.Failed() {
+		result.Status = BatchFailed
+	}
+	result.ElapsedMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// OK reports whether every file of the batch passed.
+func (s BatchSummary) OK() bool {
+	return s.Failed == 0 && s.Errors == 0
+}
+
+// Results returns the diffs of the compared files by name, e.g. for RenderTAP. Files that could not be compared are
+// left out.
+func (s BatchSummary) Results() []NamedDiff {
+	results := make([]NamedDiff, 0, len(s.Files))
+	for _, file := range s.Files {
+		if file.Status != BatchError {
+			results = append(results, NamedDiff{Name: file.Name, Diff: file.Diff})
+		}
+	}
+	return results
+}
+
+// WriteJSON writes the summary as a single indented JSON object, e.g. to a dedicated stream such as os.Stderr.
+func (s BatchSummary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteFile writes the JSON summary to the named file, replacing it if it exists.
+func (s BatchSummary) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,76 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompareBatch(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.expected.json":  `{"name":"Cat","ts":1}`,
+		"users.actual.json":    `{"name":"Cat","ts":2}`,
+		"orders.expected.json": `{"id":1}`,
+		"orders.actual.json":   `{"id":2}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pairs := []FilePair{
+		{Name: "users", Expected: filepath.Join(dir, "users.expected.json"), Actual: filepath.Join(dir, "users.actual.json")},
+		{Name: "orders", Expected: filepath.Join(dir, "orders.expected.json"), Actual: filepath.Join(dir, "orders.actual.json")},
+		{Name: "missing", Expected: filepath.Join(dir, "missing.json"), Actual: filepath.Join(dir, "orders.actual.json")},
+	}
+
+	summary := CompareBatch(pairs, map[string][]string{"ts": {}}, true)
+	if summary.OK() || summary.Total != 3 || summary.Passed != 1 || summary.Failed != 1 || summary.Errors != 1 {
+		t.Errorf("unexpected counts %+v", summary)
+	}
+	var statuses []string
+	for _, file := range summary.Files {
+		statuses = append(statuses, file.Status)
+	}
+	if want := []string{BatchPassed, BatchFailed, BatchError}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("got statuses %v, want %v", statuses, want)
+	}
+	if !reflect.DeepEqual(summary.Noise, []string{"ts"}) {
+		t.Errorf("unexpected noise %v", summary.Noise)
+	}
+	// Failed assertions fail a file without differences.
+	asserted := CompareBatch(pairs[:1], map[string][]string{"ts": {}}, true, WithAssertions("ts > 5"))
+	if asserted.OK() || asserted.Failed != 1 || asserted.Files[0].Stats.AssertionFailures != 1 || asserted.Files[0].Diff.OK() {
+		t.Errorf("expected the failed assertion to fail the file, got %+v", asserted)
+	}
+	checked := CompareBatch(pairs[:1], map[string][]string{"ts": {}}, true, WithConsistencyChecks(ValuesMatch("name", "ts")))
+	if checked.OK() || checked.Failed != 1 || checked.Files[0].Stats.Inconsistencies != 1 || checked.Files[0].Diff.OK() {
+		t.Errorf("expected the inconsistency to fail the file, got %+v", checked)
+	}
+
+	if results := summary.Results(); len(results) != 2 || results[1].Diff.Stats().Changed != 1 {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	path := filepath.Join(dir, "summary.json")
+	if err := summary.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["version"] != 1.0 || decoded["failed"] != 1.0 || len(decoded["files"].([]interface{})) != 3 {
+		t.Errorf("unexpected summary:\n%s", data)
+	}
+	if _, ok := decoded["files"].([]interface{})[0].(map[string]interface{})["Diff"]; ok {
+		t.Errorf("the diff should not be part of the summary:\n%s", data)
+	}
+}
@@ -0,0 +1,74 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+const (
+	// firstStrongIsolate starts a run of text laid out on its own, in the direction of its first strong character.
+	firstStrongIsolate = "\u2068"
+	// popDirectionalIsolate ends the run started by firstStrongIsolate.
+	popDirectionalIsolate = "\u2069"
+)
+
+// isBidiControl reports whether a character is an invisible bidi formatting character: a mark, an embedding, an
+// override or an isolate.
+func isBidiControl(char rune) bool {
+	switch {
+	case char == '\u061c', char == '\u200e', char == '\u200f':
+		return true
+	case char >= '\u202a' && char <= '\u202e':
+		return true
+	case char >= '\u2066' && char <= '\u2069':
+		return true
+	}
+	return false
+}
+
+// isRightToLeft reports whether a character is a letter of a right-to-left script, e.g. Hebrew or Arabic.
+func isRightToLeft(char rune) bool {
+	properties, _ := bidi.LookupRune(char)
+	return properties.Class() == bidi.R || properties.Class() == bidi.AL
+}
+
+// isolateBidi makes a rendered value safe to show next to other text. Bidi formatting characters in the value are
+// shown as \u escapes, so they cannot reorder the text around them, and every line holding right-to-left text is
+// wrapped in a bidi isolate, so it cannot pull the punctuation or the other column of a table into its direction.
+// The indentation of a line is left outside the isolate. Values without either are returned as they are.
+func isolateBidi(text string) string {
+	if !strings.ContainsFunc(text, func(char rune) bool { return isBidiControl(char) || isRightToLeft(char) }) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var escaped strings.Builder
+		for _, char := range line {
+			if isBidiControl(char) {
+				escaped.WriteString(fmt.Sprintf(`\u%04x`, char))
+			} else {
+				escaped.WriteRune(char)
+			}
+		}
+		line = escaped.String()
+		if strings.ContainsFunc(line, isRightToLeft) {
+			content := strings.TrimLeft(line, " \t")
+			line = line[:len(line)-len(content)] + firstStrongIsolate + content + popDirectionalIsolate
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripIsolate returns a value rendered by isolateBidi without its surrounding isolate, and the function adding it
+// back.
+func stripIsolate(text string) (string, func(string) string) {
+	if strings.HasPrefix(text, firstStrongIsolate) && strings.HasSuffix(text, popDirectionalIsolate) {
+		return text[len(firstStrongIsolate) : len(text)-len(popDirectionalIsolate)], func(s string) string {
+			return firstStrongIsolate + s + popDirectionalIsolate
+		}
+	}
+	return text, func(s string) string { return s }
+}
@@ -0,0 +1,54 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsolateBidi(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "left-to-right", text: `"hello"`, want: `"hello"`},
+		{name: "right-to-left", text: `"שלום"`, want: firstStrongIsolate + `"שלום"` + popDirectionalIsolate},
+		{name: "indentation stays outside", text: "{\n  \"name\": \"مرحبا\"\n}", want: "{\n  " + firstStrongIsolate + `"name": "مرحبا"` + popDirectionalIsolate + "\n}"},
+		{name: "controls are escaped", text: "\"abc\u202edef\"", want: `"abc\u202edef"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isolateBidi(tt.text); got != tt.want {
+				t.Errorf("isolateBidi(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareJSONIsolatesRightToLeftValues(t *testing.T) {
+	expected := []byte(`{"greeting":"שלום עולם","note":"a"}`)
+	actual := []byte("{\"greeting\":\"שלום חבר\",\"note\":\"a\u202eb\"}")
+
+	resp, err := CompareJSON(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range []string{resp.Expected, resp.Actual} {
+		if strings.Count(side, firstStrongIsolate) != 1 || strings.Count(side, popDirectionalIsolate) != 1 {
+			t.Errorf("expected the right-to-left value to be isolated, got %q", side)
+		}
+	}
+	if strings.ContainsRune(resp.Actual, '\u202e') || !strings.Contains(resp.Actual, `\u202e`) {
+		t.Errorf("expected the override to be escaped, got %q", resp.Actual)
+	}
+	if resp.Entries[0].Actual != "שלום חבר" {
+		t.Errorf("expected the entries to keep the raw value, got %q", resp.Entries[0].Actual)
+	}
+}
+
+func TestWrapANSIIgnoresBidiControls(t *testing.T) {
+	text := firstStrongIsolate + "abcd" + popDirectionalIsolate
+	if got := WrapANSI(text, 4); got != text {
+		t.Errorf("WrapANSI(%q, 4) = %q, want the text unchanged", text, got)
+	}
+}
@@ -0,0 +1,77 @@
+package colorisediff
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+const (
+	// binaryMinLength is the length from which strings with invalid UTF-8 or control characters are shown as binary.
+	binaryMinLength = 64
+	// entropyMinLength is the length from which strings with a high entropy are shown as binary.
+	entropyMinLength = 256
+	// binaryEntropy is the Shannon entropy in bits per byte above which long strings are taken to be encoded
+	// binary data, e.g. base64 with about 6 bits per byte, while natural language stays around 4.
+	binaryEntropy = 5.0
+)
+
+// WithFullBinary shows binary-looking string values in full in the colorized output. By default, strings holding
+// invalid UTF-8 or control characters and long strings of high entropy, such as base64-encoded images, are shown
+// as a summary like <binary, 14.2KB, sha256:1f2e3d4c…>. They are compared in full either way.
+func WithFullBinary() Option {
+	return func(o *options) {
+		o.valueRenderers.fullBinary = true
+	}
+}
+
+// renderBinary returns the summary of a binary-looking string value, unless full output is requested.
+func (r valueRenderers) renderBinary(value interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok || r.fullBinary || !isBinaryString(s) {
+		return "", false
+	}
+	digest := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("<binary, %s, sha256:%x%s>", formatSize(len(s)), digest[:4], r.markers.ellipsis()), true
+}
+
+// isBinaryString reports whether a string looks like binary data rather than text.
+func isBinaryString(s string) bool {
+	if len(s) < binaryMinLength {
+		return false
+	}
+	for _, char := range s {
+		if char == utf8.RuneError || (char < 0x20 && char != '\t' && char != '\n' && char != '\r') {
+			return true
+		}
+	}
+	return len(s) >= entropyMinLength && shannonEntropy(s) > binaryEntropy
+}
+
+// shannonEntropy returns the entropy of the bytes of a string in bits per byte.
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// formatSize formats a number of bytes for display, e.g. "512B" or "14.2KB".
+func formatSize(size int) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%dB", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+}
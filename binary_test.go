@@ -0,0 +1,65 @@
+package colorisediff
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestIsBinaryString(t *testing.T) {
+	random := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(random)
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{name: "base64", s: base64.StdEncoding.EncodeToString(random), want: true},
+		{name: "control characters", s: strings.Repeat("\x00\x01ab", 20), want: true},
+		{name: "invalid utf-8", s: strings.Repeat("ab�", 30), want: true},
+		{name: "short base64", s: base64.StdEncoding.EncodeToString(random[:60]), want: false},
+		{name: "prose", s: strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), want: false},
+		{name: "short control characters", s: "\x00\x01", want: false},
+	}
+	for _, tt := range tests {
+		if got := isBinaryString(tt.s); got != tt.want {
+			t.Errorf("%s: isBinaryString() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryValues(t *testing.T) {
+	random := make([]byte, 10*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+	image := base64.StdEncoding.EncodeToString(random)
+	expected := []byte(`{"image":"` + image + `"}`)
+	actual := []byte(`{"image":"` + image[:len(image)-4] + `AAAA"}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Expected, "<binary, 13.3KB, sha256:") || strings.Contains(resp.Expected, image[:100]) {
+		t.Errorf("expected a binary summary, got:\n%s", resp.Expected)
+	}
+	if entry := resp.At("image"); entry == nil || entry.Expected != image {
+		t.Errorf("expected the entry to keep the full value, got %+v", entry)
+	}
+
+	resp, err = CompareJSON(expected, actual, nil, true, WithFullBinary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(resp.Expected, "<binary") {
+		t.Errorf("expected the full value with WithFullBinary, got:\n%s", resp.Expected[:200])
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int]string{512: "512B", 14540: "14.2KB", 3 << 20: "3.0MB"} {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package colorisediff
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ResultCache stores the results of comparisons by a key derived from their inputs, for WithResultCache.
+// Implementations must be safe for concurrent use when comparisons run concurrently.
+type ResultCache interface {
+	// Get returns the diff stored under the key, if any.
+	Get(key string) (Diff, bool)
+	// Put stores a diff under the key.
+	Put(key string, diff Diff)
+}
+
+// WithResultCache makes CompareJSON look its result up in the cache before comparing, and store it there after, so
+// repeated comparisons of the same documents, e.g. when re-running a test suite, return instantly. The key hashes
+// the documents, the noise, the color setting, the scope and the values of the other options. Options holding
+// functions, e.g. value renderers, string normalizers or redaction rules, cannot be told apart by their values, so
+// comparisons using them are neither looked up nor cached, and neither are comparisons whose result depends on when
+// they run: with waivers that expire or with the metadata header of WithMetadataHeader, which shows the time. Comparisons that fail are not cached, and the WithOnDiff
+// hook is not called for results taken from the cache.
+func WithResultCache(cache ResultCache, scope string) Option {
+	return func(o *options) {
+		o.cache = cache
+		o.cacheScope = scope
+	}
+}
+
+// cacheKey derives the key of a comparison from its inputs. It reports false if the options hold functions, whose
+// behavior the key cannot capture, or make the result depend on the time of the comparison.
+func (o *options) cacheKey(expectedJSON, actualJSON []byte, noise map[string][]string) (string, bool) {
+	if o.metadataHeader {
+		return "", false
+	}
+	for _, waiver := range o.waivers {
+		if !waiver.Expires.IsZero() {
+			return "", false
+		}
+	}
+	hash := sha256.New()
+	writeField := func(field []byte) {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		hash.Write(length[:])
+		hash.Write(field)
+	}
+	writeField([]byte(o.cacheScope))
+	writeField(expectedJSON)
+	writeField(actualJSON)
+	if o.palette.noColor {
+		writeField([]byte("nocolor"))
+	} else {
+		writeField([]byte("color"))
+	}
+
+	// The noise rules are hashed in sorted order.
+	noise = o.mergeNoise(noise)
+	keys := make([]string, 0, len(noise))
+	for key := range noise {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeField([]byte(key))
+		values := append([]string(nil), noise[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			writeField([]byte(value))
+		}
+		hash.Write([]byte{0})
+	}
+
+	// The options are hashed field by field, leaving out the ones that do not change the result.
+	value := reflect.ValueOf(*o)
+	for i := 0; i < value.NumField(); i++ {
+		switch value.Type().Field(i).Name {
+		case "cache", "cacheScope", "onDiff", "metrics", "noise", "defaultNoise", "palette":
+			continue
+		}
+		writeField([]byte(value.Type().Field(i).Name))
+		if !fingerprintValue(writeField, value.Field(i)) {
+			return "", false
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// fingerprintValue writes the content of a value with writeField, following pointers and sorting map keys. It
+// reports false if the value holds a function or channel, which cannot be fingerprinted.
+func fingerprintValue(writeField func([]byte), value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		writeField(nil)
+	case reflect.Bool:
+		writeField([]byte(strconv.FormatBool(value.Bool())))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeField([]byte(strconv.FormatInt(value.Int(), 10)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeField([]byte(strconv.FormatUint(value.Uint(), 10)))
+	case reflect.Float32, reflect.Float64:
+		writeField([]byte(strconv.FormatFloat(value.Float(), 'g', -1, 64)))
+	case reflect.String:
+		writeField([]byte(value.String()))
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			writeField(nil)
+			return true
+		}
+		writeField([]byte(value.Elem().Type().String()))
+		return fingerprintValue(writeField, value.Elem())
+	case reflect.Slice, reflect.Array:
+		writeField([]byte(strconv.Itoa(value.Len())))
+		for i := 0; i < value.Len(); i++ {
+			if !fingerprintValue(writeField, value.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		writeField([]byte(strconv.Itoa(len(keys))))
+		for _, key := range keys {
+			if !fingerprintValue(writeField, key) || !fingerprintValue(writeField, value.MapIndex(key)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !fingerprintValue(writeField, value.Field(i)) {
+				return false
+			}
+		}
+	default:
+		// Functions, channels and unsafe pointers have no content to hash; nil ones change nothing.
+		if value.IsNil() {
+			writeField(nil)
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// memoryCache is a ResultCache holding the most recently used results in memory.
+type memoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // order holds the keys, most recently used first.
+	entries  map[string]*list.Element // entries maps the keys to their elements of order.
+}
+
+// memoryCacheEntry is an element of the usage order of a memoryCache.
+type memoryCacheEntry struct {
+	key  string
+	diff Diff
+}
+
+// NewMemoryCache returns a ResultCache keeping up to capacity results in memory, dropping the least recently used
+// ones first. A capacity of 0 or less keeps every result.
+func NewMemoryCache(capacity int) ResultCache {
+	return &memoryCache{capacity: capacity, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Get implements ResultCache.
+func (c *memoryCache) Get(key string) (Diff, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return Diff{}, false
+	}
+	c.order.MoveToFront(element)
+	return copyDiff(element.Value.(*memoryCacheEntry).diff), true
+}
+
+// Put implements ResultCache.
+func (c *memoryCache) Put(key string, diff Diff) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	diff = copyDiff(diff)
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).diff = diff
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, diff: diff})
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// copyDiff returns a copy of the diff that does not share its slices, nor the objects and arrays held by its entries,
+// so callers cannot change a cached result.
+func copyDiff(d Diff) Diff {
+	d.Entries = append([]DiffEntry(nil), d.Entries...)
+	for i := range d.Entries {
+		d.Entries[i].Expected = copyValue(d.Entries[i].Expected)
+		d.Entries[i].Actual = copyValue(d.Entries[i].Actual)
+	}
+	d.ArrayMappings = append([]ArrayMapping(nil), d.ArrayMappings...)
+	d.LengthChanges = append([]LengthChange(nil), d.LengthChanges...)
+	d.AssertionFailures = append([]AssertionFailure(nil), d.AssertionFailures...)
+	d.Inconsistencies = append([]Inconsistency(nil), d.Inconsistencies...)
+	d.Spilled = append([]SpillSummary(nil), d.Spilled...)
+	d.Warnings = append([]Warning(nil), d.Warnings...)
+	return d
+}
+
+// copyValue returns a deep copy of a decoded JSON value.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			copied[key] = copyValue(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, element := range v {
+			copied[i] = copyValue(element)
+		}
+		return copied
+	}
+	return value
+}
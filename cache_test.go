@@ -0,0 +1,153 @@
+package colorisediff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingCache wraps a ResultCache and counts its hits.
+type countingCache struct {
+	ResultCache
+	hits int
+}
+
+func (c *countingCache) Get(key string) (Diff, bool) {
+	diff, ok := c.ResultCache.Get(key)
+	if ok {
+		c.hits++
+	}
+	return diff, ok
+}
+
+func TestWithResultCache(t *testing.T) {
+	cache := &countingCache{ResultCache: NewMemoryCache(0)}
+	expected, actual := []byte(`{"a":1,"ts":1}`), []byte(`{"a":2,"ts":2}`)
+	noise := map[string][]string{"ts": {}}
+
+	first, err := CompareJSON(expected, actual, noise, true, WithResultCache(cache, "default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := 0
+	second, err := CompareJSON(expected, actual, noise, true, WithResultCache(cache, "default"), WithOnDiff(func(DiffEntry) { called++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.hits != 1 || called != 0 || !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the second comparison to be served from the cache, got %d hits:\n%+v\n%+v", cache.hits, first, second)
+	}
+
+	second.Entries[0].Path = "changed"
+	misses := []struct {
+		name    string
+		actual  []byte
+		noise   map[string][]string
+		noColor bool
+		scope   string
+	}{
+		{name: "other document", actual: []byte(`{"a":3,"ts":2}`), noise: noise, noColor: true, scope: "default"},
+		{name: "other noise", actual: actual, noise: nil, noColor: true, scope: "default"},
+		{name: "colors", actual: actual, noise: noise, noColor: false, scope: "default"},
+		{name: "other scope", actual: actual, noise: noise, noColor: true, scope: "strict"},
+	}
+	for _, tt := range misses {
+		if _, err := CompareJSON(expected, tt.actual, tt.noise, tt.noColor, WithResultCache(cache, tt.scope)); err != nil {
+			t.Fatal(err)
+		}
+		if cache.hits != 1 {
+			t.Errorf("%s: expected a cache miss", tt.name)
+		}
+	}
+
+	third, _ := CompareJSON(expected, actual, noise, true, WithResultCache(cache, "default"))
+	if third.Entries[0].Path != "a" {
+		t.Errorf("expected the cached result to be unaffected by changes to a returned diff, got %+v", third.Entries)
+	}
+}
+
+func TestMemoryCacheCapacity(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Put("a", Diff{Expected: "a"})
+	cache.Put("b", Diff{Expected: "b"})
+	cache.Get("a")
+	cache.Put("c", Diff{Expected: "c"})
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected the least recently used result to be dropped")
+	}
+	for _, key := range []string{"a", "c"} {
+		if diff, ok := cache.Get(key); !ok || diff.Expected != key {
+			t.Errorf("expected %q to be cached, got %+v", key, diff)
+		}
+	}
+}
+
+func TestWithResultCacheOptions(t *testing.T) {
+	cache := &countingCache{ResultCache: NewMemoryCache(0)}
+	expected, actual := []byte(`{"a":1,"b":{"c":1}}`), []byte(`{"a":1.005,"b":{"c":1,"d":2}}`)
+
+	compare := func(opts ...Option) Diff {
+		t.Helper()
+		diff, err := CompareJSON(expected, actual, nil, true, append([]Option{WithResultCache(cache, "default")}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diff
+	}
+	plain := compare()
+	tolerant := compare(WithNumericTolerance(0.01, "a"))
+	if cache.hits != 0 || len(tolerant.Entries) == len(plain.Entries) {
+		t.Errorf("expected options changing the result to miss the cache, got %d hits:\n%+v\n%+v", cache.hits, plain.Entries, tolerant.Entries)
+	}
+	compare(WithNumericTolerance(0.02, "a"))
+	compare(WithIgnoreExtraKeys())
+	if cache.hits != 0 {
+		t.Errorf("expected other option values to miss the cache, got %d hits", cache.hits)
+	}
+	compare(WithNumericTolerance(0.01, "a"))
+	if cache.hits != 1 {
+		t.Errorf("expected the same options to hit the cache, got %d hits", cache.hits)
+	}
+
+	// Options holding functions bypass the cache.
+	redacted := compare(WithRedact("b.d"))
+	compare(WithRedactFunc(func(path string, _ interface{}) bool { return path == "a" }))
+	if cache.hits != 1 || len(redacted.Entries) == 0 || redacted.Entries[len(redacted.Entries)-1].Actual != redactedValue {
+		t.Errorf("expected options holding functions to bypass the cache, got %d hits: %+v", cache.hits, redacted.Entries)
+	}
+}
+
+func TestWithResultCacheTimeDependent(t *testing.T) {
+	cache := &countingCache{ResultCache: NewMemoryCache(0)}
+	expected, actual := []byte(`{"a":1,"b":{"c":1}}`), []byte(`{"a":2,"b":[1]}`)
+	waiver := Waiver{Path: "a", Reason: "known", Expires: time.Now().Add(time.Hour)}
+	for _, opt := range []Option{WithWaivers(waiver), WithMetadataHeader("want.json", "got.json")} {
+		for i := 0; i < 2; i++ {
+			if _, err := CompareJSON(expected, actual, nil, true, WithResultCache(cache, "default"), opt); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if cache.hits != 0 {
+		t.Errorf("expected time-dependent comparisons to bypass the cache, got %d hits", cache.hits)
+	}
+
+	// Changes to the values of a returned diff do not reach the cache.
+	first, err := CompareJSON(expected, actual, nil, true, WithResultCache(cache, "default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := false
+	for _, entry := range first.Entries {
+		if object, ok := entry.Expected.(map[string]interface{}); ok {
+			object["c"] = "mutated"
+			changed = true
+		}
+	}
+	second, _ := CompareJSON(expected, actual, nil, true, WithResultCache(cache, "default"))
+	if !changed || cache.hits != 1 || strings.Contains(fmt.Sprint(second.Entries), "mutated") {
+		t.Errorf("expected the cached values to be unaffected, got %d hits: %+v", cache.hits, second.Entries)
+	}
+}
@@ -0,0 +1,150 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonicalize returns the canonical form of a JSON document following RFC 8785 (JSON Canonicalization Scheme):
+// no insignificant white space, object members sorted by the UTF-16 code units of their names, numbers in their
+// shortest round-tripping form and strings with the minimal escaping. Documents that are equal as JSON values
+// have identical canonical forms, which makes them suitable for hashing. The document is decoded the same way
+// CompareJSON decodes its inputs, so numbers are IEEE 754 doubles.
+func Canonicalize(document []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := writeCanonical(&buffer, value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// canonicalEqual reports whether two decoded JSON values have the same canonical form.
+func canonicalEqual(expected, actual interface{}) bool {
+	var expectedBuffer, actualBuffer bytes.Buffer
+	if writeCanonical(&expectedBuffer, expected) != nil || writeCanonical(&actualBuffer, actual) != nil {
+		return false
+	}
+	return bytes.Equal(expectedBuffer.Bytes(), actualBuffer.Bytes())
+}
+
+// writeCanonical appends the canonical form of a decoded JSON value to the buffer.
+func writeCanonical(buffer *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		if v {
+			buffer.WriteString("true")
+		} else {
+			buffer.WriteString("false")
+		}
+	case float64:
+		if v == 0 {
+			v = 0 // Negative zero is serialized as 0.
+		}
+		// encoding/json formats floats like ECMAScript's Number.prototype.toString, as RFC 8785 requires.
+		number, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buffer.Write(number)
+	case string:
+		writeCanonicalString(buffer, v)
+	case []interface{}:
+		buffer.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeCanonical(buffer, element); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			writeCanonicalString(buffer, key)
+			buffer.WriteByte(':')
+			if err := writeCanonical(buffer, v[key]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value of type %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalString appends a string literal escaping only the quotation mark, the reverse solidus and the
+// control characters, using the short escapes where they exist.
+func writeCanonicalString(buffer *bytes.Buffer, s string) {
+	buffer.WriteByte('"')
+	for _, char := range s {
+		switch char {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if char < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, char)
+			} else {
+				buffer.WriteRune(char)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+}
+
+// lessUTF16 orders two strings by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	if utf8.ValidString(a) && utf8.ValidString(b) && isBMP(a) && isBMP(b) {
+		return a < b
+	}
+	unitsA, unitsB := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(unitsA) && i < len(unitsB); i++ {
+		if unitsA[i] != unitsB[i] {
+			return unitsA[i] < unitsB[i]
+		}
+	}
+	return len(unitsA) < len(unitsB)
+}
+
+// isBMP reports whether the string only holds characters of the Basic Multilingual Plane, for which the UTF-8
+// and UTF-16 orders agree.
+func isBMP(s string) bool {
+	for _, char := range s {
+		if char > 0xffff {
+			return false
+		}
+	}
+	return true
+}
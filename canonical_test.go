@@ -0,0 +1,33 @@
+package colorisediff
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "sorted keys", input: `{ "b": 1, "a": [ true, null ] }`, want: `{"a":[true,null],"b":1}`},
+		{name: "nested", input: `{"z": {"y": 1, "x": 2}}`, want: `{"z":{"x":2,"y":1}}`},
+		{name: "numbers", input: `[1.0, 1e21, 1E-7, 0.000001, -0, 100, 4.50]`, want: `[1,1e+21,1e-7,0.000001,0,100,4.5]`},
+		{name: "escapes", input: `"<\/a> \u0001 \" \\ \n \u00e9"`, want: "\"</a> \\u0001 \\\" \\\\ \\n \u00e9\""},
+		{name: "line separator kept", input: "\"\u2028\"", want: "\"\u2028\""},
+		{name: "utf16 key order", input: "{\"\uff61\": 2, \"\U0001F600\": 1}", want: "{\"\U0001F600\":1,\"\uff61\":2}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonicalize(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Canonicalize([]byte(`{"a":`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
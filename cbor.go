@@ -0,0 +1,277 @@
+package colorisediff
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// cborBreak is the stop code terminating indefinite-length items.
+const cborBreak = 0xff
+
+// CompareCBOR compares two CBOR documents and returns the colorized differences.
+// The documents are decoded into JSON-compatible values and compared like CompareJSON would: byte strings become
+// base64 strings, date/time tags become RFC 3339 strings, bignums become decimal strings, embedded CBOR is decoded
+// and other tags become objects with "tag" and "value" fields.
+func CompareCBOR(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	expectedValue, err := decodeCBOR(expected)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding expected CBOR: %w", err)
+	}
+	actualValue, err := decodeCBOR(actual)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding actual CBOR: %w", err)
+	}
+	return compareDecoded(expectedValue, actualValue, noise, disableColor, opts)
+}
+
+// decodeCBOR decodes a single CBOR data item spanning the whole input.
+func decodeCBOR(data []byte) (interface{}, error) {
+	d := &cborDecoder{msgpackDecoder{data: data}}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%d trailing bytes after value", len(d.data)-d.pos)
+	}
+	return value, nil
+}
+
+// cborDecoder decodes CBOR data items from a byte slice. It shares the byte reading helpers of the MessagePack
+// decoder, since both formats use big-endian lengths.
+type cborDecoder struct {
+	msgpackDecoder
+}
+
+// argument reads the argument encoded by the additional information of an initial byte. indefinite is set for
+// the indefinite-length marker.
+func (d *cborDecoder) argument(info byte) (value uint64, indefinite bool, err error) {
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info <= 27:
+		value, err = d.uint(1 << (info - 24))
+		return value, false, err
+	case info == 31:
+		return 0, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid additional information %d at offset %d", info, d.pos-1)
+}
+
+// peekBreak consumes the break stop code if it is the next byte.
+func (d *cborDecoder) peekBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// decode decodes the next data item.
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+	arg, indefinite, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		return arg, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return new(big.Int).Sub(big.NewInt(-1), new(big.Int).SetUint64(arg)).String(), nil
+		}
+		return -1 - int64(arg), nil
+	case 2, 3:
+		raw, err := d.decodeBytes(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == 2 {
+			return base64.StdEncoding.EncodeToString(raw), nil
+		}
+		return string(raw), nil
+	case 4:
+		var array []interface{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.peekBreak() {
+				break
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if array == nil {
+			array = []interface{}{}
+		}
+		return array, nil
+	case 5:
+		object := map[string]interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.peekBreak() {
+				break
+			}
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			object[mapKeyString(key)] = value
+		}
+		return object, nil
+	case 6:
+		if indefinite {
+			return nil, errors.New("indefinite length tag")
+		}
+		return d.decodeTag(arg, depth)
+	}
+	return nil, fmt.Errorf("invalid major type %d", major)
+}
+
+// decodeBytes reads the content of a byte or text string, concatenating the chunks of indefinite-length strings.
+func (d *cborDecoder) decodeBytes(major byte, length uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if length > uint64(len(d.data)-d.pos) {
+			return nil, errUnexpectedEOF
+		}
+		return d.next(int(length))
+	}
+	var raw []byte
+	for !d.peekBreak() {
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0]>>5 != major {
+			return nil, errors.New("invalid chunk in indefinite length string")
+		}
+		chunkLength, chunkIndefinite, err := d.argument(b[0] & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		if chunkIndefinite {
+			return nil, errors.New("nested indefinite length string")
+		}
+		chunk, err := d.decodeBytes(major, chunkLength, false)
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, chunk...)
+	}
+	return raw, nil
+}
+
+// decodeSimple decodes simple values and floats of major type 7.
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 24:
+		v, err := d.uint(1)
+		return map[string]interface{}{"simple": v}, err
+	case 25:
+		v, err := d.uint(2)
+		return halfToFloat(uint16(v)), err
+	case 26:
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 27:
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	}
+	if info < 20 {
+		return map[string]interface{}{"simple": uint64(info)}, nil
+	}
+	return nil, fmt.Errorf("unexpected simple value %d at offset %d", info, d.pos-1)
+}
+
+// decodeTag decodes the content of a tagged data item.
+func (d *cborDecoder) decodeTag(tag uint64, depth int) (interface{}, error) {
+	content, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		return content, nil
+	case 1:
+		switch epoch := content.(type) {
+		case uint64:
+			return formatTimestamp(int64(epoch), 0), nil
+		case int64:
+			return formatTimestamp(epoch, 0), nil
+		case float64:
+			seconds, fraction := math.Modf(epoch)
+			return formatTimestamp(int64(seconds), int64(fraction*float64(time.Second))), nil
+		}
+	case 2, 3:
+		if encoded, ok := content.(string); ok {
+			raw, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, err
+			}
+			n := new(big.Int).SetBytes(raw)
+			if tag == 3 {
+				n.Sub(big.NewInt(-1), n)
+			}
+			return n.String(), nil
+		}
+	case 24:
+		if encoded, ok := content.(string); ok {
+			raw, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, err
+			}
+			return decodeCBOR(raw)
+		}
+	}
+	return map[string]interface{}{"tag": tag, "value": content}, nil
+}
+
+// halfToFloat converts an IEEE 754 half-precision float to a float64.
+func halfToFloat(h uint16) float64 {
+	exponent := int(h>>10) & 0x1f
+	mantissa := float64(h & 0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if h&0x8000 != 0 {
+		value = -value
+	}
+	return value
+}
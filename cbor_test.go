@@ -0,0 +1,58 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareCBOR(t *testing.T) {
+	// {"name":"Cat","ids":[1,2]} and {"name":"Dog","ids":[1,2]}
+	expected := []byte{0xa2, 0x64, 'n', 'a', 'm', 'e', 0x63, 'C', 'a', 't', 0x63, 'i', 'd', 's', 0x82, 0x01, 0x02}
+	actual := []byte{0xa2, 0x64, 'n', 'a', 'm', 'e', 0x63, 'D', 'o', 'g', 0x63, 'i', 'd', 's', 0x82, 0x01, 0x02}
+
+	resp, err := CompareCBOR(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected interface{}
+	}{
+		{name: "negative int", input: []byte{0x38, 0x63}, expected: int64(-100)},
+		{name: "uint16", input: []byte{0x19, 0x03, 0xe8}, expected: uint64(1000)},
+		{name: "half float", input: []byte{0xf9, 0x3e, 0x00}, expected: 1.5},
+		{name: "null", input: []byte{0xf6}, expected: nil},
+		{name: "byte string", input: []byte{0x42, 0x01, 0x02}, expected: "AQI="},
+		{name: "indefinite text", input: []byte{0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff}, expected: "abc"},
+		{name: "indefinite array", input: []byte{0x9f, 0x01, 0xf5, 0xff}, expected: []interface{}{uint64(1), true}},
+		{name: "integer map key", input: []byte{0xa1, 0x01, 0x61, 'x'}, expected: map[string]interface{}{"1": "x"}},
+		{name: "epoch tag", input: []byte{0xc1, 0x18, 0x3c}, expected: "1970-01-01T00:01:00Z"},
+		{name: "bignum tag", input: []byte{0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, expected: "18446744073709551616"},
+		{name: "unknown tag", input: []byte{0xd2, 0x80}, expected: map[string]interface{}{"tag": uint64(18), "value": []interface{}{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCBOR(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("decodeCBOR(%x) = %#v, want %#v", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range [][]byte{{0x1c}, {0x62, 'a'}, {0x01, 0x01}, {0x9f, 0x01}} {
+		if _, err := decodeCBOR(input); err == nil {
+			t.Errorf("expected an error decoding %x", input)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithChunking renders changed values longer than size characters in labeled chunks of size characters instead of
+// wrapping them whole. A header states the size of the value and how many of its chunks differ, e.g.
+// "<1.2MB, 310 chunks, 1 differ>", runs of identical chunks are collapsed into one line such as "chunks 1-2/310
+// same", and only the differing chunks are shown, e.g. "chunk 3/310 differs:", with their changed words colored,
+// keeping the output of megabyte-sized values readable.
+func WithChunking(size int) Option {
+	return func(o *options) {
+		o.valueRenderers.chunkSize = size
+	}
+}
+
+// chunked reports whether changed values rendered as the given texts are shown in chunks.
+func (r valueRenderers) chunked(expected, actual string) bool {
+	return r.chunkSize > 0 && (utf8.RuneCountInString(expected) > r.chunkSize || utf8.RuneCountInString(actual) > r.chunkSize)
+}
+
+// renderChunks renders the texts of a changed value as labeled chunks, each side comparing its chunks with those at
+// the same position on the other side.
+func renderChunks(indent, key, expected, actual string, size int, red, green func(a ...interface{}) string) (string, string) {
+	expectedChunks, actualChunks := splitChunks(expected, size), splitChunks(actual, size)
+	return renderChunkSide(indent, key, expected, expectedChunks, actualChunks, red),
+		renderChunkSide(indent, key, actual, actualChunks, expectedChunks, green)
+}
+
+// renderChunkSide renders the chunks of one side of a changed value, painting the words differing from the chunks
+// of the other side.
+func renderChunkSide(indent, key, text string, chunks, other []string, paint func(a ...interface{}) string) string {
+	differs := func(i int) bool {
+		return i >= len(other) || chunks[i] != other[i]
+	}
+	differing := 0
+	for i := range chunks {
+		if differs(i) {
+			differing++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(breakLines(fmt.Sprintf("%s%s: <%s, %d chunks, %d differ>", indent, key, formatSize(len(text)), len(chunks), differing)) + "\n")
+	for i := 0; i < len(chunks); {
+		if !differs(i) {
+			start := i
+			for i < len(chunks) && !differs(i) {
+				i++
+			}
+			label := fmt.Sprintf("chunk %d/%d same", start+1, len(chunks))
+			if i-start > 1 {
+				label = fmt.Sprintf("chunks %d-%d/%d same", start+1, i, len(chunks))
+			}
+			builder.WriteString(breakLines(indent+"  "+label) + "\n")
+			continue
+		}
+		otherChunk := ""
+		if i < len(other) {
+			otherChunk = other[i]
+		}
+		offsets, _, _ := diffArrayRange(chunks[i], otherChunk)
+		builder.WriteString(breakLines(fmt.Sprintf("%s  chunk %d/%d differs:", indent, i+1, len(chunks))) + "\n")
+		builder.WriteString(breakLines(indent+"    "+breakSliceWithColor(chunks[i], paint, offsets)) + "\n")
+		i++
+	}
+	return builder.String()
+}
+
+// splitChunks splits a text into chunks of size characters. The last chunk may be shorter.
+func splitChunks(text string, size int) []string {
+	var chunks []string
+	for len(text) > 0 {
+		end, count := 0, 0
+		for end < len(text) && count < size {
+			_, width := utf8.DecodeRuneInString(text[end:])
+			end += width
+			count++
+		}
+		chunks = append(chunks, text[:end])
+		text = text[end:]
+	}
+	return chunks
+}
@@ -0,0 +1,42 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithChunking(t *testing.T) {
+	text := strings.Repeat("lorem ipsum dolor ", 20)
+	changed := text[:100] + "CHANGED" + text[107:]
+	json1 := `{"body":"` + text + `"}`
+	json2 := `{"body":"` + changed + `"}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithChunking(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{` "body": <362B, 8 chunks, 1 differ>`, "   chunks 1-2/8 same\n", "   chunk 3/8 differs:\n", "   chunks 4-8/8 same\n"} {
+		if !strings.Contains(resp.Expected, want) {
+			t.Errorf("expected %q in:\n%s", want, resp.Expected)
+		}
+	}
+	if !strings.Contains(resp.Actual, "uCHANGED") || strings.Contains(resp.Actual, "chunk 1/8") {
+		t.Errorf("expected only the differing chunk to be shown, got:\n%s", resp.Actual)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true, WithChunking(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(resp.Expected, "chunk") {
+		t.Errorf("expected values shorter than the chunk size to be shown whole, got:\n%s", resp.Expected)
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	chunks := splitChunks("héllo wörld", 4)
+	want := []string{"héll", "o wö", "rld"}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Errorf("splitChunks() = %q, want %q", chunks, want)
+	}
+}
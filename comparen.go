@@ -0,0 +1,212 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// MultiDiff is the outcome of comparing more than two documents with CompareN.
+// Documents: The number of documents compared.
+// Paths: The leaf-level paths at which the documents do not all agree, sorted by path.
+type MultiDiff struct {
+	Documents int
+	Paths     []PathDivergence
+}
+
+// PathDivergence describes how the documents compared by CompareN disagree at one path.
+// Path: The gjson-style path of the values, e.g. "status.code".
+// Values: The value of each document at the path, in the order of the documents, nil where it is missing.
+// Present: Whether each document has a value at the path.
+// Groups: The indices of the documents that agree with each other, one group per distinct value and one for the
+// documents missing the path, ordered by their first document.
+type PathDivergence struct {
+	Path    string
+	Values  []interface{}
+	Present []bool
+	Groups  [][]int
+}
+
+// CompareN compares any number of JSON documents with each other, e.g. the responses of several replicas or of a
+// canary, the baseline and the recording, and reports per path which documents agree and which diverge. Objects and
+// arrays are descended into as long as every document having the path holds one, so the divergences are reported
+// at the leaves.
+func CompareN(docs ...[]byte) (MultiDiff, error) {
+	values := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		if err := json.Unmarshal(doc, &values[i]); err != nil {
+			return MultiDiff{}, fmt.Errorf("decoding document %d: %w", i+1, err)
+		}
+	}
+	present := make([]bool, len(docs))
+	for i := range present {
+		present[i] = true
+	}
+	diff := MultiDiff{Documents: len(docs), Paths: []PathDivergence{}}
+	walkDocuments("", values, present, func(divergence PathDivergence) {
+		diff.Paths = append(diff.Paths, divergence)
+	})
+	sort.SliceStable(diff.Paths, func(i, j int) bool { return diff.Paths[i].Path < diff.Paths[j].Path })
+	return diff, nil
+}
+
+// Agreed reports whether all documents agree at every path.
+func (m MultiDiff) Agreed() bool {
+	return len(m.Paths) == 0
+}
+
+// At returns the divergence at the given path, or nil if the documents agree there.
+func (m MultiDiff) At(path string) *PathDivergence {
+	for i := range m.Paths {
+		if m.Paths[i].Path == path {
+			return &m.Paths[i]
+		}
+	}
+	return nil
+}
+
+// Render lays out the divergences as a table with a row per path and a column per document, followed by a column
+// listing the groups of agreeing documents, e.g. "1,3 | 2". The columns are headed by the given names, or by the
+// numbers of the documents for names left out.
+func (m MultiDiff) Render(names ...string) string {
+	header := []string{"Path"}
+	for i := 0; i < m.Documents; i++ {
+		name := "#" + strconv.Itoa(i+1)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		header = append(header, name)
+	}
+	header = append(header, "Agree")
+
+	buf := &bytes.Buffer{}
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	for _, divergence := range m.Paths {
+		row := []string{divergence.Path}
+		for i, value := range divergence.Values {
+			cell := "<missing>"
+			if divergence.Present[i] {
+				cell = WrapANSI(formatValue(value), maxLineLength)
+			}
+			row = append(row, cell)
+		}
+		groups := make([]string, len(divergence.Groups))
+		for i, group := range divergence.Groups {
+			members := make([]string, len(group))
+			for j, index := range group {
+				members[j] = strconv.Itoa(index + 1)
+			}
+			groups[i] = strings.Join(members, ",")
+		}
+		table.Append(append(row, strings.Join(groups, " | ")))
+	}
+	table.Render()
+	return buf.String()
+}
+
+// walkDocuments visits the paths at which the values of the documents present there do not all agree. It descends
+// into objects and arrays while every present value is one.
+func walkDocuments(path string, values []interface{}, present []bool, visit func(PathDivergence)) {
+	objects, arrays, count := 0, 0, 0
+	for i, value := range values {
+		if !present[i] {
+			continue
+		}
+		count++
+		switch value.(type) {
+		case map[string]interface{}:
+			objects++
+		case []interface{}:
+			arrays++
+		}
+	}
+
+	switch {
+	case count > 0 && objects == count:
+		keys := map[string]bool{}
+		for i, value := range values {
+			if present[i] {
+				for key := range value.(map[string]interface{}) {
+					keys[key] = true
+				}
+			}
+		}
+		for _, key := range sortedSet(keys) {
+			childValues, childPresent := make([]interface{}, len(values)), make([]bool, len(values))
+			for i, value := range values {
+				if present[i] {
+					childValues[i], childPresent[i] = value.(map[string]interface{})[key]
+				}
+			}
+			walkDocuments(joinPath(path, escapePathKey(key)), childValues, childPresent, visit)
+		}
+		return
+
+	case count > 0 && arrays == count:
+		length := 0
+		for i, value := range values {
+			if present[i] {
+				length = max(length, len(value.([]interface{})))
+			}
+		}
+		for index := 0; index < length; index++ {
+			childValues, childPresent := make([]interface{}, len(values)), make([]bool, len(values))
+			for i, value := range values {
+				if elements, _ := value.([]interface{}); present[i] && index < len(elements) {
+					childValues[i], childPresent[i] = elements[index], true
+				}
+			}
+			walkDocuments(joinPath(path, strconv.Itoa(index)), childValues, childPresent, visit)
+		}
+		return
+	}
+
+	groups := groupDocuments(values, present)
+	if len(groups) > 1 {
+		visit(PathDivergence{Path: path, Values: values, Present: present, Groups: groups})
+	}
+}
+
+// groupDocuments groups the indices of the documents by their value, putting the documents missing the value in a
+// group of their own. The groups are ordered by their first document.
+func groupDocuments(values []interface{}, present []bool) [][]int {
+	var groups [][]int
+	byValue := map[string]int{}
+	for i, value := range values {
+		key := "missing"
+		if present[i] {
+			encoded, err := encodeJSON(value)
+			if err != nil {
+				encoded = []byte(fmt.Sprint(value))
+			}
+			key = "value:" + string(encoded)
+		}
+		group, ok := byValue[key]
+		if !ok {
+			group = len(groups)
+			byValue[key] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
+	}
+	return groups
+}
+
+// sortedSet returns the members of a set in sorted order.
+func sortedSet(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
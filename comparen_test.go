@@ -0,0 +1,54 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareN(t *testing.T) {
+	baseline := `{"status":"ok","version":"1.2","items":[1,2],"region":"eu"}`
+	canary := `{"status":"ok","version":"1.3","items":[1,2,3],"region":"eu"}`
+	recorded := `{"status":"ok","version":"1.2","items":[1,2]}`
+
+	diff, err := CompareN([]byte(baseline), []byte(canary), []byte(recorded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PathDivergence{
+		{Path: "items.2", Values: []interface{}{nil, 3.0, nil}, Present: []bool{false, true, false}, Groups: [][]int{{0, 2}, {1}}},
+		{Path: "region", Values: []interface{}{"eu", "eu", nil}, Present: []bool{true, true, false}, Groups: [][]int{{0, 1}, {2}}},
+		{Path: "version", Values: []interface{}{"1.2", "1.3", "1.2"}, Present: []bool{true, true, true}, Groups: [][]int{{0, 2}, {1}}},
+	}
+	if diff.Documents != 3 || !reflect.DeepEqual(diff.Paths, want) {
+		t.Errorf("unexpected divergences %+v", diff.Paths)
+	}
+	if diff.Agreed() || diff.At("status") != nil || diff.At("version") == nil {
+		t.Errorf("unexpected lookup results for %+v", diff.Paths)
+	}
+
+	table := diff.Render("baseline", "canary")
+	for _, want := range []string{"BASELINE", "CANARY", "#3", "<missing>", "1,3 | 2", `"1.3"`} {
+		if !strings.Contains(table, want) {
+			t.Errorf("expected %q in:\n%s", want, table)
+		}
+	}
+}
+
+func TestCompareNMixedTypes(t *testing.T) {
+	diff, err := CompareN([]byte(`{"a":{"b":1}}`), []byte(`{"a":{"b":1}}`), []byte(`{"a":"b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Paths) != 1 || diff.Paths[0].Path != "a" || !reflect.DeepEqual(diff.Paths[0].Groups, [][]int{{0, 1}, {2}}) {
+		t.Errorf("expected the mismatched types to diverge at their parent, got %+v", diff.Paths)
+	}
+
+	diff, err = CompareN([]byte(`[1]`), []byte(`[1]`))
+	if err != nil || !diff.Agreed() {
+		t.Errorf("expected equal documents to agree, got %+v, %v", diff, err)
+	}
+	if _, err := CompareN([]byte(`{}`), []byte(`{`)); err == nil || !strings.Contains(err.Error(), "document 2") {
+		t.Errorf("expected an error naming the invalid document, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConsistencyCheck compares the value at one path of the actual document against the value at another path of the
+// same document, e.g. that the length of "items" equals "count", so payloads whose internals no longer agree are
+// caught even where they match the expected document.
+// Name: Names the check in the output, empty for "Path vs Other".
+// Path, Other: The gjson-style paths of the two values.
+// Check: Returns why the values are inconsistent, or nil if they are consistent.
+type ConsistencyCheck struct {
+	Name  string
+	Path  string
+	Other string
+	Check func(value, other interface{}) error
+}
+
+// WithConsistencyChecks runs the checks against the actual document during the comparison. The violations are
+// reported as a category of their own, apart from the differences: they are recorded in the Inconsistencies of the
+// diff and listed below the colorized output. A path missing from the document violates the check.
+func WithConsistencyChecks(checks ...ConsistencyCheck) Option {
+	return func(o *options) {
+		for _, check := range checks {
+			check.Path, check.Other = o.pathPattern(check.Path), o.pathPattern(check.Other)
+			o.consistencyChecks = append(o.consistencyChecks, check)
+		}
+	}
+}
+
+// LengthMatches returns a check that the array, object or string at path has as many elements, members or
+// characters as the number at countPath says, e.g. LengthMatches("items", "count").
+func LengthMatches(path, countPath string) ConsistencyCheck {
+	return ConsistencyCheck{Path: path, Other: countPath, Check: func(value, count interface{}) error {
+		length, ok := valueLength(value)
+		if !ok {
+			return fmt.Errorf("%s has no length, it is %s", path, formatValue(value))
+		}
+		if !canonicalEqual(length, count) {
+			return fmt.Errorf("%s has length %s, %s is %s", path, formatValue(length), countPath, formatValue(count))
+		}
+		return nil
+	}}
+}
+
+// ValuesMatch returns a check that the values at the two paths are equal, e.g. ValuesMatch("id", "links.self.id").
+func ValuesMatch(path, other string) ConsistencyCheck {
+	return ConsistencyCheck{Path: path, Other: other, Check: func(value, otherValue interface{}) error {
+		if !canonicalEqual(value, otherValue) {
+			return fmt.Errorf("%s is %s, %s is %s", path, formatValue(value), other, formatValue(otherValue))
+		}
+		return nil
+	}}
+}
+
+// Inconsistency records a consistency check given with WithConsistencyChecks that the actual document violates.
+// Check: The name of the check, e.g. "items vs count".
+// Path, Other: The gjson-style paths of the values compared by the check.
+// Reason: Why the values are inconsistent, e.g. "items has length 3, count is 2".
+type Inconsistency struct {
+	Check  string `json:"check"`
+	Path   string `json:"path"`
+	Other  string `json:"other"`
+	Reason string `json:"reason"`
+}
+
+// String describes the inconsistency, e.g. "inconsistent: items vs count (items has length 3, count is 2)".
+func (i Inconsistency) String() string {
+	return fmt.Sprintf("inconsistent: %s (%s)", i.Check, i.Reason)
+}
+
+// inconsistencies runs the consistency checks against the actual document and returns the violations in the order
+// of the checks.
+func (o *options) inconsistencies(actual interface{}) []Inconsistency {
+	var found []Inconsistency
+	for _, check := range o.consistencyChecks {
+		name := check.Name
+		if name == "" {
+			name = check.Path + " vs " + check.Other
+		}
+		inconsistency := Inconsistency{Check: name, Path: check.Path, Other: check.Other}
+		value, ok := lookupPath(actual, check.Path)
+		other, otherOK := lookupPath(actual, check.Other)
+		switch {
+		case !ok:
+			inconsistency.Reason = check.Path + " not found"
+		case !otherOK:
+			inconsistency.Reason = check.Other + " not found"
+		case check.Check != nil:
+			if err := check.Check(value, other); err != nil {
+				inconsistency.Reason = err.Error()
+			}
+		}
+		if inconsistency.Reason != "" {
+			found = append(found, inconsistency)
+		}
+	}
+	return found
+}
+
+// renderInconsistencies renders the inconsistencies as lines to be appended below a rendered side.
+func renderInconsistencies(inconsistencies []Inconsistency) string {
+	var builder strings.Builder
+	for _, inconsistency := range inconsistencies {
+		builder.WriteString(breakLines(inconsistency.String()) + "\n")
+	}
+	return builder.String()
+}
+
+// documentChecks holds the results of the checks run against the documents before they are compared.
+type documentChecks struct {
+	warnings        []Warning
+	failures        []AssertionFailure
+	inconsistencies []Inconsistency
+}
+
+// checkDocuments scans both documents for data-quality issues and runs the assertions and consistency checks
+// against the decoded actual document.
+func (o *options) checkDocuments(expectedJSON, actualJSON []byte, actual interface{}) (documentChecks, error) {
+	failures, err := o.failedAssertions(actual)
+	if err != nil {
+		return documentChecks{}, err
+	}
+	redacted := o.valueRenderers.redacted
+	return documentChecks{
+		warnings:        append(scanWarnings(expectedJSON, "expected", redacted), scanWarnings(actualJSON, "actual", redacted)...),
+		failures:        failures,
+		inconsistencies: o.inconsistencies(actual),
+	}, nil
+}
+
+// apply records the results of the checks in the diff and lists them below both rendered sides.
+func (c documentChecks) apply(diff Diff) Diff {
+	rendered := renderAssertionFailures(c.failures) + renderInconsistencies(c.inconsistencies)
+	diff.Expected += rendered
+	diff.Actual += rendered
+	diff.AssertionFailures = c.failures
+	diff.Inconsistencies = c.inconsistencies
+	if len(c.warnings) > 0 {
+		diff.Warnings = append(append([]Warning(nil), c.warnings...), diff.Warnings...)
+	}
+	return diff
+}
@@ -0,0 +1,51 @@
+package colorisediff
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithConsistencyChecks(t *testing.T) {
+	expected := `{"count":3,"items":[1,2,3],"id":"a","links":{"self":{"id":"a"}}}`
+	actual := `{"count":2,"items":[1,2,3],"id":"a","links":{"self":{"id":"b"}}}`
+	positive := ConsistencyCheck{Name: "positive count", Path: "count", Other: "count", Check: func(value, _ interface{}) error {
+		if value.(float64) <= 0 {
+			return errors.New("count is not positive")
+		}
+		return nil
+	}}
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithConsistencyChecks(
+		LengthMatches("items", "count"), ValuesMatch("id", "links.self.id"), positive, LengthMatches("missing", "count"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Inconsistency{
+		{Check: "items vs count", Path: "items", Other: "count", Reason: "items has length 3, count is 2"},
+		{Check: "id vs links.self.id", Path: "id", Other: "links.self.id", Reason: `id is "a", links.self.id is "b"`},
+		{Check: "missing vs count", Path: "missing", Other: "count", Reason: "missing not found"},
+	}
+	if !reflect.DeepEqual(resp.Inconsistencies, want) {
+		t.Errorf("got inconsistencies %+v, want %+v", resp.Inconsistencies, want)
+	}
+	if !strings.Contains(strings.ReplaceAll(resp.Actual, "\n", ""), "inconsistent: items vs count (items has length 3, count is 2)") {
+		t.Errorf("expected the inconsistency in the output, got:\n%s", resp.Actual)
+	}
+	if resp.At("count") == nil {
+		t.Errorf("expected the differences to be reported as well, got %+v", resp.Entries)
+	}
+}
+
+func TestWithConsistencyChecksEqualDocuments(t *testing.T) {
+	doc := []byte(`{"count":1,"items":[]}`)
+	resp, err := CompareJSON(doc, doc, nil, true, WithConsistencyChecks(LengthMatches("items", "count")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Inconsistencies) != 1 || len(resp.Entries) != 0 {
+		t.Errorf("expected one inconsistency and no differences, got %+v and %+v", resp.Inconsistencies, resp.Entries)
+	}
+}
@@ -0,0 +1,86 @@
+package colorisediff
+
+import (
+	"reflect"
+
+	"github.com/fatih/color"
+)
+
+// WithContextKey controls the context section shown above the differences of objects, which lists unchanged fields
+// of the object holding the differences, e.g. an id identifying the record. It is shown by default.
+func WithContextKey(enabled bool) Option {
+	return func(o *options) {
+		o.omitContextKey = !enabled
+	}
+}
+
+// WithContextFields sets the number of unchanged fields listed in the context section, one by default. The fields
+// are the first unchanged siblings of the differences in display order, taken from the deepest object holding all
+// differences. A count of 0 drops the section like WithContextKey(false).
+func WithContextFields(count int) Option {
+	return func(o *options) {
+		o.omitContextKey = count <= 0
+		o.contextFields = count
+	}
+}
+
+// contextFieldCount returns the number of fields to list in the context section, 0 for none.
+func (o *options) contextFieldCount() int {
+	switch {
+	case o.omitContextKey:
+		return 0
+	case o.contextFields == 0:
+		return 1
+	}
+	return o.contextFields
+}
+
+// commonParent returns the deepest node of the tree whose object holds all differences.
+func (n *diffNode) commonParent() *diffNode {
+	for len(n.children) == 1 && len(n.children[0].children) > 0 {
+		n = n.children[0]
+	}
+	return n
+}
+
+// contextFields returns up to count members of two objects found at a path whose values are the same on both
+// sides, in display order and formatted like JSON members through the value renderers.
+func contextFields(expected, actual interface{}, path string, count int, renderers valueRenderers) []string {
+	expectedMap, ok := expected.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	actualMap, ok := actual.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var fields []string
+	for _, key := range renderers.keys(pathToLegacy(path), expectedMap) {
+		if len(fields) == count {
+			break
+		}
+		if actualValue, exists := actualMap[key]; exists && reflect.DeepEqual(expectedMap[key], actualValue) {
+			fields = append(fields, quoteKey(key)+": "+renderers.format(joinPath(path, escapePathKey(key)), actualValue))
+		}
+	}
+	return fields
+}
+
+// renderContext renders the context section, dimmed and labeled with the path of the object the fields belong
+// to, or returns "" if there are no fields.
+func renderContext(path string, fields []string, p palette) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	dim := p.sprintFunc(color.Faint)
+	label := " context:"
+	if path != "" {
+		label = " context (" + path + "):"
+	}
+	section := breakWithColor(label, dim, []colorRange{{Start: 0, End: len(label)}})
+	for _, field := range fields {
+		line := "   " + field
+		section += breakWithColor(line, dim, []colorRange{{Start: 0, End: len(line)}})
+	}
+	return section
+}
@@ -0,0 +1,99 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		count    int
+		want     []string
+	}{
+		{
+			name:     "first unchanged key in sorted order",
+			expected: map[string]interface{}{"zeta": 1.0, "id": 7.0, "beta": "x", "name": "Cat"},
+			actual:   map[string]interface{}{"zeta": 1.0, "id": 7.0, "beta": "x", "name": "Dog"},
+			count:    1,
+			want:     []string{`"beta": "x"`},
+		},
+		{
+			name:     "several fields",
+			expected: map[string]interface{}{"zeta": 1.0, "id": 7.0, "beta": "x", "name": "Cat"},
+			actual:   map[string]interface{}{"zeta": 1.0, "id": 7.0, "beta": "x", "name": "Dog"},
+			count:    5,
+			want:     []string{`"beta": "x"`, `"id": 7`, `"zeta": 1`},
+		},
+		{
+			name:     "changed keys are skipped",
+			expected: map[string]interface{}{"a": 1.0, "b": 2.0},
+			actual:   map[string]interface{}{"a": 3.0, "b": 2.0},
+			count:    1,
+			want:     []string{`"b": 2`},
+		},
+		{
+			name:     "no unchanged key",
+			expected: map[string]interface{}{"a": 1.0},
+			actual:   map[string]interface{}{"a": 2.0},
+			count:    1,
+		},
+		{
+			name:     "arrays have no context",
+			expected: []interface{}{1.0},
+			actual:   []interface{}{1.0, 2.0},
+			count:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contextFields(tt.expected, tt.actual, "", tt.count, valueRenderers{}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("contextFields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextSection(t *testing.T) {
+	expected := []byte(`{"id":7,"order":{"id":"A1","status":"paid","total":10,"items":{"sku":"x","qty":1}}}`)
+	actual := []byte(`{"id":7,"order":{"id":"A1","status":"paid","total":10,"items":{"sku":"x","qty":2}}}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithContextFields(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n context (order.items):\n   \"sku\": \"x\"\n"
+	if !strings.HasPrefix(resp.Expected, want) || !strings.HasPrefix(resp.Actual, want) {
+		t.Errorf("expected the context of the changed object, got:\n%s", resp.Expected)
+	}
+
+	resp, err = CompareJSON([]byte(`{"id":7,"a":1,"b":{"c":1}}`), []byte(`{"id":7,"a":2,"b":{"c":2}}`), nil, true, WithContextFields(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n context:\n   \"id\": 7\n"; !strings.HasPrefix(resp.Expected, want) {
+		t.Errorf("expected the context of the root, got:\n%s", resp.Expected)
+	}
+
+	resp, err = CompareJSON(expected, actual, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NormalizeForTest(resp.Expected); !strings.HasPrefix(got, "{\n context (order.items):\n   \"sku\": \"x\"\n \"order.items.qty\"") {
+		t.Errorf("expected a single context field by default, got:\n%s", got)
+	}
+	if !strings.Contains(resp.Expected, "\x1b[2m") {
+		t.Errorf("expected the context to be dimmed, got %q", resp.Expected)
+	}
+
+	resp, err = CompareJSON(expected, actual, nil, true, WithContextFields(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(resp.Expected, "context") {
+		t.Errorf("expected no context section, got:\n%s", resp.Expected)
+	}
+}
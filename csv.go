@@ -0,0 +1,70 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareCSV compares two CSV documents with a header row and returns the colorized differences.
+// Every row is converted into an object mapping the header names to the cell values and keyed by the values of the
+// keyColumns joined with "/", so rows are matched independently of their order and added, removed and changed rows
+// are rendered like object keys. Without keyColumns rows are keyed by their 1-based position.
+func CompareCSV(expected, actual []byte, keyColumns []string, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	expectedRows, err := csvToObject(expected, keyColumns)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding expected CSV: %w", err)
+	}
+	actualRows, err := csvToObject(actual, keyColumns)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding actual CSV: %w", err)
+	}
+	return compareDecoded(expectedRows, actualRows, noise, disableColor, opts)
+}
+
+// csvToObject converts a CSV document into an object of row objects keyed by the key columns.
+func csvToObject(data []byte, keyColumns []string) (map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rows := map[string]interface{}{}
+	if len(records) == 0 {
+		return rows, nil
+	}
+
+	header := records[0]
+	columnIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		columnIndex[name] = i
+	}
+	for _, column := range keyColumns {
+		if _, ok := columnIndex[column]; !ok {
+			return nil, fmt.Errorf("key column %q not found in header", column)
+		}
+	}
+
+	for line, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+
+		key := strconv.Itoa(line + 1)
+		if len(keyColumns) > 0 {
+			values := make([]string, len(keyColumns))
+			for i, column := range keyColumns {
+				values[i] = record[columnIndex[column]]
+			}
+			key = strings.Join(values, "/")
+		}
+		if _, exists := rows[key]; exists {
+			return nil, fmt.Errorf("duplicate row key %q on line %d", key, line+2)
+		}
+		rows[key] = row
+	}
+	return rows, nil
+}
@@ -0,0 +1,41 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareCSV(t *testing.T) {
+	expected := "id,name,price\n1,apple,10\n2,pear,20\n3,plum,30\n"
+	actual := "id,name,price\n3,plum,30\n1,apple,11\n4,fig,40\n"
+
+	resp, err := CompareCSV([]byte(expected), []byte(actual), []string{"id"}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "1.price", Op: OpChanged, Expected: "10", Actual: "11"},
+		{Path: "2", Op: OpRemoved, Expected: map[string]interface{}{"id": "2", "name": "pear", "price": "20"}},
+		{Path: "4", Op: OpAdded, Actual: map[string]interface{}{"id": "4", "name": "fig", "price": "40"}},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	errorTests := []struct {
+		name       string
+		input      string
+		keyColumns []string
+	}{
+		{name: "unknown key column", input: "id,name\n1,a\n", keyColumns: []string{"sku"}},
+		{name: "duplicate key", input: "id,name\n1,a\n1,b\n", keyColumns: []string{"id"}},
+		{name: "ragged row", input: "id,name\n1\n", keyColumns: []string{"id"}},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompareCSV([]byte(tt.input), []byte(actual), tt.keyColumns, nil, true); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
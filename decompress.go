@@ -0,0 +1,66 @@
+package colorisediff
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithDecompress makes the comparison decompress both inputs before parsing them, so raw HTTP response bodies can
+// be passed as captured. encoding takes the value of a Content-Encoding header: "gzip", "x-gzip", "deflate" or
+// "identity". An empty encoding or "auto" detects gzip and zlib streams by their magic bytes and passes other input
+// through unchanged.
+func WithDecompress(encoding string) Option {
+	return func(o *options) {
+		o.decompress = true
+		o.encoding = strings.ToLower(strings.TrimSpace(encoding))
+	}
+}
+
+// decompressBody decompresses data according to the encoding configured with WithDecompress.
+func decompressBody(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "identity":
+		return data, nil
+	case "gzip", "x-gzip":
+		return readAllFrom(gzip.NewReader(bytes.NewReader(data)))
+	case "deflate":
+		// HTTP "deflate" is specified as zlib-wrapped, but raw deflate streams are common in the wild.
+		if isZlib(data) {
+			return readAllFrom(zlib.NewReader(bytes.NewReader(data)))
+		}
+		return readAllFrom(flate.NewReader(bytes.NewReader(data)), nil)
+	case "", "auto":
+		switch {
+		case isGzip(data):
+			return readAllFrom(gzip.NewReader(bytes.NewReader(data)))
+		case isZlib(data):
+			return readAllFrom(zlib.NewReader(bytes.NewReader(data)))
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// readAllFrom reads a decompressing reader to the end and closes it.
+func readAllFrom(reader io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// isGzip reports whether data starts with the gzip magic bytes.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// isZlib reports whether data starts with a valid zlib header using the deflate method.
+func isZlib(data []byte) bool {
+	return len(data) >= 2 && data[0]&0x0f == 8 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
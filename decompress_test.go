@@ -0,0 +1,56 @@
+package colorisediff
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	compress := func(newWriter func(io.Writer) io.WriteCloser, s string) []byte {
+		var buf bytes.Buffer
+		writer := newWriter(&buf)
+		_, _ = writer.Write([]byte(s))
+		_ = writer.Close()
+		return buf.Bytes()
+	}
+	gzipWriter := func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	zlibWriter := func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	flateWriter := func(w io.Writer) io.WriteCloser {
+		writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return writer
+	}
+	json1 := `{"name":"Cat"}`
+	json2 := `{"name":"Dog"}`
+
+	tests := []struct {
+		name     string
+		encoding string
+		expected []byte
+		actual   []byte
+	}{
+		{name: "gzip", encoding: "gzip", expected: compress(gzipWriter, json1), actual: compress(gzipWriter, json2)},
+		{name: "zlib deflate", encoding: "deflate", expected: compress(zlibWriter, json1), actual: compress(zlibWriter, json2)},
+		{name: "raw deflate", encoding: "deflate", expected: compress(flateWriter, json1), actual: compress(flateWriter, json2)},
+		{name: "auto-detected mix", encoding: "", expected: compress(gzipWriter, json1), actual: []byte(json2)},
+		{name: "identity", encoding: "identity", expected: []byte(json1), actual: []byte(json2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON(tt.expected, tt.actual, nil, true, WithDecompress(tt.encoding))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry := resp.At("name"); entry == nil || entry.Expected != "Cat" || entry.Actual != "Dog" {
+				t.Errorf("unexpected entries %+v", resp.Entries)
+			}
+		})
+	}
+
+	if _, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithDecompress("br")); err == nil {
+		t.Error("expected an error for an unsupported encoding")
+	}
+}
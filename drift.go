@@ -0,0 +1,122 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// driftBaselineVersion is the version of the JSON schema written by DriftBaseline.WriteFile.
+const driftBaselineVersion = 1
+
+// DriftStatus tells how the differences of a comparison relate to an accepted baseline.
+type DriftStatus string
+
+const (
+	// DriftNew marks differences that are not part of the baseline, or whose values changed since it was stored.
+	DriftNew DriftStatus = "new"
+	// DriftUnchanged marks differences that match the baseline.
+	DriftUnchanged DriftStatus = "unchanged"
+	// DriftResolved marks differences of the baseline that no longer occur.
+	DriftResolved DriftStatus = "resolved"
+)
+
+// DriftBaseline is the fingerprint of an accepted diff: the paths of its differences that are not noised, each with
+// a hash of its kind of change and values. Storing it lets later runs tell new drift from drift that was already
+// accepted.
+// Entries: The hash of each difference, by path.
+type DriftBaseline struct {
+	Version int               `json:"version"`
+	Entries map[string]string `json:"entries"`
+}
+
+// DriftReport relates the differences of a comparison to a DriftBaseline.
+// Status: DriftNew if any difference is new, DriftResolved if none is left of a non-empty baseline, and
+// DriftUnchanged otherwise.
+// New, Unchanged, Resolved: The paths of the differences with each status, sorted.
+type DriftReport struct {
+	Status    DriftStatus `json:"status"`
+	New       []string    `json:"new"`
+	Unchanged []string    `json:"unchanged"`
+	Resolved  []string    `json:"resolved"`
+}
+
+// NewDriftBaseline fingerprints the differences of the diff that are not noised, to be stored as the accepted
+// baseline.
+func NewDriftBaseline(d Diff) DriftBaseline {
+	baseline := DriftBaseline{Version: driftBaselineVersion, Entries: map[string]string{}}
+	for _, entry := range d.Entries {
+		if !entry.Noised {
+			baseline.Entries[entry.Path] = entryHash(entry)
+		}
+	}
+	return baseline
+}
+
+// LoadDriftBaseline reads a baseline written by WriteFile. A missing file yields an empty baseline, so the first run
+// of an accepted-diff workflow reports all differences as new.
+func LoadDriftBaseline(path string) (DriftBaseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return DriftBaseline{Version: driftBaselineVersion, Entries: map[string]string{}}, nil
+	}
+	if err != nil {
+		return DriftBaseline{}, err
+	}
+	var baseline DriftBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return DriftBaseline{}, fmt.Errorf("%s: decoding drift baseline: %w", path, err)
+	}
+	if baseline.Version > driftBaselineVersion {
+		return DriftBaseline{}, fmt.Errorf("%s: unsupported drift baseline version %d", path, baseline.Version)
+	}
+	return baseline, nil
+}
+
+// WriteFile writes the baseline as indented JSON to the named file, replacing it if it exists.
+func (b DriftBaseline) WriteFile(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Compare reports which differences of the diff are new, unchanged or resolved relative to the baseline. Noised
+// differences are left out, as they are from the baseline.
+func (b DriftBaseline) Compare(d Diff) DriftReport {
+	report := DriftReport{New: []string{}, Unchanged: []string{}, Resolved: []string{}}
+	seen := map[string]bool{}
+	for _, entry := range d.Entries {
+		if entry.Noised || seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+		if hash, ok := b.Entries[entry.Path]; ok && hash == entryHash(entry) {
+			report.Unchanged = append(report.Unchanged, entry.Path)
+		} else {
+			report.New = append(report.New, entry.Path)
+		}
+	}
+	for path := range b.Entries {
+		if !seen[path] {
+			report.Resolved = append(report.Resolved, path)
+		}
+	}
+	sort.Strings(report.New)
+	sort.Strings(report.Unchanged)
+	sort.Strings(report.Resolved)
+
+	switch {
+	case len(report.New) > 0:
+		report.Status = DriftNew
+	case len(report.Unchanged) == 0 && len(report.Resolved) > 0:
+		report.Status = DriftResolved
+	default:
+		report.Status = DriftUnchanged
+	}
+	return report
+}
@@ -0,0 +1,75 @@
+package colorisediff
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDriftBaseline(t *testing.T) {
+	expected := `{"id":1,"name":"Cat","age":3,"ts":1}`
+	accepted, err := CompareJSON([]byte(expected), []byte(`{"id":1,"name":"Dog","age":4,"ts":2}`), map[string][]string{"ts": {}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := NewDriftBaseline(accepted).WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadDriftBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline.Entries) != 2 {
+		t.Fatalf("expected the noised entry to be left out, got %+v", baseline.Entries)
+	}
+
+	tests := []struct {
+		name   string
+		actual string
+		want   DriftReport
+	}{
+		{
+			name:   "unchanged",
+			actual: `{"id":1,"name":"Dog","age":4,"ts":3}`,
+			want:   DriftReport{Status: DriftUnchanged, New: []string{}, Unchanged: []string{"age", "name"}, Resolved: []string{}},
+		},
+		{
+			name:   "new value",
+			actual: `{"id":1,"name":"Dog","age":5,"ts":3}`,
+			want:   DriftReport{Status: DriftNew, New: []string{"age"}, Unchanged: []string{"name"}, Resolved: []string{}},
+		},
+		{
+			name:   "partly resolved",
+			actual: `{"id":1,"name":"Cat","age":4,"ts":3}`,
+			want:   DriftReport{Status: DriftUnchanged, New: []string{}, Unchanged: []string{"age"}, Resolved: []string{"name"}},
+		},
+		{
+			name:   "resolved",
+			actual: expected,
+			want:   DriftReport{Status: DriftResolved, New: []string{}, Unchanged: []string{}, Resolved: []string{"age", "name"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := CompareJSON([]byte(expected), []byte(tt.actual), map[string][]string{"ts": {}}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := baseline.Compare(diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDriftBaselineMissing(t *testing.T) {
+	baseline, err := LoadDriftBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := CompareStatus(200, 500)
+	if report := baseline.Compare(diff); report.Status != DriftNew || len(report.New) != 1 {
+		t.Errorf("expected every difference to be new, got %+v", report)
+	}
+}
@@ -0,0 +1,147 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// WithDynamoDBAttributeValues makes the comparison unwrap DynamoDB-style typed JSON ({"S": "foo"}, {"N": "42"},
+// {"M": {...}} and friends) into plain values before comparing, so diffs of items show the changed data instead
+// of the type wrappers. Numbers become JSON numbers and the members of sets are sorted, since sets are unordered.
+func WithDynamoDBAttributeValues() Option {
+	return func(o *options) {
+		o.dynamoDB = true
+	}
+}
+
+// unwrapDynamoDB is a normalizer unwrapping the AttributeValues of a whole document. Wrappers are recognized
+// top-down, so attributes that happen to be named like a type descriptor are not mistaken for wrappers.
+func unwrapDynamoDB(path string, value interface{}) (interface{}, bool) {
+	if path != "" {
+		return value, false
+	}
+	return unwrapAttributeValues(value), false
+}
+
+// unwrapAttributeValues replaces the AttributeValues found in a value with plain values.
+func unwrapAttributeValues(value interface{}) interface{} {
+	if unwrapped, ok := unwrapAttributeValue(value); ok {
+		return unwrapped
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = unwrapAttributeValues(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = unwrapAttributeValues(child)
+		}
+	}
+	return value
+}
+
+// unwrapAttributeValue converts a single AttributeValue into a plain value. It reports false if the value is not
+// an AttributeValue.
+func unwrapAttributeValue(value interface{}) (interface{}, bool) {
+	wrapper, ok := value.(map[string]interface{})
+	if !ok || len(wrapper) != 1 {
+		return nil, false
+	}
+	for descriptor, inner := range wrapper {
+		switch descriptor {
+		case "S", "B":
+			s, ok := inner.(string)
+			return s, ok
+		case "N":
+			if s, ok := inner.(string); ok {
+				return dynamoDBNumber(s)
+			}
+		case "BOOL":
+			b, ok := inner.(bool)
+			return b, ok
+		case "NULL":
+			if null, ok := inner.(bool); ok && null {
+				return nil, true
+			}
+		case "M":
+			if attributes, ok := inner.(map[string]interface{}); ok {
+				unwrapped := make(map[string]interface{}, len(attributes))
+				for key, attribute := range attributes {
+					if unwrapped[key], ok = unwrapAttributeValue(attribute); !ok {
+						return nil, false
+					}
+				}
+				return unwrapped, true
+			}
+		case "L":
+			if list, ok := inner.([]interface{}); ok {
+				unwrapped := make([]interface{}, len(list))
+				for i, element := range list {
+					if unwrapped[i], ok = unwrapAttributeValue(element); !ok {
+						return nil, false
+					}
+				}
+				return unwrapped, true
+			}
+		case "SS", "BS", "NS":
+			return dynamoDBSet(descriptor, inner)
+		}
+	}
+	return nil, false
+}
+
+// dynamoDBNumber converts the string form of a DynamoDB number into a JSON number.
+func dynamoDBNumber(s string) (interface{}, bool) {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return nil, false
+	}
+	return json.Number(s), true
+}
+
+// dynamoDBSet converts a string, binary or number set into a sorted list.
+func dynamoDBSet(descriptor string, inner interface{}) (interface{}, bool) {
+	members, ok := inner.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	set := make([]interface{}, len(members))
+	numbers := make([]float64, len(members))
+	for i, member := range members {
+		s, ok := member.(string)
+		if !ok {
+			return nil, false
+		}
+		set[i] = s
+		if descriptor == "NS" {
+			if set[i], ok = dynamoDBNumber(s); !ok {
+				return nil, false
+			}
+			numbers[i], _ = strconv.ParseFloat(s, 64)
+		}
+	}
+	sort.Sort(dynamoDBSetSorter{set, numbers, descriptor == "NS"})
+	return set, true
+}
+
+// dynamoDBSetSorter sorts the members of a set, numerically for number sets.
+type dynamoDBSetSorter struct {
+	members []interface{}
+	numbers []float64
+	numeric bool
+}
+
+func (s dynamoDBSetSorter) Len() int { return len(s.members) }
+
+func (s dynamoDBSetSorter) Less(i, j int) bool {
+	if s.numeric {
+		return s.numbers[i] < s.numbers[j]
+	}
+	return s.members[i].(string) < s.members[j].(string)
+}
+
+func (s dynamoDBSetSorter) Swap(i, j int) {
+	s.members[i], s.members[j] = s.members[j], s.members[i]
+	s.numbers[i], s.numbers[j] = s.numbers[j], s.numbers[i]
+}
@@ -0,0 +1,51 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDynamoDBAttributeValues(t *testing.T) {
+	expected := `{"Item": {
+		"id": {"S": "u1"},
+		"age": {"N": "41"},
+		"tags": {"SS": ["b", "a"]},
+		"scores": {"NS": ["10", "9"]},
+		"profile": {"M": {"S": {"S": "kept"}, "active": {"BOOL": true}, "nick": {"NULL": true}}},
+		"history": {"L": [{"N": "1"}, {"S": "two"}]}
+	}}`
+	actual := `{"Item": {
+		"id": {"S": "u1"},
+		"age": {"N": "42"},
+		"tags": {"SS": ["a", "b"]},
+		"scores": {"NS": ["9", "10"]},
+		"profile": {"M": {"S": {"S": "changed"}, "active": {"BOOL": true}, "nick": {"NULL": true}}},
+		"history": {"L": [{"N": "1"}, {"S": "three"}]}
+	}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithDynamoDBAttributeValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "Item.age", Op: OpChanged, Expected: float64(41), Actual: float64(42)},
+		{Path: "Item.history.1", Op: OpChanged, Expected: "two", Actual: "three"},
+		{Path: "Item.profile.S", Op: OpChanged, Expected: "kept", Actual: "changed"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+}
+
+func TestUnwrapAttributeValue(t *testing.T) {
+	for _, input := range []interface{}{
+		map[string]interface{}{"N": "abc"},
+		map[string]interface{}{"S": "a", "N": "1"},
+		map[string]interface{}{"L": []interface{}{"plain"}},
+		map[string]interface{}{"NULL": false},
+	} {
+		if got, ok := unwrapAttributeValue(input); ok {
+			t.Errorf("unwrapAttributeValue(%v) = %v, want no AttributeValue", input, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package colorisediff
+
+import "fmt"
+
+// elasticsearchNoise lists the noise patterns of the Elasticsearch preset: timings, relevance scores and the fields
+// describing which shards and nodes served a request. They are anchored at the envelope, so fields of "_source"
+// such as "credit_score" are still compared.
+var elasticsearchNoise = []string{"took.**", "_shards.**", "hits.max_score.**", "hits.hits.*._score", "hits.hits.*._shard",
+	"hits.hits.*._node", "hits.hits.*._routing"}
+
+// elasticsearchHitsPath is the path of the hits of a search response.
+const elasticsearchHitsPath = "hits.hits"
+
+// WithElasticsearch applies a preset for Elasticsearch search responses. Timings, scores and shard routing fields
+// are treated as noise, and "hits.hits" is compared keyed by each hit's "_id", so hits returned in a different
+// order do not show up as differences. The paths of hits name their key as "_id=<id>", e.g.
+// "hits.hits._id=5._source.title", so they are not mistaken for array indices; pointers and patches built from
+// them address the keyed hits, not positions in the original array.
+func WithElasticsearch() Option {
+	return func(o *options) {
+		o.elasticsearch = true
+		o.defaultNoise = append(o.defaultNoise, elasticsearchNoise...)
+	}
+}
+
+// keyElasticsearchHits replaces the "hits.hits" arrays of two search responses with objects keyed by "_id".
+func keyElasticsearchHits(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	if path != elasticsearchHitsPath {
+		return nil, nil, "", false
+	}
+	expectedHits, expectedIsArray := expected.([]interface{})
+	actualHits, actualIsArray := actual.([]interface{})
+	if !expectedIsArray || !actualIsArray {
+		return nil, nil, "", false
+	}
+	return keyElements(expectedHits, elasticsearchHitKey), keyElements(actualHits, elasticsearchHitKey), "", true
+}
+
+// elasticsearchHitKey identifies a hit by its "_id", prefixed so that numeric ids do not read as array indices.
+func elasticsearchHitKey(hit interface{}) string {
+	fields, ok := hit.(map[string]interface{})
+	if !ok {
+		return fmt.Sprint(hit)
+	}
+	return fmt.Sprint("_id=", fields["_id"])
+}
@@ -0,0 +1,71 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestElasticsearch(t *testing.T) {
+	expected := `{
+		"took": 12,
+		"_shards": {"total": 5, "successful": 5},
+		"hits": {"max_score": 1.2, "hits": [
+			{"_id": "a", "_score": 1.2, "_source": {"title": "Go"}},
+			{"_id": "b", "_score": 0.8, "_source": {"title": "Rust"}}
+		]}
+	}`
+	actual := `{
+		"took": 30,
+		"_shards": {"total": 5, "successful": 4},
+		"hits": {"max_score": 1.5, "hits": [
+			{"_id": "b", "_score": 1.5, "_source": {"title": "Rust"}},
+			{"_id": "a", "_score": 0.9, "_source": {"title": "Golang"}}
+		]}
+	}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithElasticsearch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []DiffEntry
+	for _, entry := range resp.Entries {
+		if !entry.Noised {
+			got = append(got, entry)
+		}
+	}
+	want := []DiffEntry{{Path: "hits.hits._id=a._source.title", Op: OpChanged, Expected: "Go", Actual: "Golang"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected entries %+v, want %+v", got, want)
+	}
+}
+
+func TestElasticsearchNumericIDs(t *testing.T) {
+	expected := `{"took": 1, "hits": {"hits": [
+		{"_id": "5", "_score": 1.0, "_source": {"title": "Go", "credit_score": 700}},
+		{"_id": "0", "_score": 0.5, "_source": {"title": "Rust", "credit_score": 650}}
+	]}}`
+	actual := `{"took": 2, "hits": {"hits": [
+		{"_id": "0", "_score": 0.7, "_source": {"title": "Rust", "credit_score": 650}},
+		{"_id": "5", "_score": 0.9, "_source": {"title": "Go", "credit_score": 720}}
+	]}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithElasticsearch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := resp.At("hits.hits._id=5._source.credit_score")
+	if entry == nil || entry.Noised {
+		t.Fatalf("expected a difference of the credit score of hit 5, got %+v", resp.Entries)
+	}
+	if pointer := entry.Pointer(); pointer != "/hits/hits/_id=5/_source/credit_score" {
+		t.Errorf("unexpected pointer %q", pointer)
+	}
+	patch, err := resp.Render(FormatPatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(patch, `"/hits/hits/_id=5/_source/credit_score"`) || strings.Contains(patch, `"/hits/hits/5/`) {
+		t.Errorf("expected the patch to address the hit by its id, got:\n%s", patch)
+	}
+}
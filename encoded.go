@@ -0,0 +1,218 @@
+package colorisediff
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minBase64Length is the shortest string considered as a base64 candidate, to avoid decoding ordinary words.
+const minBase64Length = 8
+
+// base64Regex matches strings made up solely of characters of the standard or URL-safe base64 alphabets.
+var base64Regex = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// jwtRegex matches compact JWS tokens, optionally prefixed with a "Bearer " authorization scheme.
+var jwtRegex = regexp.MustCompile(`^(Bearer )?([A-Za-z0-9_-]+)\.([A-Za-z0-9_-]+)\.([A-Za-z0-9_-]*)$`)
+
+// WithBase64Decoding makes the comparison decode differing string values that are base64-encoded JSON or text and
+// diff the decoded content instead. Decoded values are annotated in the output.
+func WithBase64Decoding() Option {
+	return func(o *options) {
+		o.base64Decoding = true
+	}
+}
+
+// WithJWTDecoding makes the comparison decode differing JWT-shaped string values and diff their header and claims as
+// JSON instead of highlighting the opaque tokens. The signature is only compared when compareSignature is set, since
+// it necessarily changes whenever the claims do.
+func WithJWTDecoding(compareSignature bool) Option {
+	return func(o *options) {
+		o.jwtDecoding = true
+		o.jwtSignature = compareSignature
+	}
+}
+
+// decodeJWTPair returns a transform replacing two differing JWTs with objects holding their decoded header, claims
+// and, if compareSignature is set, signature.
+func decodeJWTPair(compareSignature bool) pairTransform {
+	return func(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		e, a, ok := differingStrings(expected, actual)
+		if !ok {
+			return nil, nil, "", false
+		}
+		expectedToken, ok := decodeJWT(e, compareSignature)
+		if !ok {
+			return nil, nil, "", false
+		}
+		actualToken, ok := decodeJWT(a, compareSignature)
+		if !ok {
+			return nil, nil, "", false
+		}
+		note := "decoded JWT"
+		if !compareSignature {
+			note += ", signature ignored"
+		}
+		return expectedToken, actualToken, note, true
+	}
+}
+
+// decodeJWT decodes a compact JWS token into an object with "header", "claims" and optionally "signature" fields.
+func decodeJWT(token string, withSignature bool) (map[string]interface{}, bool) {
+	parts := jwtRegex.FindStringSubmatch(token)
+	if parts == nil {
+		return nil, false
+	}
+	header, ok := decodeJWTSegment(parts[2])
+	if !ok {
+		return nil, false
+	}
+	if _, ok := header["alg"]; !ok {
+		return nil, false
+	}
+	claims, ok := decodeJWTSegment(parts[3])
+	if !ok {
+		return nil, false
+	}
+	decoded := map[string]interface{}{"header": header, "claims": claims}
+	if parts[1] != "" {
+		decoded["scheme"] = strings.TrimSpace(parts[1])
+	}
+	if withSignature {
+		decoded["signature"] = parts[4]
+	}
+	return decoded, true
+}
+
+// decodeJWTSegment decodes a base64url-encoded JSON object segment of a JWT.
+func decodeJWTSegment(segment string) (map[string]interface{}, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return nil, false
+	}
+	doc, ok := decodeEmbeddedJSON(string(decoded))
+	if !ok {
+		return nil, false
+	}
+	object, ok := doc.(map[string]interface{})
+	return object, ok
+}
+
+// WithURLNormalization makes the comparison parse differing absolute URL values and compare their scheme, host,
+// path, query parameters and fragment structurally. Query parameter order and percent-encoding are ignored, and
+// the output names the differing component instead of highlighting the whole URL.
+func WithURLNormalization() Option {
+	return func(o *options) {
+		o.urlNormalization = true
+	}
+}
+
+// decodeURLPair replaces two differing absolute URLs with objects holding their normalized components.
+func decodeURLPair(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	e, a, ok := differingStrings(expected, actual)
+	if !ok {
+		return nil, nil, "", false
+	}
+	expectedURL, ok := decodeURL(e)
+	if !ok {
+		return nil, nil, "", false
+	}
+	actualURL, ok := decodeURL(a)
+	if !ok {
+		return nil, nil, "", false
+	}
+	return expectedURL, actualURL, "compared as URL", true
+}
+
+// decodeURL parses an absolute URL into an object with "scheme", "host", "path", "query" and, when present,
+// "user" and "fragment" fields. Query parameters with a single value map to strings, others to arrays.
+func decodeURL(s string) (map[string]interface{}, bool) {
+	if strings.ContainsAny(s, " \t\n") {
+		return nil, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, false
+	}
+	query := map[string]interface{}{}
+	for key, values := range u.Query() {
+		if len(values) == 1 {
+			query[key] = values[0]
+			continue
+		}
+		list := make([]interface{}, len(values))
+		for i, value := range values {
+			list[i] = value
+		}
+		query[key] = list
+	}
+	decoded := map[string]interface{}{
+		"scheme": strings.ToLower(u.Scheme),
+		"host":   strings.ToLower(u.Host),
+		"path":   u.Path,
+		"query":  query,
+	}
+	if u.User != nil {
+		decoded["user"] = u.User.String()
+	}
+	if u.Fragment != "" {
+		decoded["fragment"] = u.Fragment
+	}
+	return decoded, true
+}
+
+// decodeBase64Pair replaces two differing base64 strings with their decoded content. Decoded JSON objects and
+// arrays are diffed structurally; other decoded content is compared as text.
+func decodeBase64Pair(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	e, a, ok := differingStrings(expected, actual)
+	if !ok {
+		return nil, nil, "", false
+	}
+	expectedText, ok := decodeBase64Text(e)
+	if !ok {
+		return nil, nil, "", false
+	}
+	actualText, ok := decodeBase64Text(a)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	expectedDoc, expectedIsJSON := decodeEmbeddedJSON(expectedText)
+	actualDoc, actualIsJSON := decodeEmbeddedJSON(actualText)
+	if expectedIsJSON && actualIsJSON {
+		return expectedDoc, actualDoc, "decoded from base64", true
+	}
+	return expectedText, actualText, "decoded from base64", true
+}
+
+// decodeBase64Text decodes a base64 string in any of the common alphabets and paddings and returns the decoded
+// content if it is printable UTF-8 text.
+func decodeBase64Text(s string) (string, bool) {
+	if len(s) < minBase64Length || !base64Regex.MatchString(s) {
+		return "", false
+	}
+	encodings := []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding}
+	for _, encoding := range encodings {
+		decoded, err := encoding.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if text := string(decoded); isPrintableText(text) {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// isPrintableText reports whether s is non-empty valid UTF-8 consisting of printable characters and whitespace.
+func isPrintableText(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}) == -1
+}
@@ -0,0 +1,134 @@
+package colorisediff
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBase64Decoding(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	json1 := `{"payload":"` + encode(`{"user":"Cat","id":1}`) + `","text":"` + encode("hello brave world") + `"}`
+	json2 := `{"payload":"` + encode(`{"user":"Dog","id":1}`) + `","text":"` + encode("hello brave planet") + `"}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithBase64Decoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := resp.At("payload.user")
+	if entry == nil || entry.Expected != "Cat" || entry.Actual != "Dog" || entry.Note != "decoded from base64" {
+		t.Errorf("unexpected payload entry %+v in %+v", entry, resp.Entries)
+	}
+	entry = resp.At("text")
+	if entry == nil || entry.Expected != "hello brave world" || entry.Actual != "hello brave planet" {
+		t.Errorf("unexpected text entry %+v", entry)
+	}
+	if !strings.Contains(resp.Expected, "note: payload: decoded from base64") {
+		t.Errorf("expected the decoding to be annotated, got:\n%s", resp.Expected)
+	}
+}
+
+func TestDecodeBase64Text(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		decoded string
+		ok      bool
+	}{
+		{name: "padded standard", input: "aGVsbG8gd29ybGQ=", decoded: "hello world", ok: true},
+		{name: "raw url", input: "aGVsbG8_Pz8_", decoded: "hello????", ok: true},
+		{name: "ordinary word", input: "password", ok: false},
+		{name: "too short", input: "aGk=", ok: false},
+		{name: "binary content", input: base64.StdEncoding.EncodeToString([]byte{0, 1, 2, 3, 250, 251}), ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, ok := decodeBase64Text(tt.input)
+			if ok != tt.ok || decoded != tt.decoded {
+				t.Errorf("decodeBase64Text(%q) = %q, %v; want %q, %v", tt.input, decoded, ok, tt.decoded, tt.ok)
+			}
+		})
+	}
+}
+
+func TestJWTDecoding(t *testing.T) {
+	segment := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	header := segment(`{"alg":"HS256","typ":"JWT"}`)
+	token1 := header + "." + segment(`{"sub":"1","role":"user"}`) + ".c2lnbmF0dXJlMQ"
+	token2 := header + "." + segment(`{"sub":"1","role":"admin"}`) + ".c2lnbmF0dXJlMg"
+	json1 := `{"token":"` + token1 + `"}`
+	json2 := `{"token":"` + token2 + `"}`
+
+	tests := []struct {
+		name             string
+		compareSignature bool
+		count            int
+	}{
+		{name: "signature ignored", compareSignature: false, count: 1},
+		{name: "signature compared", compareSignature: true, count: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithJWTDecoding(tt.compareSignature))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Entries) != tt.count {
+				t.Errorf("expected %d entries, got %+v", tt.count, resp.Entries)
+			}
+			entry := resp.At("token.claims.role")
+			if entry == nil || entry.Expected != "user" || entry.Actual != "admin" {
+				t.Errorf("unexpected claims entry %+v", entry)
+			}
+			if tt.compareSignature && resp.At("token.signature") == nil {
+				t.Errorf("expected the signature to be compared, got %+v", resp.Entries)
+			}
+		})
+	}
+
+	if _, ok := decodeJWT("not.a.jwt", false); ok {
+		t.Error("expected a non-JWT string not to be decoded")
+	}
+}
+
+func TestURLNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		url1     string
+		url2     string
+		expected []DiffEntry
+	}{
+		{
+			name: "query order and encoding",
+			url1: "https://example.com/a%20b?x=1&y=2",
+			url2: "HTTPS://EXAMPLE.com/a%20b?y=2&x=%31",
+		},
+		{
+			name:     "changed query parameter",
+			url1:     "https://example.com/users?page=1&limit=10",
+			url2:     "https://example.com/users?limit=10&page=2",
+			expected: []DiffEntry{{Path: "link.query.page", Op: OpChanged, Expected: "1", Actual: "2", Note: "compared as URL"}},
+		},
+		{
+			name:     "changed host",
+			url1:     "https://a.example.com/users",
+			url2:     "https://b.example.com/users",
+			expected: []DiffEntry{{Path: "link.host", Op: OpChanged, Expected: "a.example.com", Actual: "b.example.com", Note: "compared as URL"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			json1 := `{"link":"` + tt.url1 + `"}`
+			json2 := `{"link":"` + tt.url2 + `"}`
+			resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithURLNormalization())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Entries, tt.expected) {
+				t.Errorf("unexpected entries %+v, want %+v", resp.Entries, tt.expected)
+			}
+		})
+	}
+}
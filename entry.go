@@ -0,0 +1,215 @@
+package colorisediff
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Op describes the kind of change recorded by a DiffEntry.
+type Op string
+
+const (
+	// OpAdded marks a value that is present only in the actual document.
+	OpAdded Op = "added"
+	// OpRemoved marks a value that is present only in the expected document.
+	OpRemoved Op = "removed"
+	// OpChanged marks a value that is present in both documents with different content.
+	OpChanged Op = "changed"
+)
+
+// DiffEntry describes a single leaf-level difference between the expected and actual documents.
+// Path: The gjson-style path of the differing value, e.g. "zoo.animals.1.age". The root is "".
+// Op: The kind of change.
+// Expected: The expected value, nil for added values.
+// Actual: The actual value, nil for removed values.
+// Noised: Whether the path matched a noise rule and is therefore ignored.
+// Note: Annotations about how the values were prepared for comparison, e.g. that they were decoded from base64.
+// Violation: Why the actual value no longer validates against the schema given with WithJSONSchema, empty if it
+// validates or no schema was given.
+// Severity: The label assigned by the first matching rule given with WithSeverityRules, e.g. "critical".
+// NoiseReason: Why the entry is noised, from the rules given with WithNoiseReasons, e.g. "server clock".
+// Waiver: The reason of the waiver given with WithWaivers that accepts the difference, empty if none does.
+// Delta: The difference of a changed number given with WithNumericDeltas, e.g. "+3, +7.1%".
+type DiffEntry struct {
+	Path        string
+	Op          Op
+	Expected    interface{}
+	Actual      interface{}
+	Noised      bool
+	Note        string
+	Violation   string
+	Severity    string
+	NoiseReason string
+	Waiver      string
+	Delta       string
+}
+
+// Pointer returns the RFC 6901 JSON Pointer of the differing value, e.g. "/zoo/animals/1/age", for generated patches
+// and tools that do not read gjson-style paths. The root is "".
+func (e DiffEntry) Pointer() string {
+	return pathToPointer(e.Path)
+}
+
+// At returns the diff entry recorded at the given path, or nil if the value at that path did not change.
+// path: The path to look up, e.g. "zoo.animals.1.age". Paths starting with "/" are also looked up as JSON Pointers,
+// e.g. "/zoo/animals/1/age", and paths starting with "$" as JSONPath expressions, e.g. "$.zoo.animals[1].age".
+func (d Diff) At(path string) *DiffEntry {
+	if entry := d.at(path); entry != nil {
+		return entry
+	}
+	syntax := GJSONPath
+	switch {
+	case strings.HasPrefix(path, "/"):
+		syntax = JSONPointer
+	case strings.HasPrefix(path, "$"):
+		syntax = JSONPath
+	}
+	if converted, err := syntax.Parse(path); err == nil && syntax != GJSONPath {
+		return d.at(converted)
+	}
+	return nil
+}
+
+// at returns the diff entry recorded at the given gjson-style path, or nil.
+func (d Diff) at(path string) *DiffEntry {
+	for i := range d.Entries {
+		if d.Entries[i].Path == path {
+			return &d.Entries[i]
+		}
+	}
+	return nil
+}
+
+// ChangedPaths returns the gjson-style paths of the differing leaves that are not noised, in the order of the entries,
+// e.g. for asserting that only "items.*.price" changed or for suggesting noise rules.
+func (d Diff) ChangedPaths() []string {
+	paths := []string{}
+	for _, entry := range d.Entries {
+		if !entry.Noised {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths
+}
+
+// diffValues walks the expected and actual values in tandem and returns an entry for every differing leaf.
+// path: The gjson-style path of the current values.
+// noisePath: The path of the current values in the format understood by checkNoise.
+// expected, actual: The decoded JSON values to compare.
+// noise: A map containing noise elements to be flagged on the resulting entries.
+func diffValues(path, noisePath string, expected, actual interface{}, noise map[string][]string) []DiffEntry {
+	var entries []DiffEntry
+	walkValues(path, noisePath, expected, actual, noise, func(entry DiffEntry) {
+		entries = append(entries, entry)
+	})
+	return entries
+}
+
+// collectEntries returns the annotated entries for every differing leaf, or replaced subtree, passing each to the
+// OnDiff hook as soon as it is found. Entries found once the memory budget is spent are only counted in the returned
+// summaries.
+func (o *options) collectEntries(expected, actual interface{}, noise map[string][]string, tr *transformer, validator *schemaValidator) ([]DiffEntry, []SpillSummary) {
+	violations := validator.violations(actual)
+	budget := o.newMemoryBudget()
+	var entries []DiffEntry
+	walkValues("", "", expected, actual, noise, collapseEntries(o.replacedSubtrees(expected, actual, noise), func(entry DiffEntry) {
+		entry = o.redactEntry(o.labelEntry(o.waive(o.explainNoise(o.annotateDelta(annotateViolations(tr.annotate(entry), violations))))))
+		if o.onDiff != nil {
+			o.onDiff(entry)
+		}
+		if budget.admit(entry) {
+			entries = append(entries, entry)
+		}
+	}))
+	return entries, budget.spilled
+}
+
+// walkValues walks the expected and actual values in tandem like diffValues, passing every differing leaf to visit
+// as soon as it is found.
+func walkValues(path, noisePath string, expected, actual interface{}, noise map[string][]string, visit func(DiffEntry)) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range unionKeys(e, a) {
+			childPath := joinPath(path, escapePathKey(key))
+			childNoisePath := noisePath + "." + key
+			expectedValue, inExpected := e[key]
+			actualValue, inActual := a[key]
+			switch {
+			case !inActual:
+				visit(DiffEntry{Path: childPath, Op: OpRemoved, Expected: expectedValue, Noised: checkNoise(childNoisePath, noise)})
+			case !inExpected:
+				visit(DiffEntry{Path: childPath, Op: OpAdded, Actual: actualValue, Noised: checkNoise(childNoisePath, noise)})
+			default:
+				walkValues(childPath, childNoisePath, expectedValue, actualValue, noise, visit)
+			}
+		}
+		return
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(e) || i < len(a); i++ {
+			childPath := joinPath(path, strconv.Itoa(i))
+			childNoisePath := noisePath + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(a):
+				visit(DiffEntry{Path: childPath, Op: OpRemoved, Expected: e[i], Noised: checkNoise(childNoisePath, noise)})
+			case i >= len(e):
+				visit(DiffEntry{Path: childPath, Op: OpAdded, Actual: a[i], Noised: checkNoise(childNoisePath, noise)})
+			default:
+				walkValues(childPath, childNoisePath, e[i], a[i], noise, visit)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		visit(DiffEntry{Path: path, Op: OpChanged, Expected: expected, Actual: actual, Noised: checkNoise(noisePath, noise)})
+	}
+}
+
+// unionKeys returns the keys of both maps in sorted order without duplicates.
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinPath appends a component to a gjson-style path.
+func joinPath(path, component string) string {
+	if path == "" {
+		return component
+	}
+	return path + "." + component
+}
+
+// escapePathKey escapes the characters that gjson treats specially inside a path component.
+func escapePathKey(key string) string {
+	if !strings.ContainsAny(key, `.*?\|#@`) {
+		return key
+	}
+	var builder strings.Builder
+	for _, char := range key {
+		if strings.ContainsRune(`.*?\|#@`, char) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
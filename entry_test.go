@@ -0,0 +1,110 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		json1    string
+		json2    string
+		path     string
+		expected *DiffEntry
+		count    int
+	}{
+		{
+			name:     "changed leaf inside array",
+			json1:    `{"zoo":{"animals":[{"name":"Elephant","age":10},{"name":"Parrot","age":2}]}}`,
+			json2:    `{"zoo":{"animals":[{"name":"Elephant","age":10},{"name":"Parrot","age":3}]}}`,
+			path:     "zoo.animals.1.age",
+			expected: &DiffEntry{Path: "zoo.animals.1.age", Op: OpChanged, Expected: float64(2), Actual: float64(3)},
+			count:    1,
+		},
+		{
+			name:     "unchanged leaf",
+			json1:    `{"zoo":{"animals":[{"name":"Elephant","age":10},{"name":"Parrot","age":2}]}}`,
+			json2:    `{"zoo":{"animals":[{"name":"Elephant","age":10},{"name":"Parrot","age":3}]}}`,
+			path:     "zoo.animals.0.age",
+			expected: nil,
+			count:    1,
+		},
+		{
+			name:     "added key",
+			json1:    `{"a":1}`,
+			json2:    `{"a":1,"b":"x"}`,
+			path:     "b",
+			expected: &DiffEntry{Path: "b", Op: OpAdded, Actual: "x"},
+			count:    1,
+		},
+		{
+			name:     "removed array element",
+			json1:    `{"a":[1,2,3]}`,
+			json2:    `{"a":[1,2]}`,
+			path:     "a.2",
+			expected: &DiffEntry{Path: "a.2", Op: OpRemoved, Expected: float64(3)},
+			count:    1,
+		},
+		{
+			name:     "key containing a dot",
+			json1:    `{"a.b":1}`,
+			json2:    `{"a.b":2}`,
+			path:     `a\.b`,
+			expected: &DiffEntry{Path: `a\.b`, Op: OpChanged, Expected: float64(1), Actual: float64(2)},
+			count:    1,
+		},
+		{
+			name:     "noised leaf is flagged",
+			json1:    `{"ts":1,"id":1}`,
+			json2:    `{"ts":2,"id":1}`,
+			path:     "ts",
+			expected: &DiffEntry{Path: "ts", Op: OpChanged, Expected: float64(1), Actual: float64(2), Noised: true},
+			count:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(tt.json1), []byte(tt.json2), map[string][]string{"ts": {}}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Entries) != tt.count {
+				t.Errorf("expected %d entries, got %d: %+v", tt.count, len(resp.Entries), resp.Entries)
+			}
+			if got := resp.At(tt.path); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("At(%q) = %+v, want %+v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	json1 := `{"items":[{"price":1,"name":"a"},{"price":2,"name":"b"}],"ts":1}`
+	json2 := `{"items":[{"price":3,"name":"a"},{"price":4,"name":"b"}],"ts":2}`
+	resp, err := CompareJSON([]byte(json1), []byte(json2), map[string][]string{"ts": {}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"items.0.price", "items.1.price"}
+	if got := resp.ChangedPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedPaths() = %v, want %v", got, want)
+	}
+	if got := (Diff{}).ChangedPaths(); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty list without differences, got %#v", got)
+	}
+}
+
+func TestDiffEntryPointer(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		"zoo.animals.1.age": "/zoo/animals/1/age",
+		`a/b.c~d.e\.f`:      "/a~1b/c~0d/e.f",
+	}
+	for path, want := range tests {
+		if got := (DiffEntry{Path: path}).Pointer(); got != want {
+			t.Errorf("Pointer() of %q = %q, want %q", path, got, want)
+		}
+	}
+}
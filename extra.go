@@ -0,0 +1,119 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithIgnoreExtraElements makes the comparison tolerate arrays that have more elements in the actual document than
+// in the expected one, for APIs that append new records over time. The extra trailing elements are reported as
+// noised entries with a note instead of as additions, and the colorized output lists a note per array instead of
+// the elements. Combined with WithUnorderedArrays, unmatched actual elements of unordered arrays are tolerated too.
+func WithIgnoreExtraElements() Option {
+	return func(o *options) {
+		o.ignoreExtraElements = true
+	}
+}
+
+// WithIgnoreExtraKeys makes the comparison tolerate objects that have more keys in the actual document than in the
+// expected one, so the expected document only needs to list the keys it cares about. The extra keys are reported
+// like the elements ignored by WithIgnoreExtraElements.
+func WithIgnoreExtraKeys() Option {
+	return func(o *options) {
+		o.ignoreExtraKeys = true
+	}
+}
+
+// trimExtra returns a copy of the actual value without the members the options tolerate: elements of arrays beyond
+// the length of the expected array at the same path and keys missing from the expected object at the same path.
+// It records the paths of the members left out and a note per trimmed array or object, which only annotates the
+// members left out of it.
+func (t *transformer) trimExtra(o *options, path string, expected, actual interface{}) interface{} {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		trimmed := make(map[string]interface{}, len(a))
+		extra := 0
+		for key, actualValue := range a {
+			childPath := joinPath(path, escapePathKey(key))
+			expectedValue, exists := e[key]
+			switch {
+			case exists:
+				trimmed[key] = t.trimExtra(o, childPath, expectedValue, actualValue)
+			case o.ignoreExtraKeys:
+				t.addExtra(childPath)
+				extra++
+			default:
+				trimmed[key] = actualValue
+			}
+		}
+		if extra > 0 {
+			t.addExtraNote(path, extraNote(extra, "key"))
+		}
+		return trimmed
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return actual
+		}
+		if o.ignoreExtraElements && len(a) > len(e) {
+			for i := len(e); i < len(a); i++ {
+				t.addExtra(joinPath(path, strconv.Itoa(i)))
+			}
+			t.addExtraNote(path, extraNote(len(a)-len(e), "element"))
+			a = a[:len(e)]
+		}
+		trimmed := make([]interface{}, len(a))
+		for i := range a {
+			if i < len(e) {
+				trimmed[i] = t.trimExtra(o, joinPath(path, strconv.Itoa(i)), e[i], a[i])
+			} else {
+				trimmed[i] = a[i]
+			}
+		}
+		return trimmed
+	}
+	return actual
+}
+
+// addExtra records the path of a member left out by trimExtra.
+func (t *transformer) addExtra(path string) {
+	if t.extra == nil {
+		t.extra = map[string]bool{}
+	}
+	t.extra[path] = true
+}
+
+// addExtraNote records the note of an array or object trimmed by trimExtra.
+func (t *transformer) addExtraNote(path, note string) {
+	if t.extraNotes == nil {
+		t.extraNotes = map[string]string{}
+	}
+	t.extraNotes[path] = note
+}
+
+// renderExtraNotes renders the notes of the arrays and objects trimmed by trimExtra as lines to be appended below
+// the actual side, where the ignored members are.
+func (t *transformer) renderExtraNotes() string {
+	var builder strings.Builder
+	for _, path := range sortedKeys(t.extraNotes) {
+		label := path
+		if label == "" {
+			label = "(root)"
+		}
+		builder.WriteString(breakLines(fmt.Sprintf("note: %s: %s", label, t.extraNotes[path])) + "\n")
+	}
+	return builder.String()
+}
+
+// extraNote describes how many extra members of the given kind were ignored, e.g. "2 extra elements ignored".
+func extraNote(count int, kind string) string {
+	if count != 1 {
+		kind += "s"
+	}
+	return fmt.Sprintf("%d extra %s ignored", count, kind)
+}
@@ -0,0 +1,101 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithIgnoreExtraElements(t *testing.T) {
+	json1 := `{"id":1,"records":[{"n":1},{"n":2}],"name":"Cat"}`
+	json2 := `{"id":1,"records":[{"n":1},{"n":2},{"n":3},{"n":4}],"name":"Dog"}`
+
+	plain, err := CompareJSON([]byte(json1), []byte(json2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := plain.Stats(); stats.Added != 2 {
+		t.Errorf("without the option the extra elements should be additions, got %+v", stats)
+	}
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithIgnoreExtraElements())
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := "2 extra elements ignored"
+	want := []DiffEntry{
+		{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"},
+		{Path: "records.2", Op: OpAdded, Actual: map[string]interface{}{"n": 3.0}, Noised: true, Note: note},
+		{Path: "records.3", Op: OpAdded, Actual: map[string]interface{}{"n": 4.0}, Noised: true, Note: note},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+	if stats := resp.Stats(); stats.Total != 1 || stats.Noised != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if strings.Contains(resp.Actual, `"n": 3`) || !strings.Contains(resp.Actual, "note: records: "+note) {
+		t.Errorf("expected a note instead of the extra elements, got:\n%s", resp.Actual)
+	}
+}
+
+func TestWithIgnoreExtraElementsOnly(t *testing.T) {
+	resp, err := CompareJSON([]byte(`[1,2]`), []byte(`[1,2,3]`), nil, true, WithIgnoreExtraElements())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "2", Op: OpAdded, Actual: 3.0, Noised: true, Note: "1 extra element ignored"}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+	if resp.Expected != "" || resp.Actual != "" {
+		t.Errorf("expected no colorized output, got:\n%s\n%s", resp.Expected, resp.Actual)
+	}
+
+	resp, err = CompareJSON([]byte(`[1,2]`), []byte(`[3,2,1]`), nil, true, WithIgnoreExtraElements(), WithUnorderedArrays(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := resp.Stats(); stats.Total != 0 || stats.Noised != 1 {
+		t.Errorf("expected the unmatched element to be ignored, got %+v", resp.Entries)
+	}
+}
+
+func TestWithIgnoreExtraKeys(t *testing.T) {
+	json1 := `{"user":{"name":"Cat"}}`
+	json2 := `{"user":{"name":"Cat","id":7,"a.b":true},"version":2}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithIgnoreExtraKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "user.a\\.b", Op: OpAdded, Actual: true, Noised: true, Note: "2 extra keys ignored"},
+		{Path: "user.id", Op: OpAdded, Actual: 7.0, Noised: true, Note: "2 extra keys ignored"},
+		{Path: "version", Op: OpAdded, Actual: 2.0, Noised: true, Note: "1 extra key ignored"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+}
+
+func TestWithIgnoreExtraKeysNotesStayOnMembers(t *testing.T) {
+	json1 := `{"a":{"x":1}}`
+	json2 := `{"a":{"x":2},"b":true}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithIgnoreExtraKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "a.x", Op: OpChanged, Expected: 1.0, Actual: 2.0},
+		{Path: "b", Op: OpAdded, Actual: true, Noised: true, Note: "1 extra key ignored"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+	note := "note: (root): 1 extra key ignored"
+	if strings.Contains(resp.Expected, note) || strings.Count(resp.Actual, note) != 1 {
+		t.Errorf("expected the note once below the actual side, got\n%s\n%s", resp.Expected, resp.Actual)
+	}
+}
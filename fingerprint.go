@@ -0,0 +1,32 @@
+package colorisediff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Fingerprint returns a stable hash of the structural difference: the paths, kinds of change and values of the
+// entries that are not noised. It does not depend on colors, rendering options or the order of the entries, so
+// equal failures share a fingerprint across runs, e.g. for deduplicating reports, caching or waivers. A diff
+// without differences that are not noised has the fingerprint of an empty diff.
+func (d Diff) Fingerprint() string {
+	lines := make([]string, 0, len(d.Entries))
+	for _, entry := range d.Entries {
+		if !entry.Noised {
+			lines = append(lines, entry.Path+"\x00"+entryHash(entry))
+		}
+	}
+	sort.Strings(lines)
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// entryHash hashes the kind of change and the values of an entry, independently of its path and annotations.
+func entryHash(entry DiffEntry) string {
+	digest := sha256.Sum256([]byte(string(entry.Op) + "\x00" + formatValue(entry.Expected) + "\x00" + formatValue(entry.Actual)))
+	return hex.EncodeToString(digest[:8])
+}
@@ -0,0 +1,44 @@
+package colorisediff
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	expected := `{"id":1,"name":"Cat","ts":1,"tags":["a"]}`
+	fingerprint := func(actual string, opts ...Option) string {
+		t.Helper()
+		diff, err := CompareJSON([]byte(expected), []byte(actual), map[string][]string{"ts": {}}, true, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diff.Fingerprint()
+	}
+
+	base := fingerprint(`{"id":1,"name":"Dog","ts":2,"tags":["b"]}`)
+	if len(base) != 32 {
+		t.Fatalf("unexpected fingerprint %q", base)
+	}
+	tests := []struct {
+		name   string
+		actual string
+		opts   []Option
+		same   bool
+	}{
+		{name: "colors and rendering", actual: `{"id":1,"name":"Dog","ts":2,"tags":["b"]}`, opts: []Option{WithIndent("\t"), WithLegend()}, same: true},
+		{name: "key order", actual: `{"tags":["b"],"ts":2,"name":"Dog","id":1}`, same: true},
+		{name: "noised value", actual: `{"id":1,"name":"Dog","ts":3,"tags":["b"]}`, same: true},
+		{name: "changed value", actual: `{"id":1,"name":"Cow","ts":2,"tags":["b"]}`},
+		{name: "changed path", actual: `{"id":2,"name":"Cat","ts":2,"tags":["b"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.actual, tt.opts...); (got == base) != tt.same {
+				t.Errorf("fingerprint %s, base %s, want same = %v", got, base, tt.same)
+			}
+		})
+	}
+
+	diff, _ := CompareJSON([]byte(expected), []byte(expected), nil, true)
+	if diff.Fingerprint() != (Diff{}).Fingerprint() {
+		t.Error("expected equal documents to have the fingerprint of an empty diff")
+	}
+}
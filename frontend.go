@@ -0,0 +1,23 @@
+package colorisediff
+
+// compareDecoded compares two documents decoded by one of the non-JSON front-ends. The values must only contain
+// types that encoding/json can marshal; they are re-encoded as JSON and handed to CompareJSON so every front-end
+// shares the same comparison and rendering pipeline.
+func compareDecoded(expected, actual interface{}, noise map[string][]string, disableColor bool, opts []Option) (Diff, error) {
+	expectedJSON, err := encodeJSON(expected)
+	if err != nil {
+		return Diff{}, err
+	}
+	actualJSON, err := encodeJSON(actual)
+	if err != nil {
+		return Diff{}, err
+	}
+	return CompareJSON(expectedJSON, actualJSON, noise, disableColor, opts...)
+}
+
+// CompareValues compares two decoded documents, e.g. converted by a front-end living in a package of its own, and
+// returns the colorized differences. The values must only contain types that encoding/json can marshal, such as the
+// maps, slices and scalars produced by json.Unmarshal.
+func CompareValues(expected, actual interface{}, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	return compareDecoded(expected, actual, noise, disableColor, opts)
+}
@@ -0,0 +1,75 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// GenerateTemplate turns a sample JSON document, e.g. a response of a new endpoint, into an expectation template
+// to bootstrap its assertion files. Strings that look volatile are replaced with the type matcher of their kind:
+// UUIDs with "{{uuid}}" and RFC 3339 timestamps with "{{timestamp}}". Other values are kept as they are. The
+// template is indented with two spaces and keeps the key order of the sample; compare responses against it with
+// WithKeployTemplates, which treats the matchers as matching any value of their kind.
+func GenerateTemplate(sample []byte) ([]byte, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(sample, &decoded); err != nil {
+		return nil, fmt.Errorf("decoding sample: %w", err)
+	}
+	var buffer bytes.Buffer
+	writeTemplate(&buffer, gjson.ParseBytes(sample), 0)
+	return buffer.Bytes(), nil
+}
+
+// writeTemplate writes the template of a parsed value indented at the given depth.
+func writeTemplate(buffer *bytes.Buffer, value gjson.Result, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	switch {
+	case value.IsObject():
+		first := true
+		value.ForEach(func(key, member gjson.Result) bool {
+			if first {
+				buffer.WriteString("{\n")
+			} else {
+				buffer.WriteString(",\n")
+			}
+			first = false
+			buffer.WriteString(indent + key.Raw + ": ")
+			writeTemplate(buffer, member, depth+1)
+			return true
+		})
+		if first {
+			buffer.WriteString("{}")
+			return
+		}
+		buffer.WriteString("\n" + indent[2:] + "}")
+	case value.IsArray():
+		elements := value.Array()
+		if len(elements) == 0 {
+			buffer.WriteString("[]")
+			return
+		}
+		buffer.WriteString("[\n")
+		for i, element := range elements {
+			if i > 0 {
+				buffer.WriteString(",\n")
+			}
+			buffer.WriteString(indent)
+			writeTemplate(buffer, element, depth+1)
+		}
+		buffer.WriteString("\n" + indent[2:] + "]")
+	case value.Type == gjson.String:
+		for _, matcher := range typeMatchers {
+			if matcher.matches(value.String()) {
+				buffer.WriteString(`"{{` + matcher.name + `}}"`)
+				return
+			}
+		}
+		buffer.WriteString(value.Raw)
+	default:
+		buffer.WriteString(value.Raw)
+	}
+}
@@ -0,0 +1,45 @@
+package colorisediff
+
+import "testing"
+
+func TestGenerateTemplate(t *testing.T) {
+	sample := `{"id":"123e4567-e89b-12d3-a456-426614174000","name":"Ada","createdAt":"2024-01-02T03:04:05Z",` +
+		`"tags":[],"meta":{},"items":[{"ref":"8f14e45f-ceea-467f-a0e6-7c4d5f1b2a3c","qty":2,"ok":true,"note":null}]}`
+	got, err := GenerateTemplate([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "id": "{{uuid}}",
+  "name": "Ada",
+  "createdAt": "{{timestamp}}",
+  "tags": [],
+  "meta": {},
+  "items": [
+    {
+      "ref": "{{uuid}}",
+      "qty": 2,
+      "ok": true,
+      "note": null
+    }
+  ]
+}`
+	if string(got) != want {
+		t.Errorf("unexpected template\n%s", got)
+	}
+
+	// The template matches other responses of the same endpoint.
+	other := `{"id":"0b7e2f7a-1c1d-4a4e-9f3b-2b9f0e6c5d4a","name":"Ada","createdAt":"2025-06-07T08:09:10.5+02:00",` +
+		`"tags":[],"meta":{},"items":[{"ref":"9c1f6e2d-3b4a-4c5d-8e7f-6a5b4c3d2e1f","qty":2,"ok":true,"note":null}]}`
+	resp, err := CompareJSON(got, []byte(other), nil, true, WithKeployTemplates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 0 {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	if _, err := GenerateTemplate([]byte(`{"id":`)); err == nil {
+		t.Error("expected an error for an invalid sample")
+	}
+}
@@ -0,0 +1,125 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden rewrites the golden renders of the corpus instead of comparing against them:
+//
+//	go test -run TestGoldenCorpus -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden renders in testdata/golden")
+
+// goldenCorpus is the directory of the current version of the corpus. Every case is a directory holding the
+// expected.json and actual.json inputs, an optional case.json and one .golden file per rendered format.
+const goldenCorpus = "testdata/golden/v1"
+
+// goldenCase is the optional configuration of a corpus case.
+// Kind: "json" (the default) compares the inputs with CompareJSON, "headers" compares them as header maps with
+// CompareHeaders.
+type goldenCase struct {
+	Kind  string              `json:"kind"`
+	Noise map[string][]string `json:"noise"`
+}
+
+// TestGoldenCorpus is the stability contract of the rendered output: it renders every case of the corpus in every
+// format and fails when a render differs from the committed golden file. Intentional changes are recorded by
+// running the test with -update and reviewing the diff of the golden files.
+func TestGoldenCorpus(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join(goldenCorpus, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatalf("no cases in %s", goldenCorpus)
+	}
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			diff := compareGoldenCase(t, dir)
+			for name, render := range goldenRenders(t, diff) {
+				path := filepath.Join(dir, name+".golden")
+				if *updateGolden {
+					if err := os.WriteFile(path, []byte(render), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("%v (run with -update to create it)", err)
+				}
+				if render != string(want) {
+					t.Errorf("%s render changed (run with -update if intended):\n--- got\n%s\n--- want\n%s", name, render, want)
+				}
+			}
+		})
+	}
+}
+
+// compareGoldenCase compares the inputs of the corpus case in the directory.
+func compareGoldenCase(t *testing.T, dir string) Diff {
+	t.Helper()
+	expected, err := os.ReadFile(filepath.Join(dir, "expected.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile(filepath.Join(dir, "actual.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config goldenCase
+	if data, err := os.ReadFile(filepath.Join(dir, "case.json")); err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			t.Fatalf("case.json: %v", err)
+		}
+	}
+
+	switch config.Kind {
+	case "", "json":
+		diff, err := CompareJSON(expected, actual, config.Noise, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diff
+	case "headers":
+		var expectedHeaders, actualHeaders map[string]string
+		if err := json.Unmarshal(expected, &expectedHeaders); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(actual, &actualHeaders); err != nil {
+			t.Fatal(err)
+		}
+		return CompareHeaders(expectedHeaders, actualHeaders, WithNoise(config.Noise))
+	}
+	t.Fatalf("unknown case kind %q", config.Kind)
+	return Diff{}
+}
+
+// goldenRenders renders the diff in every format covered by the corpus, keyed by the name of the golden file. The
+// readable render marks the highlights of the colorized sides as RenderPlain does, for reviewing changes, and the
+// colorized render keeps the exact colors with the escape character spelled as \e.
+func goldenRenders(t *testing.T, diff Diff) map[string]string {
+	t.Helper()
+	renders := map[string]string{
+		"readable": RenderPlain(diff),
+		"colorized": "--- expected\n" + strings.ReplaceAll(diff.Expected, "\x1b", `\e`) +
+			"\n--- actual\n" + strings.ReplaceAll(diff.Actual, "\x1b", `\e`) + "\n",
+	}
+	for _, format := range []Format{FormatPlain, FormatHTML, FormatPatch} {
+		out, err := diff.Render(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		renders[string(format)] = out
+	}
+	structured, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renders["json"] = string(structured) + "\n"
+	return renders
+}
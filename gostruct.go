@@ -0,0 +1,394 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// CompareJSONToStruct compares a JSON document, e.g. the response of a handler, against the typed expectation v and
+// returns the colorized differences, v being the expected side. v is marshalled with encoding/json, so its json tags
+// name the fields. A field left out by omitempty matches both an absent key and a key holding the empty value of its
+// JSON type: null, false, 0, "", [] or {}.
+// Fields can carry their comparison rules in a jsondiff tag holding comma-separated rules: `jsondiff:"noise"`
+// ignores the field and `jsondiff:"tolerance=0.01"` lets the numbers at and below it differ by up to 0.01, as
+// WithNumericTolerance does. The rules apply to the fields at the paths where v holds them; opts are applied after
+// them.
+func CompareJSONToStruct(jsonBytes []byte, v interface{}, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	expectedJSON, err := encodeJSON(v)
+	if err != nil {
+		return Diff{}, fmt.Errorf("marshalling expected value: %w", err)
+	}
+	tagOptions, err := structTagOptions(reflect.ValueOf(v))
+	if err != nil {
+		return Diff{}, err
+	}
+	opts = append(tagOptions, opts...)
+	if omitted := omittedFields(reflect.ValueOf(v), "", nil); len(omitted) > 0 && json.Valid(jsonBytes) {
+		jsonBytes = pruneEmptyMembers(jsonBytes, omitted)
+	}
+	return CompareJSON(expectedJSON, jsonBytes, noise, disableColor, opts...)
+}
+
+// decodeExact decodes a JSON document keeping its numbers as written. It reports false if the data is not a single
+// JSON value, leaving the error to CompareJSON.
+func decodeExact(data []byte) (interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return value, true
+}
+
+// structField is a field of a struct as encoding/json sees it.
+type structField struct {
+	name      string              // name is the key of the field in the JSON object.
+	index     []int               // index is the index sequence of the field, through embedded structs.
+	omitEmpty bool                // omitEmpty tells whether the field is left out when empty.
+	field     reflect.StructField // field is the field itself, for its tags.
+}
+
+// jsonFields returns the fields encoding/json marshals for a struct type. Fields of embedded structs without a name
+// tag are promoted; of the fields sharing a name, the least nested one wins, then the tagged one, and ties drop all.
+func jsonFields(t reflect.Type) []structField {
+	type candidate struct {
+		structField
+		depth  int
+		tagged bool
+	}
+	var candidates []candidate
+	var collect func(t reflect.Type, index []int, depth int)
+	collect = func(t reflect.Type, index []int, depth int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			fieldIndex := append(append([]int(nil), index...), i)
+			if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+				collect(fieldType, fieldIndex, depth+1)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			tagged := name != ""
+			if !tagged {
+				name = field.Name
+			}
+			omitEmpty := false
+			for _, option := range strings.Split(options, ",") {
+				omitEmpty = omitEmpty || option == "omitempty"
+			}
+			candidates = append(candidates, candidate{
+				structField: structField{name: name, index: fieldIndex, omitEmpty: omitEmpty, field: field},
+				depth:       depth,
+				tagged:      tagged,
+			})
+		}
+	}
+	collect(t, nil, 0)
+
+	byName := map[string][]candidate{}
+	var names []string
+	for _, c := range candidates {
+		if _, ok := byName[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+	var fields []structField
+	for _, name := range names {
+		var dominant []candidate
+		for _, c := range byName[name] {
+			switch {
+			case len(dominant) == 0 || c.depth < dominant[0].depth:
+				dominant = []candidate{c}
+			case c.depth == dominant[0].depth:
+				dominant = append(dominant, c)
+			}
+		}
+		if len(dominant) > 1 {
+			var tagged []candidate
+			for _, c := range dominant {
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+			dominant = tagged
+		}
+		if len(dominant) == 1 {
+			fields = append(fields, dominant[0].structField)
+		}
+	}
+	return fields
+}
+
+// fieldByIndex returns the field at the index sequence, or false if an embedded pointer on the way is nil.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, at := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(at)
+	}
+	return value, true
+}
+
+// walkJSONValue calls visit with the gjson-style path of every struct field reachable from the value the way
+// encoding/json marshals it, and descends into the fields for which visit returns true. Values marshalling
+// themselves are not descended into.
+func walkJSONValue(value reflect.Value, path string, visit func(path string, field structField, value reflect.Value) bool) {
+	if !value.IsValid() {
+		return
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() || marshalsItself(value) {
+			return
+		}
+		value = value.Elem()
+	}
+	if marshalsItself(value) {
+		return
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for _, field := range jsonFields(value.Type()) {
+			fieldValue, ok := fieldByIndex(value, field.index)
+			if !ok {
+				continue
+			}
+			fieldPath := joinPath(path, escapePathKey(field.name))
+			if visit(fieldPath, field, fieldValue) {
+				walkJSONValue(fieldValue, fieldPath, visit)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			walkJSONValue(value.Index(i), joinPath(path, strconv.Itoa(i)), visit)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			walkJSONValue(iter.Value(), joinPath(path, escapePathKey(mapKeyName(iter.Key()))), visit)
+		}
+	}
+}
+
+// marshalsItself reports whether the value implements json.Marshaler or encoding.TextMarshaler.
+func marshalsItself(value reflect.Value) bool {
+	t := value.Type()
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// mapKeyName returns the object key encoding/json uses for a map key.
+func mapKeyName(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// structTagOptions returns the options given by the jsondiff tags of the fields reachable from the value.
+func structTagOptions(value reflect.Value) ([]Option, error) {
+	var opts []Option
+	noise := map[string][]string{}
+	var err error
+	walkJSONValue(value, "", func(path string, field structField, _ reflect.Value) bool {
+		tag, ok := field.field.Tag.Lookup("jsondiff")
+		if !ok {
+			return true
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			name, argument, hasArgument := strings.Cut(strings.TrimSpace(rule), "=")
+			switch {
+			case name == "":
+			case name == "noise" && !hasArgument:
+				// A pattern anchored at the field, unlike a plain rule matching anywhere in a path, leaves alone the
+				// keys merely containing its name. The "body." prefix keeps a field named "body" or "header" a body
+				// path.
+				noise["body."+strings.ToLower(path)+".**"] = []string{}
+			case name == "tolerance" && hasArgument:
+				tolerance, parseErr := strconv.ParseFloat(argument, 64)
+				if parseErr != nil || tolerance < 0 {
+					err = fmt.Errorf("field %s: invalid jsondiff tolerance %q", field.field.Name, argument)
+					return false
+				}
+				opts = append(opts, WithNumericTolerance(tolerance, path, path+".**"))
+			default:
+				err = fmt.Errorf("field %s: unknown jsondiff rule %q", field.field.Name, rule)
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(noise) > 0 {
+		opts = append(opts, WithNoise(noise))
+	}
+	return opts, nil
+}
+
+// omittedFields returns the paths of the fields of the value that omitempty leaves out of its JSON encoding.
+func omittedFields(value reflect.Value, path string, omitted []string) []string {
+	walkJSONValue(value, path, func(path string, field structField, value reflect.Value) bool {
+		if field.omitEmpty && isEmptyValue(value) {
+			omitted = append(omitted, path)
+			return false
+		}
+		return true
+	})
+	return omitted
+}
+
+// isEmptyValue reports whether omitempty leaves the value out, following encoding/json.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
+
+// pruneEmptyMembers deletes the members at the paths from the raw document where they hold the empty value of their
+// JSON type, editing the bytes instead of re-encoding them so the rest of the document keeps its layout, e.g. the
+// order of its keys. Of duplicate keys encoding/json keeps the last, so all of them go if the last value is empty.
+func pruneEmptyMembers(data []byte, paths []string) []byte {
+	for _, path := range paths {
+		components := splitPath(path)
+		parentPath := ""
+		for _, component := range components[:len(components)-1] {
+			parentPath = joinPath(parentPath, escapePathKey(component))
+		}
+		key := components[len(components)-1]
+		for checked := false; ; checked = true {
+			parent, members := objectMembersAt(data, parentPath)
+			match := -1
+			for i, m := range members {
+				if m.key == key {
+					match = i
+				}
+			}
+			if match < 0 {
+				break
+			}
+			if !checked {
+				if value, ok := decodeExact([]byte(members[match].value)); !ok || !isEmptyJSON(value) {
+					break
+				}
+			}
+			data = deleteMember(data, parent, members, match)
+		}
+	}
+	return data
+}
+
+// rawMember locates a member of an object within a raw document.
+type rawMember struct {
+	key      string // key is the unescaped key of the member.
+	value    string // value is the raw value of the member.
+	keyStart int    // keyStart is the offset of the opening quote of the key.
+	valueEnd int    // valueEnd is the offset following the value.
+}
+
+// objectMembersAt returns the object at a gjson-style path of the raw document and its members, or no members if
+// there is no object whose position in the document is known.
+func objectMembersAt(data []byte, path string) (gjson.Result, []rawMember) {
+	object := gjson.ParseBytes(data)
+	if path != "" {
+		object = gjson.GetBytes(data, path)
+	}
+	end := object.Index + len(object.Raw)
+	if !object.IsObject() || end > len(data) || string(data[object.Index:end]) != object.Raw {
+		return object, nil
+	}
+	var members []rawMember
+	object.ForEach(func(key, value gjson.Result) bool {
+		members = append(members, rawMember{key: key.String(), value: value.Raw, keyStart: key.Index, valueEnd: value.Index + len(value.Raw)})
+		return true
+	})
+	return object, members
+}
+
+// deleteMember deletes a member of an object from the raw document along with the comma separating it from its
+// neighbors, leaving the white space around the other members as it is.
+func deleteMember(data []byte, object gjson.Result, members []rawMember, i int) []byte {
+	start, end := members[i].keyStart, members[i].valueEnd
+	switch {
+	case i > 0:
+		start = members[i-1].valueEnd
+	case len(members) > 1:
+		end = members[i+1].keyStart
+	default:
+		start, end = object.Index+1, object.Index+len(object.Raw)-1
+	}
+	return append(append([]byte(nil), data[:start]...), data[end:]...)
+}
+
+// isEmptyJSON reports whether a decoded value is the empty value of its JSON type.
+func isEmptyJSON(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type testAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type testUser struct {
+	testAudit
+	ID       int           `json:"id"`
+	Name     string        `json:"name"`
+	Nickname string        `json:"nickname,omitempty"`
+	Tags     []string      `json:"tags,omitempty"`
+	Address  *testAddress  `json:"address,omitempty"`
+	Previous []testAddress `json:"previous"`
+	Secret   string        `json:"-"`
+	internal string
+}
+
+func TestCompareJSONToStruct(t *testing.T) {
+	expected := testUser{
+		testAudit: testAudit{CreatedBy: "admin"},
+		ID:        1,
+		Name:      "Ada",
+		Address:   &testAddress{City: "London"},
+		Previous:  []testAddress{{City: "Paris"}},
+		Secret:    "hidden",
+		internal:  "hidden",
+	}
+
+	// Empty values of omitted fields match, at any depth.
+	actual := `{"createdBy":"admin","id":1,"name":"Ada","nickname":"","tags":[],"address":{"city":"London","zip":""},"previous":[{"city":"Paris","zip":null}]}`
+	resp, err := CompareJSONToStruct([]byte(actual), expected, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 0 {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	// Other values of omitted fields and fields hidden from encoding/json are differences.
+	actual = `{"createdBy":"admin","id":2,"name":"Ada","nickname":"Countess","address":{"city":"London"},"previous":[{"city":"Paris"}],"Secret":"hidden"}`
+	resp, err = CompareJSONToStruct([]byte(actual), expected, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "Secret", Op: OpAdded, Actual: "hidden"},
+		{Path: "id", Op: OpChanged, Expected: float64(1), Actual: float64(2)},
+		{Path: "nickname", Op: OpAdded, Actual: "Countess"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	if _, err := CompareJSONToStruct([]byte(actual), map[string]interface{}{"f": func() {}}, nil, true); err == nil {
+		t.Error("expected an error for a value encoding/json cannot marshal")
+	}
+	if _, err := CompareJSONToStruct([]byte(`{"id":`), expected, nil, true); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	type inner struct {
+		Name  string `json:"name"`
+		Value int
+	}
+	type outer struct {
+		inner
+		Value string `json:"value,omitempty"`
+		Other int    `json:"other,string"`
+	}
+	var got []string
+	for _, field := range jsonFields(reflect.TypeOf(outer{})) {
+		got = append(got, field.name)
+		if field.name == "value" && !field.omitEmpty {
+			t.Error("expected value to be omitempty")
+		}
+	}
+	want := []string{"name", "Value", "value", "other"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields %v, want %v", got, want)
+	}
+}
+
+func TestCompareJSONToStructTags(t *testing.T) {
+	type line struct {
+		SKU   string  `json:"sku"`
+		Price float64 `json:"price" jsondiff:"tolerance=0.01"`
+	}
+	type order struct {
+		ID        string `json:"id" jsondiff:"noise"`
+		Lines     []line `json:"lines"`
+		Total     float64
+		UpdatedAt string `json:"updatedAt,omitempty" jsondiff:"noise"`
+	}
+	expected := order{ID: "a1", Lines: []line{{SKU: "x", Price: 9.99}, {SKU: "y", Price: 5}}, Total: 14.99}
+
+	actual := `{"id":"b2","lines":[{"sku":"x","price":9.995},{"sku":"y","price":5.1}],"Total":14.99,"updatedAt":"2024-01-02"}`
+	resp, err := CompareJSONToStruct([]byte(actual), expected, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "id", Op: OpChanged, Expected: "a1", Actual: "b2", Noised: true},
+		{Path: "lines.1.price", Op: OpChanged, Expected: float64(5), Actual: 5.1},
+		{Path: "updatedAt", Op: OpAdded, Actual: "2024-01-02", Noised: true},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	// The noise rule covers the tagged field only, not the keys containing its name.
+	type payment struct {
+		ID      string `json:"id" jsondiff:"noise"`
+		Paid    bool   `json:"paid"`
+		VideoID string `json:"video_id"`
+	}
+	resp, err = CompareJSONToStruct([]byte(`{"id":"b","paid":false,"video_id":"w"}`), payment{ID: "a", Paid: true, VideoID: "v"}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []DiffEntry{
+		{Path: "id", Op: OpChanged, Expected: "a", Actual: "b", Noised: true},
+		{Path: "paid", Op: OpChanged, Expected: true, Actual: false},
+		{Path: "video_id", Op: OpChanged, Expected: "v", Actual: "w"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	type invalid struct {
+		Price float64 `json:"price" jsondiff:"tolerance=abc"`
+	}
+	if _, err := CompareJSONToStruct([]byte(`{"price":1}`), invalid{}, nil, true); err == nil {
+		t.Error("expected an error for an invalid tolerance")
+	}
+	type unknown struct {
+		Price float64 `json:"price" jsondiff:"fuzzy"`
+	}
+	if _, err := CompareJSONToStruct([]byte(`{"price":1}`), unknown{}, nil, true); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+func TestPruneEmptyMembers(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		paths []string
+		want  string
+	}{
+		{name: "middle member", data: "{\n  \"b\": 1,\n  \"a\": \"\",\n  \"c\": 2\n}", paths: []string{"a"}, want: "{\n  \"b\": 1,\n  \"c\": 2\n}"},
+		{name: "first member", data: ` {"a": [], "b": 1}`, paths: []string{"a"}, want: ` {"b": 1}`},
+		{name: "only member", data: `{"x": {"a": null}}`, paths: []string{"x.a"}, want: `{"x": {}}`},
+		{name: "in an array", data: `{"l": [{"a": 0, "b": 1}, {"a": 1}]}`, paths: []string{"l.0.a", "l.1.a"}, want: `{"l": [{"b": 1}, {"a": 1}]}`},
+		{name: "escaped key", data: `{"a.b": false, "c": 1}`, paths: []string{`a\.b`}, want: `{"c": 1}`},
+		{name: "last duplicate empty", data: `{"a": 1, "a": "", "b": 2}`, paths: []string{"a"}, want: `{"b": 2}`},
+		{name: "last duplicate set", data: `{"a": "", "a": 1}`, paths: []string{"a"}, want: `{"a": "", "a": 1}`},
+		{name: "not empty", data: `{"a": "x"}`, paths: []string{"a", "missing.b"}, want: `{"a": "x"}`},
+	}
+	for _, tt := range tests {
+		if got := string(pruneEmptyMembers([]byte(tt.data), tt.paths)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
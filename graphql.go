@@ -0,0 +1,75 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphQLTracingNoise is the noise path of the tracing data servers attach to GraphQL responses.
+const graphQLTracingNoise = "extensions.tracing"
+
+// WithGraphQL makes the comparison understand GraphQL response envelopes. The "data" member is diffed
+// structurally, the "errors" member is compared as a set keyed by each error's path and message, so reordered
+// errors do not show up as differences, and "extensions.tracing" is treated as noise.
+func WithGraphQL() Option {
+	return func(o *options) {
+		o.graphQL = true
+		o.defaultNoise = append(o.defaultNoise, graphQLTracingNoise)
+	}
+}
+
+// keyGraphQLErrors replaces the "errors" arrays of two GraphQL response envelopes with objects keyed by
+// graphQLErrorKey, so errors sharing a key are told apart by a "#n" suffix in their order of appearance.
+func keyGraphQLErrors(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	if path != "" {
+		return nil, nil, "", false
+	}
+	e, ok := expected.(map[string]interface{})
+	if !ok {
+		return nil, nil, "", false
+	}
+	a, ok := actual.(map[string]interface{})
+	if !ok {
+		return nil, nil, "", false
+	}
+	expectedErrors, expectedIsArray := e["errors"].([]interface{})
+	actualErrors, actualIsArray := a["errors"].([]interface{})
+	if !expectedIsArray && !actualIsArray {
+		return nil, nil, "", false
+	}
+	if expectedIsArray {
+		e = withMember(e, "errors", keyElements(expectedErrors, graphQLErrorKey))
+	}
+	if actualIsArray {
+		a = withMember(a, "errors", keyElements(actualErrors, graphQLErrorKey))
+	}
+	return e, a, "", true
+}
+
+// graphQLErrorKey identifies a GraphQL error by its path and message, e.g. "user.friends.0: Not authorized".
+func graphQLErrorKey(graphQLError interface{}) string {
+	fields, ok := graphQLError.(map[string]interface{})
+	if !ok {
+		return fmt.Sprint(graphQLError)
+	}
+	message, _ := fields["message"].(string)
+	segments, _ := fields["path"].([]interface{})
+	if len(segments) == 0 {
+		return message
+	}
+	path := make([]string, len(segments))
+	for i, segment := range segments {
+		path[i] = fmt.Sprint(segment)
+	}
+	return strings.Join(path, ".") + ": " + message
+}
+
+// withMember returns a shallow copy of the object with the member set to the value.
+func withMember(object map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
@@ -0,0 +1,55 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphQL(t *testing.T) {
+	expected := `{
+		"data": {"user": {"name": "Ann", "friends": [null, null]}},
+		"errors": [
+			{"message": "Not authorized", "path": ["user", "friends", 0]},
+			{"message": "Not authorized", "path": ["user", "friends", 1]},
+			{"message": "Rate limited"}
+		],
+		"extensions": {"tracing": {"duration": 1200}}
+	}`
+	actual := `{
+		"data": {"user": {"name": "Bob", "friends": [null, null]}},
+		"errors": [
+			{"message": "Not authorized", "path": ["user", "friends", 1]},
+			{"message": "Not authorized", "path": ["user", "friends", 0]},
+			{"message": "Timeout"}
+		],
+		"extensions": {"tracing": {"duration": 3400}}
+	}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithGraphQL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "data.user.name", Op: OpChanged, Expected: "Ann", Actual: "Bob"},
+		{Path: "errors.Rate limited", Op: OpRemoved, Expected: map[string]interface{}{"message": "Rate limited"}},
+		{Path: "errors.Timeout", Op: OpAdded, Actual: map[string]interface{}{"message": "Timeout"}},
+		{Path: "extensions.tracing.duration", Op: OpChanged, Expected: float64(1200), Actual: float64(3400), Noised: true},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+}
+
+func TestKeyElements(t *testing.T) {
+	errors := []interface{}{
+		map[string]interface{}{"message": "Bad input", "path": []interface{}{"a", float64(2)}},
+		map[string]interface{}{"message": "Bad input", "path": []interface{}{"a", float64(2)}},
+		map[string]interface{}{"message": "Down"},
+	}
+	got := keyElements(errors, graphQLErrorKey)
+	for _, key := range []string{"a.2: Bad input", "a.2: Bad input#2", "Down"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing key %q in %v", key, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package grpc compares gRPC metadata and statuses. It lives in a package of its own so programs comparing plain
+// JSON do not depend on gRPC.
+package grpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	jsondiff "github.com/keploy/jsonDiff"
+	"github.com/keploy/jsonDiff/proto"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// binarySuffix marks metadata keys whose values are binary.
+const binarySuffix = "-bin"
+
+// CompareMetadata compares two sets of gRPC metadata, e.g. headers or trailers, and returns the colorized
+// differences. Keys are compared case-insensitively and values of binary ("-bin") keys are compared base64-encoded.
+func CompareMetadata(expected, actual metadata.MD, noise map[string][]string, disableColor bool, opts ...jsondiff.Option) (jsondiff.Diff, error) {
+	return jsondiff.CompareValues(metadataToValue(expected), metadataToValue(actual), noise, disableColor, opts...)
+}
+
+// CompareStatus compares two gRPC statuses and returns the colorized differences. The code is compared by
+// name, and the details are decoded into their messages when their types are linked into the binary and compared
+// through their JSON mapping. A nil status is an OK status.
+func CompareStatus(expected, actual *status.Status, noise map[string][]string, disableColor bool, opts ...jsondiff.Option) (jsondiff.Diff, error) {
+	expectedValue, err := statusToValue(expected)
+	if err != nil {
+		return jsondiff.Diff{}, fmt.Errorf("decoding expected status details: %w", err)
+	}
+	actualValue, err := statusToValue(actual)
+	if err != nil {
+		return jsondiff.Diff{}, fmt.Errorf("decoding actual status details: %w", err)
+	}
+	return jsondiff.CompareValues(expectedValue, actualValue, noise, disableColor, opts...)
+}
+
+// metadataToValue converts metadata into an object mapping the lowercased keys to their values. The values of keys
+// differing only in case are merged in the sorted order of the keys, so the result does not depend on map order.
+func metadataToValue(md metadata.MD) map[string]interface{} {
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	value := make(map[string]interface{}, len(md))
+	for _, key := range keys {
+		values := md[key]
+		key = strings.ToLower(key)
+		converted, _ := value[key].([]interface{})
+		for _, v := range values {
+			if strings.HasSuffix(key, binarySuffix) || !utf8.ValidString(v) {
+				v = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			converted = append(converted, v)
+		}
+		if converted == nil {
+			converted = []interface{}{}
+		}
+		value[key] = converted
+	}
+	return value
+}
+
+// statusToValue converts a status into an object with "code", "message" and "details" fields. Details whose type
+// is unknown are kept as their type URL and base64-encoded content.
+func statusToValue(s *status.Status) (map[string]interface{}, error) {
+	details := []interface{}{}
+	for _, detail := range s.Proto().GetDetails() {
+		message, err := detail.UnmarshalNew()
+		if err != nil {
+			details = append(details, map[string]interface{}{
+				"@type": detail.GetTypeUrl(),
+				"value": base64.StdEncoding.EncodeToString(detail.GetValue()),
+			})
+			continue
+		}
+		value, err := proto.Options{}.Value(message)
+		if err != nil {
+			return nil, err
+		}
+		if object, ok := value.(map[string]interface{}); ok {
+			object["@type"] = detail.GetTypeUrl()
+		}
+		details = append(details, value)
+	}
+	return map[string]interface{}{
+		"code":    s.Code().String(),
+		"message": s.Message(),
+		"details": details,
+	}, nil
+}
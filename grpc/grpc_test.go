@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"reflect"
+	"testing"
+
+	jsondiff "github.com/keploy/jsonDiff"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestCompareMetadata(t *testing.T) {
+	expected := metadata.Pairs("x-request-id", "a1", "trace-bin", "\x01\x02", "grpc-status", "0")
+	actual := metadata.Pairs("x-request-id", "b2", "trace-bin", "\x01\x02", "grpc-status", "0", "retry", "1")
+
+	resp, err := CompareMetadata(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []jsondiff.DiffEntry{
+		{Path: "retry", Op: jsondiff.OpAdded, Actual: []interface{}{"1"}},
+		{Path: "x-request-id.0", Op: jsondiff.OpChanged, Expected: "a1", Actual: "b2"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+}
+
+func TestMetadataToValueMergesKeysInOrder(t *testing.T) {
+	md := metadata.MD{"x-id": {"c"}, "X-Id": {"a"}, "X-ID": {"b"}}
+	want := map[string]interface{}{"x-id": []interface{}{"b", "a", "c"}}
+	for i := 0; i < 20; i++ {
+		if got := metadataToValue(md); !reflect.DeepEqual(got, want) {
+			t.Fatalf("metadataToValue() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCompareStatus(t *testing.T) {
+	expected, err := status.New(codes.InvalidArgument, "bad request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := status.New(codes.InvalidArgument, "bad request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "required"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := CompareStatus(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []jsondiff.DiffEntry{{Path: "details.0.field_violations.0.field", Op: jsondiff.OpChanged, Expected: "name", Actual: "email"}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+
+	unknown := status.New(codes.Internal, "boom").Proto()
+	unknown.Details = []*anypb.Any{{TypeUrl: "type.example.com/Unknown", Value: []byte{0x08, 0x01}}}
+	resp, err = CompareStatus(nil, status.FromProto(unknown), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []jsondiff.DiffEntry{
+		{Path: "code", Op: jsondiff.OpChanged, Expected: "OK", Actual: "Internal"},
+		{Path: "details.0", Op: jsondiff.OpAdded, Actual: map[string]interface{}{"@type": "type.example.com/Unknown", "value": "CAE="}},
+		{Path: "message", Op: jsondiff.OpChanged, Expected: "", Actual: "boom"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+}
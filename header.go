@@ -0,0 +1,108 @@
+package colorisediff
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// WithMetadataHeader adds a header block above the colorized differences, so saved logs and shared screenshots
+// describe themselves: the time of the comparison, the identifier of the input shown on each side, e.g. a file name
+// or a test case id, the noise rules applied and the options that change how values are compared. Identifiers left
+// empty are omitted.
+func WithMetadataHeader(expectedID, actualID string) Option {
+	return func(o *options) {
+		o.metadataHeader = true
+		o.inputIDs = [2]string{expectedID, actualID}
+	}
+}
+
+// WithLegend adds a legend explaining the colors of the output above the colorized differences.
+func WithLegend() Option {
+	return func(o *options) {
+		o.legend = true
+	}
+}
+
+// renderHeaders renders the metadata header and the legend of both sides, or returns empty strings if neither was
+// asked for.
+func (o *options) renderHeaders(noise map[string][]string) (string, string) {
+	if !o.metadataHeader && !o.legend {
+		return "", ""
+	}
+	dim := o.palette.sprintFunc(color.Faint)
+	line := func(text string) string {
+		return breakWithColor(text, dim, []colorRange{{Start: 0, End: len(text)}})
+	}
+
+	var expected, actual string
+	if o.metadataHeader {
+		compared := line(" compared: " + time.Now().UTC().Format(time.RFC3339))
+		expected, actual = compared, compared
+		if o.inputIDs[0] != "" {
+			expected += line(" input: " + o.inputIDs[0])
+		}
+		if o.inputIDs[1] != "" {
+			actual += line(" input: " + o.inputIDs[1])
+		}
+
+		rules := make([]string, 0, len(noise))
+		for rule := range noise {
+			rules = append(rules, rule)
+		}
+		sort.Strings(rules)
+		shared := line(" noise: " + joinOrDefault(rules, "none"))
+		shared += line(" options: " + joinOrDefault(o.summary(), "defaults"))
+		expected += shared
+		actual += shared
+	}
+	if o.legend {
+		red, green := o.palette.sprintFunc(color.FgRed), o.palette.sprintFunc(color.FgGreen)
+		dimmed := breakLines("         "+dim("dim")+" = noise or context") + "\n"
+		expected += breakLines(" legend: "+red("red")+" = expected only or changed") + "\n" + dimmed
+		actual += breakLines(" legend: "+green("green")+" = actual only or changed") + "\n" + dimmed
+	}
+	return expected, actual
+}
+
+// summary names the enabled options that change how values are compared.
+func (o *options) summary() []string {
+	var enabled []string
+	add := func(on bool, name string) {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	add(o.nestedJSONStrings, "nested JSON strings")
+	add(o.base64Decoding, "base64 decoding")
+	add(o.jwtDecoding, "JWT decoding")
+	add(o.urlNormalization, "URL normalization")
+	add(o.localeNumbers, "locale numbers")
+	add(len(o.stringNormalizers) > 0, "string normalizers")
+	add(o.decompress, "decompression")
+	add(o.mongoExtendedJSON, "MongoDB Extended JSON")
+	add(o.dynamoDB, "DynamoDB")
+	add(o.openTelemetry, "OpenTelemetry")
+	add(o.graphQL, "GraphQL")
+	add(o.elasticsearch, "Elasticsearch")
+	add(o.keployTemplates, "keploy templates")
+	add(len(o.unorderedArrays) > 0, "unordered arrays ("+strings.Join(o.unorderedArrays, ", ")+")")
+	add(o.ignoreExtraElements, "ignore extra elements")
+	add(o.ignoreExtraKeys, "ignore extra keys")
+	add(o.shapeOnly, "shape only")
+	add(o.jsonSchema != nil, "JSON schema")
+	add(len(o.assertions) > 0, "assertions")
+	add(len(o.consistencyChecks) > 0, "consistency checks")
+	add(len(o.valueRenderers.redact) > 0, "redaction")
+	return enabled
+}
+
+// joinOrDefault joins the items with ", ", or returns the fallback if there are none.
+func joinOrDefault(items []string, fallback string) string {
+	if len(items) == 0 {
+		return fallback
+	}
+	return strings.Join(items, ", ")
+}
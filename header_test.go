@@ -0,0 +1,54 @@
+package colorisediff
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithMetadataHeader(t *testing.T) {
+	json1 := `{"name":"Cat","ts":1,"tags":["a","b"]}`
+	json2 := `{"name":"Dog","ts":2,"tags":["b","a"]}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), map[string][]string{"ts": {}}, true,
+		WithMetadataHeader("recorded.json", ""), WithUnorderedArrays("tags"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := regexp.MustCompile(`^ compared: \d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ
+ input: recorded.json
+ noise: ts
+ options: unordered arrays \(tags\)
+\{
+`)
+	if !expected.MatchString(resp.Expected) {
+		t.Errorf("unexpected expected header:\n%s", resp.Expected)
+	}
+	if strings.Contains(resp.Actual, "input:") || !strings.Contains(resp.Actual, " noise: ts\n") {
+		t.Errorf("unexpected actual header:\n%s", resp.Actual)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true, WithMetadataHeader("", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Expected, " noise: none\n options: defaults\n{") {
+		t.Errorf("expected the defaults to be named, got:\n%s", resp.Expected)
+	}
+}
+
+func TestWithLegend(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"a":1}`), []byte(`{"a":2}`), nil, false, WithLegend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.Expected, " legend: \x1b[31mred\x1b[0m = expected only or changed\n         \x1b[2mdim\x1b[22m = noise or context\n{") {
+		t.Errorf("unexpected expected legend %q", resp.Expected)
+	}
+	if got := NormalizeForTest(resp.Actual); !strings.HasPrefix(got, " legend: green = actual only or changed\n         dim = noise or context\n{") {
+		t.Errorf("unexpected actual legend:\n%s", got)
+	}
+	if strings.Contains(resp.Expected, "compared:") {
+		t.Errorf("expected no metadata without WithMetadataHeader, got:\n%s", resp.Expected)
+	}
+}
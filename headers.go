@@ -0,0 +1,218 @@
+package colorisediff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// headerAllowlistReason is the noise reason of the headers left out by CompareHeadersSubset.
+const headerAllowlistReason = "not in the header allowlist"
+
+// CompareHeadersSubset compares only the headers named in the allowlist, e.g. []string{"Content-Type",
+// "Cache-Control"}, for contract tests that don't own the headers added by proxies and servers. Names are matched
+// case-insensitively. Differences in all other headers are reported as noised entries with the reason "not in the
+// header allowlist" and left out of the colorized output, like headers ignored with WithNoise.
+func CompareHeadersSubset(expectedHeaders, actualHeaders map[string]string, allowlist []string, opts ...Option) Diff {
+	allowed := make(map[string]bool, len(allowlist))
+	for _, name := range allowlist {
+		allowed[strings.ToLower(name)] = true
+	}
+	noise := map[string][]string{}
+	ignored := map[string]bool{}
+	for _, name := range headerNames(expectedHeaders, actualHeaders) {
+		if !allowed[strings.ToLower(name)] {
+			noise["header."+name] = []string{}
+			ignored[escapePathKey(name)] = true
+		}
+	}
+
+	diff := CompareHeaders(expectedHeaders, actualHeaders, append(opts[:len(opts):len(opts)], WithNoise(noise))...)
+	for i, entry := range diff.Entries {
+		if ignored[entry.Path] {
+			diff.Entries[i].NoiseReason = headerAllowlistReason
+		}
+	}
+	return diff
+}
+
+// HeaderParser parses header values into structures that are compared instead of the raw strings, so that
+// equivalent spellings of a header, e.g. reordered Link entries, don't show up as differences.
+type HeaderParser interface {
+	// ParseHeader returns the structured form of a header value, built from the types produced by decoding JSON
+	// (maps, slices, strings, float64 and bool), or an error to compare the raw strings instead. A panic is treated
+	// like an error.
+	ParseHeader(value string) (interface{}, error)
+}
+
+// HeaderParserFunc adapts a function to the HeaderParser interface.
+type HeaderParserFunc func(value string) (interface{}, error)
+
+// ParseHeader calls f(value).
+func (f HeaderParserFunc) ParseHeader(value string) (interface{}, error) {
+	return f(value)
+}
+
+// WithHeaderParser makes CompareHeaders compare the values of the named header, matched case-insensitively, through
+// the parser. Differences are reported below the header name, e.g. "Link.next" for a changed next page. A parser
+// given later for the same name replaces the earlier one.
+func WithHeaderParser(name string, parser HeaderParser) Option {
+	return func(o *options) {
+		if o.headerParsers == nil {
+			o.headerParsers = map[string]HeaderParser{}
+		}
+		o.headerParsers[strings.ToLower(name)] = parser
+	}
+}
+
+// WithStandardHeaderParsers registers the built-in parsers for the headers they are named after: LinkHeaderParser
+// for Link, AcceptHeaderParser for Accept and CacheControlParser for Cache-Control.
+func WithStandardHeaderParsers() Option {
+	return func(o *options) {
+		WithHeaderParser("Link", LinkHeaderParser())(o)
+		WithHeaderParser("Accept", AcceptHeaderParser())(o)
+		WithHeaderParser("Cache-Control", CacheControlParser())(o)
+	}
+}
+
+// LinkHeaderParser returns a parser for RFC 8288 Link headers, e.g. `<https://api/items?page=2>; rel="next"`, that
+// maps every relation type to its URL. Links with several relation types are listed under each of them; the first
+// link of a relation type wins.
+func LinkHeaderParser() HeaderParser {
+	return HeaderParserFunc(func(value string) (interface{}, error) {
+		links := map[string]interface{}{}
+		for _, link := range splitHeaderList(value) {
+			target, params, _ := strings.Cut(link, ";")
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				return nil, fmt.Errorf("link %q: expected a URL in angle brackets", link)
+			}
+			target = target[1 : len(target)-1]
+			for _, rel := range strings.Fields(headerParams(params)["rel"]) {
+				rel = strings.ToLower(rel)
+				if _, exists := links[rel]; !exists {
+					links[rel] = target
+				}
+			}
+		}
+		return links, nil
+	})
+}
+
+// AcceptHeaderParser returns a parser for Accept headers, e.g. "text/html;q=0.8, application/json", that lists the
+// media ranges in order of preference: by descending quality value, then in the order given. Every range is an
+// object holding the lower-cased "range", with its parameters other than q, and the "q" value.
+func AcceptHeaderParser() HeaderParser {
+	return HeaderParserFunc(func(value string) (interface{}, error) {
+		type mediaRange struct {
+			name string
+			q    float64
+		}
+		var ranges []mediaRange
+		for _, item := range splitHeaderList(value) {
+			name, params, _ := strings.Cut(item, ";")
+			r := mediaRange{name: strings.ToLower(strings.TrimSpace(name)), q: 1}
+			for _, param := range strings.Split(params, ";") {
+				key, paramValue, _ := strings.Cut(strings.TrimSpace(param), "=")
+				key = strings.ToLower(strings.TrimSpace(key))
+				switch key {
+				case "":
+				case "q":
+					q, err := strconv.ParseFloat(strings.TrimSpace(paramValue), 64)
+					if err != nil {
+						return nil, fmt.Errorf("media range %q: invalid quality value", item)
+					}
+					r.q = q
+				default:
+					r.name += ";" + key + "=" + strings.Trim(strings.TrimSpace(paramValue), `"`)
+				}
+			}
+			ranges = append(ranges, r)
+		}
+		sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+		parsed := make([]interface{}, len(ranges))
+		for i, r := range ranges {
+			parsed[i] = map[string]interface{}{"range": r.name, "q": r.q}
+		}
+		return parsed, nil
+	})
+}
+
+// CacheControlParser returns a parser for Cache-Control headers, e.g. "max-age=60, no-cache", that maps the
+// lower-cased directives to their unquoted values, "" for directives without a value.
+func CacheControlParser() HeaderParser {
+	return HeaderParserFunc(func(value string) (interface{}, error) {
+		directives := map[string]interface{}{}
+		for _, directive := range splitHeaderList(value) {
+			name, directiveValue, _ := strings.Cut(directive, "=")
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(directiveValue), `"`)
+		}
+		return directives, nil
+	})
+}
+
+// splitHeaderList splits a comma-separated header value into its trimmed, non-empty items, keeping commas inside
+// quoted strings and angle brackets.
+func splitHeaderList(value string) []string {
+	var items []string
+	quoted, bracketed, start := false, false, 0
+	add := func(item string) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	for i, r := range value {
+		switch {
+		case r == '"' && !bracketed:
+			quoted = !quoted
+		case r == '<' && !quoted:
+			bracketed = true
+		case r == '>' && !quoted:
+			bracketed = false
+		case r == ',' && !quoted && !bracketed:
+			add(value[start:i])
+			start = i + 1
+		}
+	}
+	add(value[start:])
+	return items
+}
+
+// headerParams parses the ";"-separated parameters of a header list item into a map from the lower-cased names to
+// the unquoted values.
+func headerParams(params string) map[string]string {
+	parsed := map[string]string{}
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			parsed[name] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return parsed
+}
+
+// parseHeaderPair parses the expected and actual values of a header with the parser registered for its name. It
+// reports false if there is no parser or either value fails to parse.
+func (o *options) parseHeaderPair(name, expected, actual string) (interface{}, interface{}, bool) {
+	parser, ok := o.headerParsers[strings.ToLower(name)]
+	if !ok {
+		return nil, nil, false
+	}
+	e, err := parseHeaderRecovered(parser, expected)
+	if err != nil {
+		return nil, nil, false
+	}
+	a, err := parseHeaderRecovered(parser, actual)
+	if err != nil {
+		return nil, nil, false
+	}
+	return e, a, true
+}
+
+// parseHeaderRecovered parses a header value, returning a panic of the parser as a *PanicError so header comparisons,
+// which return no errors, fall back to comparing the raw strings instead of crashing the caller.
+func parseHeaderRecovered(parser HeaderParser, value string) (parsed interface{}, err error) {
+	defer recoverPanic(&err)
+	return parser.ParseHeader(value)
+}
@@ -0,0 +1,113 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareHeadersSubset(t *testing.T) {
+	expected := map[string]string{"Content-Type": "application/json", "Cache-Control": "no-cache", "Date": "Mon", "Via": "1.1 a"}
+	actual := map[string]string{"Content-Type": "text/html", "Cache-Control": "no-cache", "Date": "Tue", "Server": "x"}
+
+	diff := CompareHeadersSubset(expected, actual, []string{"content-type", "Cache-Control"})
+	want := []DiffEntry{
+		{Path: "Content-Type", Op: OpChanged, Expected: "application/json", Actual: "text/html"},
+		{Path: "Date", Op: OpChanged, Expected: "Mon", Actual: "Tue", Noised: true, NoiseReason: headerAllowlistReason},
+		{Path: "Server", Op: OpAdded, Actual: "x", Noised: true, NoiseReason: headerAllowlistReason},
+		{Path: "Via", Op: OpRemoved, Expected: "1.1 a", Noised: true, NoiseReason: headerAllowlistReason},
+	}
+	if !reflect.DeepEqual(diff.Entries, want) {
+		t.Errorf("unexpected entries %+v", diff.Entries)
+	}
+	if stats := diff.Stats(); stats.Total != 1 || stats.Noised != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if !strings.Contains(diff.Expected, "Content-Type") || strings.Contains(diff.Expected, "Date") {
+		t.Errorf("expected only the allowed headers to be shown, got:\n%s", diff.Expected)
+	}
+
+	diff = CompareHeadersSubset(expected, actual, []string{"Cache-Control"}, WithNoise(map[string][]string{"header.Cache-Control": {}}))
+	if stats := diff.Stats(); stats.Total != 0 {
+		t.Errorf("expected the noise to apply to the allowed headers too, got %+v", diff.Entries)
+	}
+}
+
+func TestWithHeaderParser(t *testing.T) {
+	expected := map[string]string{
+		"Link":          `<https://api/items?page=2>; rel="next", <https://api/items?page=9>; rel="last"`,
+		"Cache-Control": "no-cache, max-age=60",
+		"Etag":          "a",
+	}
+	actual := map[string]string{
+		"Link":          `<https://api/items?page=9>; rel=last, <https://api/items?page=3>; rel="next"`,
+		"Cache-Control": `max-age="60", No-Cache`,
+		"Etag":          "b",
+	}
+
+	diff := CompareHeaders(expected, actual, WithStandardHeaderParsers())
+	want := []DiffEntry{
+		{Path: "Etag", Op: OpChanged, Expected: "a", Actual: "b"},
+		{Path: "Link.next", Op: OpChanged, Expected: "https://api/items?page=2", Actual: "https://api/items?page=3"},
+	}
+	if !reflect.DeepEqual(diff.Entries, want) {
+		t.Errorf("unexpected entries %+v", diff.Entries)
+	}
+
+	upper := HeaderParserFunc(func(value string) (interface{}, error) { return strings.ToUpper(value), nil })
+	diff = CompareHeaders(map[string]string{"X-Mode": "fast"}, map[string]string{"X-Mode": "FAST"}, WithHeaderParser("x-mode", upper))
+	if len(diff.Entries) != 0 {
+		t.Errorf("expected the custom parser to be used, got %+v", diff.Entries)
+	}
+
+	diff = CompareHeaders(map[string]string{"Link": "broken"}, map[string]string{"Link": "<a>; rel=next"}, WithStandardHeaderParsers())
+	want = []DiffEntry{{Path: "Link", Op: OpChanged, Expected: "broken", Actual: "<a>; rel=next"}}
+	if !reflect.DeepEqual(diff.Entries, want) {
+		t.Errorf("expected unparsable values to be compared as strings, got %+v", diff.Entries)
+	}
+}
+
+func TestAcceptHeaderParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  interface{}
+	}{
+		{
+			name:  "ordered by quality",
+			value: `text/html;q=0.8, Application/JSON, text/plain; charset="utf-8";q=0.8`,
+			want: []interface{}{
+				map[string]interface{}{"range": "application/json", "q": 1.0},
+				map[string]interface{}{"range": "text/html", "q": 0.8},
+				map[string]interface{}{"range": "text/plain;charset=utf-8", "q": 0.8},
+			},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AcceptHeaderParser().ParseHeader(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHeader(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+	if _, err := AcceptHeaderParser().ParseHeader("text/html;q=high"); err == nil {
+		t.Error("expected an invalid quality value to fail")
+	}
+}
+
+func TestSplitHeaderList(t *testing.T) {
+	got := splitHeaderList(`<https://a/?x=1,2>; rel="next", , a="b,c", d`)
+	want := []string{`<https://a/?x=1,2>; rel="next"`, `a="b,c"`, "d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitHeaderList() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+package colorisediff
+
+// WithOnDiff registers a callback invoked with every difference as soon as it is found, before the differences are
+// rendered. The entries passed to it carry the same noise flag, note, violation and severity as those of the returned
+// Diff, so large comparisons can be streamed to logs or metrics while they run. Calls happen in path order on the
+// comparing goroutine.
+func WithOnDiff(fn func(DiffEntry)) Option {
+	return func(o *options) {
+		o.onDiff = fn
+	}
+}
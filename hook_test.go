@@ -0,0 +1,75 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithOnDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		noise    map[string][]string
+		want     []string
+	}{
+		{
+			name:     "objects",
+			expected: `{"a":1,"b":{"c":2,"d":3},"e":4}`,
+			actual:   `{"a":1,"b":{"c":5,"d":3},"f":4}`,
+			want:     []string{"b.c", "e", "f"},
+		},
+		{
+			name:     "type mismatch",
+			expected: `{"a":1}`,
+			actual:   `[1]`,
+			want:     []string{""},
+		},
+		{
+			name:     "noised entries are reported",
+			expected: `{"id":1,"name":"Cat"}`,
+			actual:   `{"id":2,"name":"Cat"}`,
+			noise:    map[string][]string{"id": {}},
+			want:     []string{"id"},
+		},
+		{
+			name:     "equal documents",
+			expected: `{"a":[1,2]}`,
+			actual:   `{"a":[1,2]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []DiffEntry
+			resp, err := CompareJSON([]byte(tt.expected), []byte(tt.actual), tt.noise, true, WithOnDiff(func(entry DiffEntry) {
+				seen = append(seen, entry)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, entry := range seen {
+				paths = append(paths, entry.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("OnDiff saw paths %q, want %q", paths, tt.want)
+			}
+			if !reflect.DeepEqual(seen, resp.Entries) {
+				t.Errorf("OnDiff saw %+v, Diff has %+v", seen, resp.Entries)
+			}
+		})
+	}
+}
+
+func TestWithOnDiffAnnotations(t *testing.T) {
+	var seen []DiffEntry
+	_, err := CompareJSON([]byte(`{"status":"ok"}`), []byte(`{"status":"down"}`), nil, true,
+		WithSeverityRules(SeverityRule{Pattern: "status", Label: "critical"}),
+		WithOnDiff(func(entry DiffEntry) { seen = append(seen, entry) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 1 || seen[0].Severity != "critical" {
+		t.Errorf("expected the streamed entry to be labelled, got %+v", seen)
+	}
+}
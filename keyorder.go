@@ -0,0 +1,191 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// WithSourceKeyOrder renders the keys of objects in the order they appear in the expected document, followed by
+// the keys found in the actual document only in their order there, instead of sorting them, so the diff matches
+// the raw payloads seen in logs. The order is read from the documents as given, before any normalization.
+func WithSourceKeyOrder() Option {
+	return func(o *options) {
+		o.sourceKeyOrder = true
+	}
+}
+
+// keyOrder records the order of the keys of the objects of the source documents, by gjson-style path of the
+// object.
+type keyOrder map[string][]string
+
+// sourceKeyOrder reads the order of the keys of the expected document and then of the actual one, whose keys are
+// appended to the objects they share with the expected document.
+func sourceKeyOrder(expectedJSON, actualJSON []byte) keyOrder {
+	order := keyOrder{}
+	order.record("", gjson.ParseBytes(expectedJSON))
+	order.record("", gjson.ParseBytes(actualJSON))
+	return order
+}
+
+// record appends the keys of the objects within a parsed value that are not recorded yet.
+func (k keyOrder) record(path string, value gjson.Result) {
+	switch {
+	case value.IsObject():
+		seen := map[string]bool{}
+		for _, key := range k[path] {
+			seen[key] = true
+		}
+		value.ForEach(func(key, member gjson.Result) bool {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				k[path] = append(k[path], key.String())
+			}
+			k.record(joinPath(path, escapePathKey(key.String())), member)
+			return true
+		})
+	case value.IsArray():
+		for i, element := range value.Array() {
+			k.record(joinPath(path, strconv.Itoa(i)), element)
+		}
+	}
+}
+
+// sort orders the keys of the object at a path by their position in the source documents. Keys that are not
+// recorded, e.g. because a normalizer added them, follow in sorted order. Without a recorded order the keys are
+// sorted.
+func (k keyOrder) sort(path string, keys []string) []string {
+	sort.Strings(keys)
+	recorded := k[path]
+	if len(recorded) == 0 {
+		return keys
+	}
+	position := make(map[string]int, len(recorded))
+	for i, key := range recorded {
+		position[key] = i
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		pi, iok := position[keys[i]]
+		pj, jok := position[keys[j]]
+		if iok != jok {
+			return iok
+		}
+		return iok && pi < pj
+	})
+	return keys
+}
+
+// sortChildren orders the children of the nodes of a difference tree by the position of their keys in the source
+// documents. A nil keyOrder sorts them by key.
+func (k keyOrder) sortChildren(n *diffNode) {
+	if n == nil || len(n.children) == 0 {
+		return
+	}
+	keys := make([]string, len(n.children))
+	byKey := make(map[string]*diffNode, len(n.children))
+	for i, child := range n.children {
+		keys[i] = lastPathKey(child.path)
+		byKey[keys[i]] = child
+	}
+	for i, key := range k.sort(n.path, keys) {
+		n.children[i] = byKey[key]
+		k.sortChildren(n.children[i])
+	}
+}
+
+// lastPathKey returns the unescaped last key of a gjson-style path.
+func lastPathKey(path string) string {
+	var key []byte
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			key = append(key, path[i])
+		case path[i] == '.':
+			key = key[:0]
+		default:
+			key = append(key, path[i])
+		}
+	}
+	return string(key)
+}
+
+// marshal encodes a value like json.MarshalIndent, or like json.Marshal if indent is empty, with the keys of the
+// objects in their source order.
+func (k keyOrder) marshal(path string, value interface{}, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := k.encode(&buf, path, value, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes the encoding of a value at a path, whose lines after the first start with prefix.
+func (k keyOrder) encode(buf *bytes.Buffer, path string, value interface{}, prefix, indent string) error {
+	newline, separator := "\n"+prefix+indent, ": "
+	if indent == "" {
+		newline, separator = "", ":"
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		buf.WriteByte('{')
+		for i, key := range k.sort(path, keys) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(newline)
+			quoted, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.Write(quoted)
+			buf.WriteString(separator)
+			if err := k.encode(buf, joinPath(path, escapePathKey(key)), v[key], prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(closingNewline(prefix, indent) + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(newline)
+			if err := k.encode(buf, joinPath(path, strconv.Itoa(i)), element, prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(closingNewline(prefix, indent) + "]")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
+
+// closingNewline returns the line break before the closing bracket of an indented object or array.
+func closingNewline(prefix, indent string) string {
+	if indent == "" {
+		return ""
+	}
+	return "\n" + prefix
+}
@@ -0,0 +1,109 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithSourceKeyOrder(t *testing.T) {
+	expected := []byte(`{"zeta":1,"id":7,"items":[{"name":"Cat","age":3}]}`)
+	actual := []byte(`{"zeta":2,"id":7,"items":[{"name":"Dog","age":3,"extra":true},{"y":1,"x":{"q":1,"p":2}}]}`)
+
+	sorted, err := CompareJSON(expected, actual, nil, false, WithCompact(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := RenderPlain(sorted); !strings.Contains(got, `"age": 3,`+"\n"+`       "name"`) || !strings.Contains(got, `{"x":{"p":2,"q":1},"y":1}`) {
+		t.Errorf("expected sorted keys by default, got:\n%s", got)
+	}
+
+	resp, err := CompareJSON(expected, actual, nil, false, WithCompact(40), WithSourceKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- expected
+{
+ context:
+   "id": 7
+ "zeta": [-1-] ,
+ "items": [
+   [0]: {
+       "name": [-"Cat"-] ,
+       "age": 3,
+     }
+ ]
+ }
+--- actual
+{
+ context:
+   "id": 7
+ "zeta": {+2+} ,
+ "items": [
+   [0]: {
+       "name": {+"Dog"+} ,
+       "age": 3,
+       {+"extra"+}: {+true+},
+     }
+   [1]: {+{"y":1,"x":{"q":1,"p":2}}+}
+ ]
+ }
+`
+	if got := RenderPlain(resp); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithSourceKeyOrderAfterNormalization(t *testing.T) {
+	// Normalizers re-encode the documents with sorted keys, the source order still applies.
+	expected := []byte(`{"b":"x","a":" y "}`)
+	actual := []byte(`{"b":"z","a":"q"}`)
+	resp, err := CompareJSON(expected, actual, nil, true, WithStringNormalizers(CollapseWhitespace), WithSourceKeyOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, a := strings.Index(resp.Expected, `"b"`), strings.Index(resp.Expected, `"a"`); b < 0 || a < 0 || b > a {
+		t.Errorf("expected b before a, got:\n%s", resp.Expected)
+	}
+}
+
+func TestKeyOrderIndependentOfTransforms(t *testing.T) {
+	expected := []byte(`{"zeta":1,"alpha":{"y":1,"b":1},"mid":[1]}`)
+	actual := []byte(`{"zeta":2,"alpha":{"y":2,"b":2},"mid":[2]}`)
+
+	plain, err := CompareJSON(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformed, err := CompareJSON(expected, actual, nil, true, WithNestedJSONStrings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Expected != transformed.Expected || plain.Actual != transformed.Actual {
+		t.Errorf("expected a transform not to change the order of the output, got:\n%s\nand:\n%s", plain.Expected, transformed.Expected)
+	}
+	if alpha, zeta := strings.Index(plain.Expected, "alpha"), strings.Index(plain.Expected, "zeta"); alpha < 0 || alpha > zeta {
+		t.Errorf("expected the keys to be sorted, got:\n%s", plain.Expected)
+	}
+}
+
+func TestKeyOrderSort(t *testing.T) {
+	order := sourceKeyOrder([]byte(`{"c":1,"a":{"y":1,"x":2}}`), []byte(`{"b":1,"c":2,"a":{"z":1}}`))
+	tests := []struct {
+		path string
+		keys []string
+		want []string
+	}{
+		{path: "", keys: []string{"a", "b", "c", "added"}, want: []string{"c", "a", "b", "added"}},
+		{path: "a", keys: []string{"x", "y", "z"}, want: []string{"y", "x", "z"}},
+		{path: "unknown", keys: []string{"b", "a"}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := order.sort(tt.path, tt.keys); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := lastPathKey(`items.0.a\.b`); got != "a.b" {
+		t.Errorf("lastPathKey() = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// KeyReport lists the paths whose presence or type differs between two documents, as gjson-style paths in path
+// order.
+// OnlyExpected: The paths present in the expected document only.
+// OnlyActual: The paths present in the actual document only.
+// TypeChanged: The paths present in both documents whose values have different JSON types.
+type KeyReport struct {
+	OnlyExpected []string
+	OnlyActual   []string
+	TypeChanged  []string
+}
+
+// Empty reports whether the documents have the same keys with the same types.
+func (r KeyReport) Empty() bool {
+	return len(r.OnlyExpected) == 0 && len(r.OnlyActual) == 0 && len(r.TypeChanged) == 0
+}
+
+// CompareKeys compares the keys of two JSON documents without comparing or rendering their values, as a lightweight
+// contract check. Array elements are compared by index, so elements beyond the length of the other array are
+// reported like keys.
+func CompareKeys(expectedJSON, actualJSON []byte) (KeyReport, error) {
+	var expected, actual interface{}
+	if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+		return KeyReport{}, fmt.Errorf("decoding expected JSON: %w", err)
+	}
+	if err := json.Unmarshal(actualJSON, &actual); err != nil {
+		return KeyReport{}, fmt.Errorf("decoding actual JSON: %w", err)
+	}
+
+	var report KeyReport
+	walkValues("", "", expected, actual, nil, func(entry DiffEntry) {
+		switch entry.Op {
+		case OpRemoved:
+			report.OnlyExpected = append(report.OnlyExpected, entry.Path)
+		case OpAdded:
+			report.OnlyActual = append(report.OnlyActual, entry.Path)
+		case OpChanged:
+			if jsonTypeName(entry.Expected) != jsonTypeName(entry.Actual) {
+				report.TypeChanged = append(report.TypeChanged, entry.Path)
+			}
+		}
+	})
+	return report, nil
+}
+
+// jsonTypeName returns the name of the JSON type of a decoded value: "object", "array", "string", "number",
+// "boolean" or "null".
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return "number"
+}
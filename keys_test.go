@@ -0,0 +1,39 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareKeys(t *testing.T) {
+	json1 := `{"id":1,"name":"Cat","tags":["a","b"],"meta":{"active":true,"owner":null}}`
+	json2 := `{"id":"1","name":"Dog","tags":["a"],"meta":{"active":false,"owner":{"id":2}},"extra":1}`
+
+	report, err := CompareKeys([]byte(json1), []byte(json2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := KeyReport{
+		OnlyExpected: []string{"tags.1"},
+		OnlyActual:   []string{"extra"},
+		TypeChanged:  []string{"id", "meta.owner"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("CompareKeys() = %+v, want %+v", report, want)
+	}
+	if report.Empty() {
+		t.Error("expected a non-empty report")
+	}
+
+	report, err = CompareKeys([]byte(json1), []byte(json1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Empty() {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+
+	if _, err := CompareKeys([]byte(`{`), []byte(json1)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,28 @@
+package colorisediff
+
+// Labels names the two sides of a comparison in the rendered output, for comparisons that are not between an
+// expectation and an actual result, e.g. a recorded and a replayed response. Empty labels fall back to "Expected"
+// and "Actual", spelled the way each renderer spells them.
+type Labels struct {
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// WithLabels names the two sides of the comparison, e.g. WithLabels("recorded", "replayed"). The labels are kept on
+// the returned Diff and used by all of its renderers instead of "Expected" and "Actual".
+func WithLabels(expected, actual string) Option {
+	return func(o *options) {
+		o.labels = Labels{Expected: expected, Actual: actual}
+	}
+}
+
+// or returns the labels, with the given defaults in place of empty ones.
+func (l Labels) or(expected, actual string) (string, string) {
+	if l.Expected != "" {
+		expected = l.Expected
+	}
+	if l.Actual != "" {
+		actual = l.Actual
+	}
+	return expected, actual
+}
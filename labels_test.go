@@ -0,0 +1,68 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithLabels(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"a":1}`), []byte(`{"a":2}`), nil, true, WithLabels("recorded", "replayed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Labels != (Labels{Expected: "recorded", Actual: "replayed"}) {
+		t.Fatalf("unexpected labels %+v", resp.Labels)
+	}
+
+	tests := []struct {
+		format Format
+		want   []string
+	}{
+		{format: FormatTable, want: []string{"RECORDED", "REPLAYED"}},
+		{format: FormatHTML, want: []string{"<th>recorded</th><th>replayed</th>"}},
+	}
+	for _, tt := range tests {
+		out, err := resp.Render(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("format %v: expected %q in:\n%s", tt.format, want, out)
+			}
+		}
+		if strings.Contains(out, "Expected") || strings.Contains(out, "EXPECTED") {
+			t.Errorf("format %v: expected no default label in:\n%s", tt.format, out)
+		}
+	}
+	if plain := RenderPlain(resp); !strings.HasPrefix(plain, "--- recorded\n") || !strings.Contains(plain, "\n--- replayed\n") {
+		t.Errorf("unexpected plain rendering:\n%s", plain)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored Diff
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Labels != resp.Labels {
+		t.Errorf("labels not restored from %s", data)
+	}
+}
+
+func TestWithLabelsDefaults(t *testing.T) {
+	resp := CompareStatus(200, 500, WithLabels("", "replayed"))
+	if plain := RenderPlain(resp); !strings.HasPrefix(plain, "--- expected\n") || !strings.Contains(plain, "\n--- replayed\n") {
+		t.Errorf("expected the default for the empty label, got:\n%s", plain)
+	}
+	data, err := json.Marshal(CompareStatus(200, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "labels") {
+		t.Errorf("expected no labels without WithLabels, got %s", data)
+	}
+}
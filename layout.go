@@ -0,0 +1,18 @@
+package colorisediff
+
+// WithIndent sets the indentation added per nesting level of the colorized output and of the JSON values shown in
+// it, e.g. "    " or "\t". It defaults to two spaces.
+func WithIndent(indent string) Option {
+	return func(o *options) {
+		o.valueRenderers.indent = indent
+	}
+}
+
+// WithCompact keeps objects and arrays on one line in the colorized output when their JSON encoding is at most
+// maxWidth bytes long, e.g. {"id":1,"name":"Cat"}, instead of spreading them over indented lines. Larger values
+// are still indented.
+func WithCompact(maxWidth int) Option {
+	return func(o *options) {
+		o.valueRenderers.compactWidth = maxWidth
+	}
+}
@@ -0,0 +1,64 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithIndent(t *testing.T) {
+	expected := []byte(`{"items":[{"id":1,"tags":["a"]}],"meta":{"page":1}}`)
+	actual := []byte(`{"items":[{"id":1,"tags":["a"]},{"id":2}],"meta":{"page":2}}`)
+
+	tests := []struct {
+		indent string
+		want   string
+	}{
+		{indent: "", want: "\n       \"id\": 1,\n"},
+		{indent: "    ", want: "\n             \"id\": 1,\n"},
+		{indent: "\t", want: "\n \t\t\t\"id\": 1,\n"},
+	}
+	for _, tt := range tests {
+		resp, err := CompareJSON(expected, actual, nil, true, WithIndent(tt.indent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(resp.Expected, tt.want) {
+			t.Errorf("WithIndent(%q): expected %q in:\n%s", tt.indent, tt.want, resp.Expected)
+		}
+	}
+}
+
+func TestWithCompact(t *testing.T) {
+	expected := []byte(`{"items":[{"id":1}]}`)
+	actual := []byte(`{"items":[{"id":1},{"id":2,"tags":["b","c"]},{"id":3,"note":"a value too long to fit on the line"}]}`)
+
+	resp, err := CompareJSON(expected, actual, nil, false, WithCompact(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- expected
+{
+ "items": [
+   [0]: {
+       "id": 1,
+     }
+ ]
+ }
+--- actual
+{
+ "items": [
+   [0]: {
+       "id": 1,
+     }
+   [1]: {+{"id":2,"tags":["b","c"]}+}
+   [2]: {+{+}
+{+  "id": 3,+}
+{+  "note": "a value too long to fit on the line"+}
+{+}+}
+ ]
+ }
+`
+	if got := RenderPlain(resp); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
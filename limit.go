@@ -0,0 +1,108 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// omittedPathsShown is the number of omitted paths listed in the footer of a truncated output.
+const omittedPathsShown = 3
+
+// WithMaxOutputLines caps the rendered output at n lines per side, protecting terminals and CI logs from enormous
+// diffs. An output that would be longer is cut after the lines that fit, keeping its layout, and ends with a footer
+// stating how many differences were cut off and the paths of the first few of them. The footer counts towards the
+// limit.
+func WithMaxOutputLines(n int) Option {
+	return func(o *options) {
+		o.maxOutputLines = n
+	}
+}
+
+// outputSpan locates the rendering of the differences at or below a path within the rendered sides.
+type outputSpan struct {
+	path        string // path is the gjson-style path of the rendered differences.
+	expectedEnd int    // expectedEnd is the byte offset in the expected side where the rendering ends.
+	actualEnd   int    // actualEnd is the byte offset in the actual side where the rendering ends.
+}
+
+// limitOutput returns the rendered sides unchanged if they fit within the line limit, and otherwise cuts them to
+// leave room for the footer listing the differences cut off, along with the number of differences omitted. A
+// difference is omitted unless the span rendering it ends within the kept lines of both sides.
+func (o *options) limitOutput(expected, actual string, entries []DiffEntry, spans []outputSpan) (string, string, int) {
+	if o.maxOutputLines <= 0 || (countLines(expected) <= o.maxOutputLines && countLines(actual) <= o.maxOutputLines) {
+		return expected, actual, 0
+	}
+
+	// The footer may wrap onto more lines as more differences are cut off, so the cut is moved up until it fits.
+	footerLines := 1
+	for {
+		kept := o.maxOutputLines - footerLines
+		if kept < 0 {
+			kept = 0
+		}
+		expectedCut, actualCut := lineOffset(expected, kept), lineOffset(actual, kept)
+		var omitted []DiffEntry
+		for _, entry := range entries {
+			if !entry.Noised && !spanWithin(spans, entry.Path, expectedCut, actualCut) {
+				omitted = append(omitted, entry)
+			}
+		}
+		footer := omittedFooter(omitted, o.palette)
+		if lines := countLines(footer); lines > footerLines && kept > 0 {
+			footerLines = lines
+			continue
+		}
+		return expected[:expectedCut] + footer, actual[:actualCut] + footer, len(omitted)
+	}
+}
+
+// lineOffset returns the byte offset following the first n lines of a rendered side.
+func lineOffset(s string, n int) int {
+	offset := 0
+	for ; n > 0; n-- {
+		end := strings.IndexByte(s[offset:], '\n')
+		if end < 0 {
+			return len(s)
+		}
+		offset += end + 1
+	}
+	return offset
+}
+
+// spanWithin reports whether a span rendering the differences at a path ends within the offsets of both sides.
+func spanWithin(spans []outputSpan, path string, expectedCut, actualCut int) bool {
+	for _, span := range spans {
+		if span.path == "" || span.path == path || strings.HasPrefix(path, span.path+".") {
+			return span.expectedEnd <= expectedCut && span.actualEnd <= actualCut
+		}
+	}
+	return false
+}
+
+// omittedFooter renders the colored footer of a truncated output listing the omitted differences, if any.
+func omittedFooter(omitted []DiffEntry, p palette) string {
+	if len(omitted) == 0 {
+		return p.sprintFunc(color.FgYellow)("... output truncated") + "\n"
+	}
+	paths := make([]string, 0, omittedPathsShown)
+	for _, entry := range omitted {
+		if len(paths) == omittedPathsShown {
+			paths = append(paths, "...")
+			break
+		}
+		paths = append(paths, entry.Path)
+	}
+	unit := "differences"
+	if len(omitted) == 1 {
+		unit = "difference"
+	}
+	footer := fmt.Sprintf("... %d more %s omitted: %s", len(omitted), unit, strings.Join(paths, ", "))
+	return breakLines(p.sprintFunc(color.FgYellow)(footer)) + "\n"
+}
+
+// countLines returns the number of lines of a rendered side.
+func countLines(s string) int {
+	return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+}
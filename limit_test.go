@@ -0,0 +1,64 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxOutputLines(t *testing.T) {
+	expected := `{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6}`
+	actual := `{"a": 10, "b": 20, "c": 30, "d": 40, "e": 50, "f": 60}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithMaxOutputLines(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n \"a\": 1 ,\n \"b\": 2 ,\n... 4 more differences omitted: c, d, e, ...\n"
+	if resp.Expected != want {
+		t.Errorf("Expected = %q, want %q", resp.Expected, want)
+	}
+	if !strings.HasPrefix(resp.Actual, "{\n \"a\": 10 ,\n \"b\": 20 ,\n") || countLines(resp.Actual) != 4 {
+		t.Errorf("unexpected actual output %q", resp.Actual)
+	}
+	if len(resp.Entries) != 6 {
+		t.Errorf("expected all entries to be kept, got %d", len(resp.Entries))
+	}
+
+	unlimited, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithMaxOutputLines(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(unlimited.Expected, "omitted") {
+		t.Errorf("unexpected footer in %q", unlimited.Expected)
+	}
+}
+
+func TestMaxOutputLinesKeepsLayout(t *testing.T) {
+	expected := `{"headers":{"a":1,"b":2},"body":{"c":3,"d":4}}`
+	actual := `{"headers":{"a":10,"b":20},"body":{"c":30,"d":40}}`
+
+	// Sections keep their headers, and the footer counts towards the limit even when it wraps.
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithSectionGrouping(1), WithMaxOutputLines(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(NormalizeForTest(resp.Expected), "\n")
+	if len(lines) != 5 || lines[0] != "body (2 changes)" || lines[2] != "body.d: 4" || !strings.HasPrefix(lines[3], "... 2 more differences omitted: headers.a,") {
+		t.Errorf("unexpected output %q", resp.Expected)
+	}
+
+	// Cutting off only the notes omits no difference.
+	full, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithSeverityRules(SeverityRule{Pattern: "**", Label: "minor"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := strings.Index(full.Expected, " }\n")
+	n = strings.Count(full.Expected[:n], "\n") + 1
+	resp, err = CompareJSON([]byte(expected), []byte(actual), nil, true, WithSeverityRules(SeverityRule{Pattern: "**", Label: "minor"}), WithMaxOutputLines(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countLines(resp.Expected) != n || !strings.HasSuffix(resp.Expected, "... output truncated\n") || len(resp.Warnings) != 0 {
+		t.Errorf("unexpected output %q with warnings %+v", resp.Expected, resp.Warnings)
+	}
+}
@@ -0,0 +1,109 @@
+package colorisediff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// localeNumberRegex matches numbers written with digit group and decimal separators, e.g. "1,234.50",
+// "1.234,50", "1 234,5" or "-1'000".
+var localeNumberRegex = regexp.MustCompile(`^[+-]?\d+(?:[.,' \x{00a0}\x{202f}]\d+)*$`)
+
+// WithLocaleNumbers makes the comparison treat strings holding numbers written with thousands separators or
+// comma decimals ("1,234.50", "1234,50") as the numbers they denote. Differing strings, or a string and a
+// number, denoting the same value compare equal; otherwise they are compared as numbers.
+//
+// When only one kind of separator occurs, it is a decimal separator if it occurs once and is not followed by
+// exactly three digits, and a thousands separator otherwise. When both occur, the last one is the decimal
+// separator.
+func WithLocaleNumbers() Option {
+	return func(o *options) {
+		o.localeNumbers = true
+	}
+}
+
+// compareLocaleNumbers replaces two differing values that denote numbers, at least one of them as a locale
+// formatted string, with the numbers, or with the expected value on both sides if they are equal.
+func compareLocaleNumbers(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	_, expectedIsString := expected.(string)
+	_, actualIsString := actual.(string)
+	if !expectedIsString && !actualIsString {
+		return nil, nil, "", false
+	}
+	if expected == actual {
+		return nil, nil, "", false
+	}
+	e, ok := localeNumberValue(expected)
+	if !ok {
+		return nil, nil, "", false
+	}
+	a, ok := localeNumberValue(actual)
+	if !ok {
+		return nil, nil, "", false
+	}
+	if e == a {
+		return expected, expected, "", true
+	}
+	return e, a, "compared as numbers", true
+}
+
+// localeNumberValue returns the number a JSON number or locale formatted string denotes.
+func localeNumberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		return parseLocaleNumber(v)
+	}
+	return 0, false
+}
+
+// parseLocaleNumber parses a number written with digit group and decimal separators.
+func parseLocaleNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if !localeNumberRegex.MatchString(s) {
+		return 0, false
+	}
+	s = strings.NewReplacer(" ", "", "'", "", "\u00a0", "", "\u202f", "").Replace(s)
+
+	decimal := ""
+	lastComma, lastDot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		decimal = ","
+		if lastDot > lastComma {
+			decimal = "."
+		}
+	case lastComma >= 0:
+		decimal = decimalSeparator(s, ",")
+	case lastDot >= 0:
+		decimal = decimalSeparator(s, ".")
+	}
+
+	var builder strings.Builder
+	for i, char := range s {
+		switch {
+		case string(char) == decimal && i == strings.LastIndex(s, decimal):
+			builder.WriteRune('.')
+		case char == ',' || char == '.':
+			// A digit group separator.
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	n, err := strconv.ParseFloat(builder.String(), 64)
+	return n, err == nil
+}
+
+// decimalSeparator returns the separator if it is the only separator of a number and acts as its decimal
+// separator, or "" if it separates digit groups.
+func decimalSeparator(s, separator string) string {
+	if strings.Count(s, separator) > 1 {
+		return ""
+	}
+	if len(s)-strings.LastIndex(s, separator)-1 == 3 {
+		return ""
+	}
+	return separator
+}
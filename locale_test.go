@@ -0,0 +1,51 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocaleNumbers(t *testing.T) {
+	expected := `{"total": "1,234.50", "tax": "12,5", "count": "1.000.000", "fee": 3, "id": "12-34"}`
+	actual := `{"total": "1234,50", "tax": "12.6", "count": 1000000, "fee": "3", "id": "1234"}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithLocaleNumbers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "id", Op: OpChanged, Expected: "12-34", Actual: "1234"},
+		{Path: "tax", Op: OpChanged, Expected: 12.5, Actual: 12.6, Note: "compared as numbers"},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v, want %+v", resp.Entries, want)
+	}
+}
+
+func TestParseLocaleNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		ok    bool
+	}{
+		{input: "1,234.50", want: 1234.5, ok: true},
+		{input: "1.234,50", want: 1234.5, ok: true},
+		{input: "1234,50", want: 1234.5, ok: true},
+		{input: "1,234", want: 1234, ok: true},
+		{input: "1,234,567", want: 1234567, ok: true},
+		{input: "1 234,5", want: 1234.5, ok: true},
+		{input: "-1'000.25", want: -1000.25, ok: true},
+		{input: "42", want: 42, ok: true},
+		{input: "1,,2"},
+		{input: "abc"},
+		{input: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseLocaleNumber(tt.input)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseLocaleNumber(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
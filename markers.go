@@ -0,0 +1,55 @@
+package colorisediff
+
+import "unicode/utf8"
+
+// Markers are the characters the colorizer inserts into values it shortens or wraps.
+type Markers struct {
+	Ellipsis     string // Ellipsis stands for the text left out of a value, "…" if empty.
+	Continuation string // Continuation starts the lines continuing a wrapped line, none if empty.
+	LineBreak    string // LineBreak ends the lines broken by the wrapping, none if empty.
+}
+
+// ASCIIMarkers keeps the output ASCII-only, e.g. for legacy log systems, and marks the wrapped lines with a
+// backslash at the end.
+var ASCIIMarkers = Markers{Ellipsis: "...", LineBreak: "\\"}
+
+// WithMarkers sets the characters marking the text left out of shortened values, such as the words hidden by
+// WithStringWindow and the digests of binary summaries, and the lines broken by the wrapping of long values.
+func WithMarkers(markers Markers) Option {
+	return func(o *options) {
+		o.valueRenderers.markers = markers
+	}
+}
+
+// ellipsis returns the marker standing for text left out of a value.
+func (m Markers) ellipsis() string {
+	if m.Ellipsis == "" {
+		return "…"
+	}
+	return m.Ellipsis
+}
+
+// fit returns the visible lengths of the line break and continuation markers, or zeros if a line of the given width
+// would have no room left for the text.
+func (m Markers) fit(width int) (int, int) {
+	lineBreak, continuation := utf8.RuneCountInString(m.LineBreak), utf8.RuneCountInString(m.Continuation)
+	if width <= 0 || lineBreak+continuation >= width {
+		return 0, 0
+	}
+	return lineBreak, continuation
+}
+
+// breakLines breaks the input string into lines of at most maxLineLength visible characters like the breakLines
+// function, marking the lines it breaks.
+func (r valueRenderers) breakLines(input string) string {
+	return wrapANSI(input, maxLineLength, r.markers)
+}
+
+// breakWithColor is the breakWithColor function marking the lines it breaks.
+func (r valueRenderers) breakWithColor(input string, paint func(a ...interface{}) string, highlightRanges []colorRange) string {
+	output := wrapANSI(paintRanges(input, paint, highlightRanges), maxLineLength, r.markers)
+	if output != "" && output[len(output)-1] != '\n' {
+		output += "\n"
+	}
+	return output
+}
@@ -0,0 +1,69 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapANSIMarkers(t *testing.T) {
+	const green = "\x1b[32m"
+	markers := Markers{Continuation: ">", LineBreak: "\\"}
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "fits", text: "abcd", width: 4, want: "abcd"},
+		{name: "broken lines are marked", text: "abcdefgh", width: 4, want: "abc\\\n>de\\\n>fgh"},
+		{name: "existing line breaks are not marked", text: "ab\ncd", width: 4, want: "ab\ncd"},
+		{name: "markers are not colored", text: green + "abcdef" + ansiResetCode, width: 4, want: green + "abc" + ansiResetCode + "\\\n>" + green + "def" + ansiResetCode},
+		{name: "no room for the markers", text: "abcdef", width: 2, want: "ab\ncd\nef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapANSI(tt.text, tt.width, markers)
+			if got != tt.want {
+				t.Errorf("wrapANSI(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			if again := wrapANSI(got, tt.width, markers); again != got {
+				t.Errorf("wrapANSI is not idempotent: %q became %q", got, again)
+			}
+		})
+	}
+}
+
+func TestWithMarkers(t *testing.T) {
+	paragraph := strings.Repeat("lorem ipsum dolor sit amet ", 4)
+	expected := []byte(`{"text":"` + paragraph + `quick fox ` + paragraph + `"}`)
+	actual := []byte(`{"text":"` + paragraph + `quick dog ` + paragraph + `"}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithStringWindow(12), WithMarkers(ASCIIMarkers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range []string{resp.Expected, resp.Actual} {
+		for _, char := range side {
+			if char > '~' {
+				t.Fatalf("expected ASCII-only output, got %q in:\n%s", char, side)
+			}
+		}
+		if !strings.Contains(side, "...(@") {
+			t.Errorf("expected the ASCII ellipsis, got:\n%s", side)
+		}
+	}
+
+	long := strings.Repeat("x", maxLineLength*2)
+	resp, err = CompareJSON([]byte(`{"a":"`+long+`"}`), []byte(`{"a":"y`+long+`"}`), nil, true, WithMarkers(ASCIIMarkers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(resp.Actual, "\n"), "\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line %q is longer than %d characters", line, maxLineLength)
+		}
+	}
+	if !strings.Contains(resp.Actual, "\\\n") {
+		t.Errorf("expected marked line breaks, got:\n%s", resp.Actual)
+	}
+}
@@ -0,0 +1,194 @@
+package colorisediff
+
+import "encoding/json"
+
+// diffSchemaVersion is the version of the structured serialization produced by Diff.MarshalJSON.
+const diffSchemaVersion = 1
+
+// Stats summarizes the entries of a Diff.
+// Added, Removed, Changed: The number of differences of each kind that are not noised.
+// Noised: The number of differences suppressed by noise rules.
+// Accepted: The number of the noised differences accepted by waivers.
+// Spilled: The number of differences that are not noised and were only counted because of the memory budget.
+// Total: The number of differences that are not noised.
+// AssertionFailures: The number of assertions given with WithAssertions that failed.
+// Inconsistencies: The number of violations of the checks given with WithConsistencyChecks.
+type Stats struct {
+	Added             int `json:"added"`
+	Removed           int `json:"removed"`
+	Changed           int `json:"changed"`
+	Noised            int `json:"noised"`
+	Accepted          int `json:"accepted,omitempty" yaml:"accepted,omitempty"`
+	Spilled           int `json:"spilled,omitempty" yaml:"spilled,omitempty"`
+	Total             int `json:"total"`
+	AssertionFailures int `json:"assertionFailures,omitempty" yaml:"assertionFailures,omitempty"`
+	Inconsistencies   int `json:"inconsistencies,omitempty" yaml:"inconsistencies,omitempty"`
+}
+
+// Failed reports whether the comparison failed: whether any difference is not noised, any assertion failed or any
+// consistency check was violated.
+func (s Stats) Failed() bool {
+	return s.Total > 0 || s.AssertionFailures > 0 || s.Inconsistencies > 0
+}
+
+// OK reports whether the comparison passed, with no difference that is not noised, no failed assertion and no
+// inconsistency.
+func (d Diff) OK() bool {
+	return !d.Stats().Failed()
+}
+
+// Stats counts the entries of the diff by kind.
+func (d Diff) Stats() Stats {
+	var stats Stats
+	for _, entry := range d.Entries {
+		if entry.Noised {
+			stats.Noised++
+			if entry.Waiver != "" {
+				stats.Accepted++
+			}
+			continue
+		}
+		switch entry.Op {
+		case OpAdded:
+			stats.Added++
+		case OpRemoved:
+			stats.Removed++
+		case OpChanged:
+			stats.Changed++
+		}
+		stats.Total++
+	}
+	for _, summary := range d.Spilled {
+		stats.Spilled += summary.Differences
+		stats.Noised += summary.Noised
+		stats.Total += summary.Differences
+	}
+	stats.AssertionFailures = len(d.AssertionFailures)
+	stats.Inconsistencies = len(d.Inconsistencies)
+	return stats
+}
+
+// diffJSON is the wire form of a Diff.
+type diffJSON struct {
+	Version int         `json:"version"`
+	Entries []entryJSON `json:"entries"`
+	Noise   []string    `json:"noise"`
+	Stats   Stats       `json:"stats"`
+
+	ArrayMappings []ArrayMapping `json:"arrayMappings,omitempty"`
+	Labels        *Labels        `json:"labels,omitempty"`
+	LengthChanges []LengthChange `json:"lengthChanges,omitempty"`
+
+	AssertionFailures []AssertionFailure `json:"assertionFailures,omitempty"`
+	Inconsistencies   []Inconsistency    `json:"inconsistencies,omitempty"`
+	Spilled           []SpillSummary     `json:"spilled,omitempty"`
+	Warnings          []Warning          `json:"warnings,omitempty"`
+}
+
+// entryJSON is the wire form of a DiffEntry.
+type entryJSON struct {
+	Path        string      `json:"path"`
+	Pointer     string      `json:"pointer"`
+	Op          Op          `json:"op"`
+	Expected    interface{} `json:"expected,omitempty"`
+	Actual      interface{} `json:"actual,omitempty"`
+	Noised      bool        `json:"noised,omitempty"`
+	Note        string      `json:"note,omitempty"`
+	Violation   string      `json:"violation,omitempty"`
+	Severity    string      `json:"severity,omitempty"`
+	NoiseReason string      `json:"noiseReason,omitempty"`
+	Waiver      string      `json:"waiver,omitempty"`
+	Delta       string      `json:"delta,omitempty"`
+}
+
+// newEntryJSON returns the wire form of an entry.
+func newEntryJSON(entry DiffEntry) entryJSON {
+	return entryJSON{
+		Path:        entry.Path,
+		Pointer:     entry.Pointer(),
+		Op:          entry.Op,
+		Expected:    entry.Expected,
+		Actual:      entry.Actual,
+		Noised:      entry.Noised,
+		Note:        entry.Note,
+		Violation:   entry.Violation,
+		Severity:    entry.Severity,
+		NoiseReason: entry.NoiseReason,
+		Waiver:      entry.Waiver,
+		Delta:       entry.Delta,
+	}
+}
+
+// entry returns the entry of its wire form. The pointer is derived from the path.
+func (e entryJSON) entry() DiffEntry {
+	return DiffEntry{
+		Path:        e.Path,
+		Op:          e.Op,
+		Expected:    e.Expected,
+		Actual:      e.Actual,
+		Noised:      e.Noised,
+		Note:        e.Note,
+		Violation:   e.Violation,
+		Severity:    e.Severity,
+		NoiseReason: e.NoiseReason,
+		Waiver:      e.Waiver,
+		Delta:       e.Delta,
+	}
+}
+
+// MarshalJSON serializes the structured part of the diff. The colorized strings are not included.
+//
+// The produced document has the following shape:
+//
+//	{
+//	  "version": 1,
+//	  "entries": [{"path": "a.b", "pointer": "/a/b", "op": "changed", "expected": 1, "actual": 2, "noised": false}],
+//	  "noise": ["paths", "of", "noised", "entries"],
+//	  "stats": {"added": 0, "removed": 0, "changed": 1, "noised": 0, "total": 1}
+//	}
+//
+// "pointer" is the RFC 6901 JSON Pointer of the entry, for tools that do not read gjson-style paths. "op" is one of
+// "added", "removed" or "changed". "expected" is omitted for added entries and "actual" is omitted for removed
+// entries. Entries may carry a "note" describing how their values were prepared
+// and a "violation" describing why the actual value no longer validates against the schema, as well as the
+// "severity" assigned by the severity rules, the "noiseReason" explaining why a noised entry is ignored and the
+// "waiver" accepting it, and changed numbers the "delta" given with WithNumericDeltas. The stats count the "accepted" entries, if any.
+// Comparisons matching arrays regardless of order add the "arrayMappings" of the reordered arrays, e.g.
+// [{"path": "items", "pairs": [{"expected": 0, "actual": 2}]}], and comparisons with WithLabels add the "labels"
+// of the two sides, e.g. {"expected": "recorded", "actual": "replayed"}. Comparisons with WithArrayLengthSummaries
+// add the "lengthChanges" of the arrays whose length differs, e.g.
+// [{"path": "animals", "expected": 3, "actual": 4, "inserted": [2]}]. Comparisons with WithAssertions add the
+// "assertionFailures" of the assertions that do not hold, e.g. [{"expression": "total >= 0", "reason": "total is -1"}],
+// and comparisons with WithConsistencyChecks the "inconsistencies" of the violated checks, e.g.
+// [{"check": "items vs count", "path": "items", "other": "count", "reason": "items has length 3, count is 2"}].
+// Comparisons exceeding the memory budget given with WithMemoryBudget add the "spilled" summaries of the
+// differences left out, e.g. [{"path": "items", "differences": 120}], which the stats count as "spilled". The
+// "warnings" list the issues that did not stop the comparison, e.g.
+// [{"kind": "lossyNumber", "side": "actual", "path": "id", "message": "12345678901234567890 is compared as ..."}].
+func (d Diff) MarshalJSON() (data []byte, err error) {
+	defer recoverPanic(&err)
+	out := diffJSON{
+		Version:       diffSchemaVersion,
+		Entries:       make([]entryJSON, 0, len(d.Entries)),
+		Noise:         []string{},
+		Stats:         d.Stats(),
+		ArrayMappings: d.ArrayMappings,
+		LengthChanges: d.LengthChanges,
+
+		AssertionFailures: d.AssertionFailures,
+		Inconsistencies:   d.Inconsistencies,
+		Spilled:           d.Spilled,
+		Warnings:          d.Warnings,
+	}
+	if d.Labels != (Labels{}) {
+		labels := d.Labels
+		out.Labels = &labels
+	}
+	for _, entry := range d.Entries {
+		out.Entries = append(out.Entries, newEntryJSON(entry))
+		if entry.Noised {
+			out.Noise = append(out.Noise, entry.Path)
+		}
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,58 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffMarshalJSON(t *testing.T) {
+	json1 := `{"name":"Cat","ts":1,"tags":["a"],"gone":true}`
+	json2 := `{"name":"Dog","ts":2,"tags":["a","b"]}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), map[string][]string{"ts": {}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"version":1,"entries":[` +
+		`{"path":"gone","pointer":"/gone","op":"removed","expected":true},` +
+		`{"path":"name","pointer":"/name","op":"changed","expected":"Cat","actual":"Dog"},` +
+		`{"path":"tags.1","pointer":"/tags/1","op":"added","actual":"b"},` +
+		`{"path":"ts","pointer":"/ts","op":"changed","expected":1,"actual":2,"noised":true}],` +
+		`"noise":["ts"],` +
+		`"stats":{"added":1,"removed":1,"changed":1,"noised":1,"total":3}}`
+	if string(got) != expected {
+		t.Errorf("unexpected serialization\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestDiffMarshalJSONArrayMappings(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"tags":["a","b","c"]}`), []byte(`{"tags":["c","a"]}`), nil, true, WithUnorderedArrays("tags"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":1,"entries":[{"path":"tags.2","pointer":"/tags/2","op":"removed","expected":"b","note":"elements matched regardless of order"}],` +
+		`"noise":[],` +
+		`"stats":{"added":0,"removed":1,"changed":0,"noised":0,"total":1},` +
+		`"arrayMappings":[{"path":"tags","pairs":[{"expected":0,"actual":1},{"expected":2,"actual":0},{"expected":1,"actual":-1}]}]}`
+	if string(got) != expected {
+		t.Errorf("unexpected serialization\n got: %s\nwant: %s", got, expected)
+	}
+
+	var restored Diff
+	if err := json.Unmarshal(got, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.ArrayMappings, resp.ArrayMappings) {
+		t.Errorf("unexpected restored mappings %+v", restored.ArrayMappings)
+	}
+}
@@ -0,0 +1,164 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// entryOverhead approximates the bytes taken by a DiffEntry apart from its path and values.
+const entryOverhead = 200
+
+// WithMemoryBudget caps the approximate memory taken by the entries of the result at the given number of bytes, so
+// comparisons of huge documents with many differences do not balloon the memory of the process. Once the budget is
+// spent, further differences are no longer recorded one by one but only counted per top-level subtree, in the
+// Spilled summaries of the diff. Values holding no recorded difference are left out of the colorized output, which
+// ends with a line per summarized subtree. The summarized differences still count towards the stats, and the OnDiff
+// hook still sees every difference.
+func WithMemoryBudget(bytes int) Option {
+	return func(o *options) {
+		o.memoryBudget = bytes
+	}
+}
+
+// SpillSummary counts the differences of a subtree that were not recorded because the memory budget given with
+// WithMemoryBudget was spent.
+// Path: The gjson-style path of the top-level subtree, "" for the root.
+// Differences: The number of summarized differences that are not noised.
+// Noised: The number of summarized differences suppressed by noise rules.
+type SpillSummary struct {
+	Path        string `json:"path"`
+	Differences int    `json:"differences"`
+	Noised      int    `json:"noised,omitempty"`
+}
+
+// String describes the summary, e.g. `12 differences in "items" not shown (memory budget exceeded)`.
+func (s SpillSummary) String() string {
+	unit := "differences"
+	if s.Differences == 1 {
+		unit = "difference"
+	}
+	text := fmt.Sprintf("%d %s in %s not shown", s.Differences, unit, quotePath(s.Path))
+	if s.Noised > 0 {
+		text += fmt.Sprintf(", %d noised", s.Noised)
+	}
+	return text + " (memory budget exceeded)"
+}
+
+// memoryBudget tracks the approximate memory taken by the recorded entries and summarizes the entries recorded
+// after the budget is spent.
+type memoryBudget struct {
+	remaining int            // remaining is the number of bytes left, meaningless without a budget.
+	limited   bool           // limited tells whether a budget was given.
+	spilled   []SpillSummary // spilled are the summaries of the entries not recorded, in order of appearance.
+	index     map[string]int // index maps the paths of the subtrees to their summaries.
+}
+
+// newMemoryBudget returns the budget given with WithMemoryBudget, or an unlimited one.
+func (o *options) newMemoryBudget() *memoryBudget {
+	return &memoryBudget{remaining: o.memoryBudget, limited: o.memoryBudget > 0}
+}
+
+// admit reports whether the entry fits within the budget and charges it if so. Once an entry does not fit, no
+// further entry is admitted; the entries turned away are counted by their top-level subtree.
+func (b *memoryBudget) admit(entry DiffEntry) bool {
+	if !b.limited {
+		return true
+	}
+	if b.spilled == nil {
+		if size := entrySize(entry); size <= b.remaining {
+			b.remaining -= size
+			return true
+		}
+		b.index = map[string]int{}
+	}
+	var subtree string
+	if components := splitPath(entry.Path); len(components) > 0 {
+		subtree = escapePathKey(components[0])
+	}
+	i, ok := b.index[subtree]
+	if !ok {
+		i = len(b.spilled)
+		b.index[subtree] = i
+		b.spilled = append(b.spilled, SpillSummary{Path: subtree})
+	}
+	if entry.Noised {
+		b.spilled[i].Noised++
+	} else {
+		b.spilled[i].Differences++
+	}
+	return false
+}
+
+// entrySize approximates the bytes taken by an entry.
+func entrySize(entry DiffEntry) int {
+	return entryOverhead + len(entry.Path) + len(entry.Note) + len(entry.Violation) + len(entry.Severity) +
+		len(entry.NoiseReason) + len(entry.Waiver) + len(entry.Delta) + valueSize(entry.Expected) + valueSize(entry.Actual)
+}
+
+// valueSize approximates the bytes taken by a decoded JSON value.
+func valueSize(value interface{}) int {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		size := 48
+		for key, member := range v {
+			size += 32 + len(key) + valueSize(member)
+		}
+		return size
+	case []interface{}:
+		size := 24
+		for _, element := range v {
+			size += 16 + valueSize(element)
+		}
+		return size
+	case string:
+		return 16 + len(v)
+	case nil:
+		return 0
+	}
+	return 8
+}
+
+// withRecorded returns the tree without the leaves that hold no recorded entry, or nil if none are left.
+func (n *diffNode) withRecorded(entries []DiffEntry) *diffNode {
+	recorded := map[string]bool{}
+	for _, entry := range entries {
+		path := ""
+		recorded[path] = true
+		for _, component := range splitPath(entry.Path) {
+			path = joinPath(path, escapePathKey(component))
+			recorded[path] = true
+		}
+	}
+	var prune func(n *diffNode) *diffNode
+	prune = func(n *diffNode) *diffNode {
+		if n == nil || !recorded[n.path] {
+			return nil
+		}
+		if len(n.children) == 0 {
+			return n
+		}
+		pruned := *n
+		pruned.children = nil
+		for _, child := range n.children {
+			if child = prune(child); child != nil {
+				pruned.children = append(pruned.children, child)
+			}
+		}
+		if len(pruned.children) == 0 {
+			return nil
+		}
+		return &pruned
+	}
+	return prune(n)
+}
+
+// renderSpilled renders the colored lines summarizing the differences left out because of the memory budget.
+func renderSpilled(spilled []SpillSummary, p palette) string {
+	var builder strings.Builder
+	for _, summary := range spilled {
+		builder.WriteString(breakLines(p.sprintFunc(color.FgYellow)(summary.String())) + "\n")
+	}
+	return builder.String()
+}
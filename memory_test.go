@@ -0,0 +1,48 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithMemoryBudget(t *testing.T) {
+	expected := []byte(`{"a":1,"items":[1,2,3,4],"meta":{"x":1,"ts":1}}`)
+	actual := []byte(`{"a":2,"items":[5,6,7,8],"meta":{"x":2,"ts":2}}`)
+	noise := map[string][]string{"meta.ts": {}}
+
+	resp, err := CompareJSON(expected, actual, noise, true, WithMemoryBudget(2*entryOverhead+50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := []string{"a", "items.0"}; len(resp.Entries) != 2 || resp.Entries[0].Path != paths[0] || resp.Entries[1].Path != paths[1] {
+		t.Fatalf("expected the entries %v within the budget, got %+v", paths, resp.Entries)
+	}
+	want := []SpillSummary{{Path: "items", Differences: 3}, {Path: "meta", Differences: 1, Noised: 1}}
+	if !reflect.DeepEqual(resp.Spilled, want) {
+		t.Errorf("got spilled %+v, want %+v", resp.Spilled, want)
+	}
+	stats := resp.Stats()
+	if stats.Total != 6 || stats.Spilled != 4 || stats.Noised != 1 {
+		t.Errorf("expected the summarized differences in the stats, got %+v", stats)
+	}
+	output := strings.ReplaceAll(resp.Actual, "\n", "")
+	if !strings.Contains(output, `3 differences in "items" not shown (memory budget exceeded)`) {
+		t.Errorf("expected a summary of the items, got:\n%s", resp.Actual)
+	}
+	if strings.Contains(output, `"x"`) {
+		t.Errorf("expected the summarized subtree to be left out, got:\n%s", resp.Actual)
+	}
+}
+
+func TestWithMemoryBudgetOnDiff(t *testing.T) {
+	var seen int
+	resp, err := CompareJSON([]byte(`{"a":1,"b":1}`), []byte(`{"a":2,"b":2}`), nil, true,
+		WithMemoryBudget(1), WithOnDiff(func(DiffEntry) { seen++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != 2 || len(resp.Entries) != 0 || resp.Stats().Total != 2 {
+		t.Errorf("expected the hook to see every difference and the stats to count them, got %d, %+v", seen, resp.Stats())
+	}
+}
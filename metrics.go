@@ -0,0 +1,47 @@
+package colorisediff
+
+import "time"
+
+// Metrics receives statistics about the comparisons run with WithMetrics. Its methods map onto Prometheus-style
+// instruments: counters for the comparisons, differences, noise suppressions and bytes, and a histogram for the
+// duration. Implementations must be safe for concurrent use when comparisons run concurrently.
+type Metrics interface {
+	// IncComparisons counts a comparison, including one that failed.
+	IncComparisons()
+	// AddDiffs counts the differences found that are not noise.
+	AddDiffs(n int)
+	// AddNoiseSuppressions counts the differences suppressed as noise.
+	AddNoiseSuppressions(n int)
+	// AddBytes counts the bytes of the compared inputs.
+	AddBytes(n int)
+	// ObserveDuration records how long a comparison took.
+	ObserveDuration(d time.Duration)
+}
+
+// WithMetrics reports statistics about every comparison to m, so services embedding the comparator can monitor it,
+// e.g. by backing m with Prometheus counters and a histogram.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// recordMetrics reports a finished comparison to the configured metrics, if any.
+func (o *options) recordMetrics(size int, entries []DiffEntry, elapsed time.Duration) {
+	if o.metrics == nil {
+		return
+	}
+	diffs, noised := 0, 0
+	for _, entry := range entries {
+		if entry.Noised {
+			noised++
+		} else {
+			diffs++
+		}
+	}
+	o.metrics.IncComparisons()
+	o.metrics.AddDiffs(diffs)
+	o.metrics.AddNoiseSuppressions(noised)
+	o.metrics.AddBytes(size)
+	o.metrics.ObserveDuration(elapsed)
+}
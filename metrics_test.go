@@ -0,0 +1,37 @@
+package colorisediff
+
+import (
+	"testing"
+	"time"
+)
+
+// recordingMetrics sums the reported statistics.
+type recordingMetrics struct {
+	comparisons, diffs, noised, bytes, durations int
+}
+
+func (m *recordingMetrics) IncComparisons()               { m.comparisons++ }
+func (m *recordingMetrics) AddDiffs(n int)                { m.diffs += n }
+func (m *recordingMetrics) AddNoiseSuppressions(n int)    { m.noised += n }
+func (m *recordingMetrics) AddBytes(n int)                { m.bytes += n }
+func (m *recordingMetrics) ObserveDuration(time.Duration) { m.durations++ }
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	inputs := [][2]string{
+		{`{"id":1,"name":"Cat","age":2}`, `{"id":2,"name":"Dog","age":3}`},
+		{`{"id":1}`, `{"id":1}`},
+		{`{"id":`, `{}`},
+	}
+	for _, input := range inputs {
+		CompareJSON([]byte(input[0]), []byte(input[1]), map[string][]string{"id": {}}, true, WithMetrics(m))
+	}
+
+	want := recordingMetrics{comparisons: 3, diffs: 2, noised: 1, durations: 3}
+	for _, input := range inputs {
+		want.bytes += len(input[0]) + len(input[1])
+	}
+	if *m != want {
+		t.Errorf("recorded %+v, want %+v", *m, want)
+	}
+}
@@ -0,0 +1,97 @@
+package colorisediff
+
+import (
+	"strconv"
+	"time"
+)
+
+// WithMongoExtendedJSON makes the comparison unwrap MongoDB Extended JSON values ({"$oid": ...},
+// {"$date": ...}, {"$numberLong": ...} and friends) into plain values before comparing, so diffs are not dominated
+// by wrapper objects. With ignoreIDsAndDates set, ObjectIds, dates and timestamps present on both sides are treated
+// as equal.
+func WithMongoExtendedJSON(ignoreIDsAndDates bool) Option {
+	return func(o *options) {
+		o.mongoExtendedJSON = true
+		o.mongoIgnoreVolatile = ignoreIDsAndDates
+	}
+}
+
+// unwrapExtendedJSON returns a normalizer replacing MongoDB Extended JSON wrappers with plain values. ObjectIds,
+// dates and timestamps are marked volatile when ignoreVolatile is set.
+func unwrapExtendedJSON(ignoreVolatile bool) documentNormalizer {
+	return func(_ string, value interface{}) (interface{}, bool) {
+		wrapper, ok := value.(map[string]interface{})
+		if !ok || len(wrapper) != 1 {
+			return value, false
+		}
+		for key, inner := range wrapper {
+			switch key {
+			case "$oid", "$uuid", "$symbol":
+				if s, ok := inner.(string); ok {
+					return s, ignoreVolatile && key == "$oid"
+				}
+			case "$date":
+				if date, ok := extendedJSONDate(inner); ok {
+					return date, ignoreVolatile
+				}
+			case "$timestamp":
+				if fields, ok := inner.(map[string]interface{}); ok {
+					if seconds, ok := extendedJSONInt(fields["t"]); ok {
+						return formatTimestamp(seconds, 0), ignoreVolatile
+					}
+				}
+			case "$numberLong", "$numberInt":
+				if s, ok := inner.(string); ok {
+					if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+						return n, false
+					}
+				}
+			case "$numberDouble":
+				if s, ok := inner.(string); ok {
+					if f, err := strconv.ParseFloat(s, 64); err == nil && s != "NaN" && s != "Infinity" && s != "-Infinity" {
+						return f, false
+					}
+					return s, false
+				}
+			case "$numberDecimal":
+				if s, ok := inner.(string); ok {
+					return s, false
+				}
+			case "$binary":
+				if fields, ok := inner.(map[string]interface{}); ok {
+					if data, ok := fields["base64"].(string); ok {
+						return data, false
+					}
+				}
+			}
+		}
+		return value, false
+	}
+}
+
+// extendedJSONDate converts the content of a "$date" wrapper, an ISO-8601 string or milliseconds since the epoch,
+// into an RFC 3339 string in UTC.
+func extendedJSONDate(inner interface{}) (string, bool) {
+	if s, ok := inner.(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t.UTC().Format(time.RFC3339Nano), true
+		}
+		return s, true
+	}
+	millis, ok := extendedJSONInt(inner)
+	if !ok {
+		return "", false
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339Nano), true
+}
+
+// extendedJSONInt converts a decoded JSON or already unwrapped number into an int64.
+func extendedJSONInt(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
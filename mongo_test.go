@@ -0,0 +1,42 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMongoExtendedJSON(t *testing.T) {
+	json1 := `{"_id":{"$oid":"5f1d7a"},"createdAt":{"$date":{"$numberLong":"0"}},"count":{"$numberLong":"41"},"price":{"$numberDouble":"1.5"}}`
+	json2 := `{"_id":{"$oid":"6a2e8b"},"createdAt":{"$date":"1970-01-01T00:00:01Z"},"count":{"$numberLong":"42"},"price":{"$numberDouble":"1.5"}}`
+
+	tests := []struct {
+		name   string
+		ignore bool
+		want   []DiffEntry
+	}{
+		{
+			name: "unwrapped values",
+			want: []DiffEntry{
+				{Path: "_id", Op: OpChanged, Expected: "5f1d7a", Actual: "6a2e8b"},
+				{Path: "count", Op: OpChanged, Expected: float64(41), Actual: float64(42)},
+				{Path: "createdAt", Op: OpChanged, Expected: "1970-01-01T00:00:00Z", Actual: "1970-01-01T00:00:01Z"},
+			},
+		},
+		{
+			name:   "ids and dates ignored",
+			ignore: true,
+			want:   []DiffEntry{{Path: "count", Op: OpChanged, Expected: float64(41), Actual: float64(42)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithMongoExtendedJSON(tt.ignore))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Entries, tt.want) {
+				t.Errorf("unexpected entries %+v, want %+v", resp.Entries, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package colorisediff
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxDecodeDepth bounds the nesting of binary documents to protect the decoders from malicious input.
+const maxDecodeDepth = 1000
+
+// errUnexpectedEOF is returned when a binary document ends in the middle of a value.
+var errUnexpectedEOF = errors.New("unexpected end of input")
+
+// CompareMsgpack compares two MessagePack documents and returns the colorized differences.
+// The documents are decoded into JSON-compatible values and compared like CompareJSON would: map keys that are
+// not strings are converted with fmt, binary values become base64 strings, timestamps become RFC 3339 strings and
+// other extension values become objects with "type" and "data" fields.
+func CompareMsgpack(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	expectedValue, err := decodeMsgpack(expected)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding expected MessagePack: %w", err)
+	}
+	actualValue, err := decodeMsgpack(actual)
+	if err != nil {
+		return Diff{}, fmt.Errorf("decoding actual MessagePack: %w", err)
+	}
+	return compareDecoded(expectedValue, actualValue, noise, disableColor, opts)
+}
+
+// decodeMsgpack decodes a single MessagePack value spanning the whole input.
+func decodeMsgpack(data []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%d trailing bytes after value", len(d.data)-d.pos)
+	}
+	return value, nil
+}
+
+// msgpackDecoder decodes MessagePack values from a byte slice.
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+// next consumes and returns the next n bytes.
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of n bytes.
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// decode decodes the next value.
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		raw, err := d.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(int(n))
+	case 0xca:
+		v, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(v))), nil
+	case 0xcb:
+		v, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(v), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n), depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n), depth)
+	}
+	return nil, fmt.Errorf("invalid MessagePack type byte 0x%02x at offset %d", c, d.pos-1)
+}
+
+// decodeString decodes a string of n bytes.
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// decodeArray decodes an array of n elements.
+func (d *msgpackDecoder) decodeArray(n int, depth int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errUnexpectedEOF
+	}
+	array := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+// decodeMap decodes a map of n key-value pairs.
+func (d *msgpackDecoder) decodeMap(n int, depth int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errUnexpectedEOF
+	}
+	object := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		object[mapKeyString(key)] = value
+	}
+	return object, nil
+}
+
+// decodeExt decodes an extension value with n data bytes. The timestamp extension (-1) becomes an RFC 3339 string.
+func (d *msgpackDecoder) decodeExt(n int) (interface{}, error) {
+	typeByte, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	extType := int8(typeByte[0])
+	if extType == -1 {
+		switch n {
+		case 4:
+			return formatTimestamp(int64(binary.BigEndian.Uint32(data)), 0), nil
+		case 8:
+			v := binary.BigEndian.Uint64(data)
+			return formatTimestamp(int64(v&0x3ffffffff), int64(v>>34)), nil
+		case 12:
+			return formatTimestamp(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))), nil
+		}
+	}
+	return map[string]interface{}{"type": int64(extType), "data": base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// formatTimestamp formats seconds and nanoseconds since the Unix epoch as an RFC 3339 string in UTC.
+func formatTimestamp(seconds, nanoseconds int64) string {
+	return time.Unix(seconds, nanoseconds).UTC().Format(time.RFC3339Nano)
+}
+
+// mapKeyString converts a decoded map key into the string used as a JSON object key.
+func mapKeyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
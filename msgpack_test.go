@@ -0,0 +1,61 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareMsgpack(t *testing.T) {
+	// {"name":"Cat","age":3} and {"name":"Dog","age":3}
+	expected := []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'C', 'a', 't', 0xa3, 'a', 'g', 'e', 0x03}
+	actual := []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'D', 'o', 'g', 0xa3, 'a', 'g', 'e', 0x03}
+
+	resp, err := CompareMsgpack(expected, actual, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	if _, err := CompareMsgpack(expected[:5], actual, nil, true); err == nil {
+		t.Error("expected an error for truncated input")
+	}
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected interface{}
+	}{
+		{name: "nil", input: []byte{0xc0}, expected: nil},
+		{name: "negative fixint", input: []byte{0xff}, expected: int64(-1)},
+		{name: "uint16", input: []byte{0xcd, 0x01, 0x00}, expected: uint64(256)},
+		{name: "int8", input: []byte{0xd0, 0x80}, expected: int64(-128)},
+		{name: "float64", input: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, expected: 1.5},
+		{name: "array16", input: []byte{0xdc, 0x00, 0x02, 0xc3, 0xc2}, expected: []interface{}{true, false}},
+		{name: "bin8", input: []byte{0xc4, 0x02, 0x01, 0x02}, expected: "AQI="},
+		{name: "integer map key", input: []byte{0x81, 0x01, 0xa1, 'x'}, expected: map[string]interface{}{"1": "x"}},
+		{name: "timestamp32", input: []byte{0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c}, expected: "1970-01-01T00:01:00Z"},
+		{name: "other ext", input: []byte{0xd4, 0x05, 0x07}, expected: map[string]interface{}{"type": int64(5), "data": "Bw=="}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeMsgpack(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("decodeMsgpack(%x) = %#v, want %#v", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range [][]byte{{0xc1}, {0xc0, 0xc0}, {0xdd, 0xff, 0xff, 0xff, 0xff}} {
+		if _, err := decodeMsgpack(input); err == nil {
+			t.Errorf("expected an error decoding %x", input)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package colorisediff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// WithNoiseReasons adds noise rules carrying a human-readable reason, e.g. {"ts": "server clock"}, on top of the
+// noise passed to the comparison. Noised entries get the reason of the first matching rule, in sorted order, in
+// DiffEntry.NoiseReason. With render set, noised fields are shown dimmed with their reason in the colorized output
+// instead of being left out, so reviewers see why they are ignored.
+func WithNoiseReasons(reasons map[string]string, render bool) Option {
+	return func(o *options) {
+		if o.noiseReasons == nil {
+			o.noiseReasons = map[string]string{}
+		}
+		for key, reason := range reasons {
+			o.noiseReasons[key] = reason
+			o.defaultNoise = append(o.defaultNoise, key)
+		}
+		o.renderNoiseReasons = o.renderNoiseReasons || render
+	}
+}
+
+// explainNoise sets the reason of a noised entry matched by a rule given with WithNoiseReasons.
+func (o *options) explainNoise(entry DiffEntry) DiffEntry {
+	if entry.Noised && len(o.noiseReasons) > 0 {
+		entry.NoiseReason = noiseReason(pathToLegacy(entry.Path), o.noiseReasons)
+	}
+	return entry
+}
+
+// noiseReason returns the reason of the first rule, in sorted order, matching a path in the ".key[0]" notation
+// the way checkNoise matches it.
+func noiseReason(legacyPath string, reasons map[string]string) string {
+	for _, rule := range sortedKeys(reasons) {
+		if checkNoise(legacyPath, map[string][]string{rule: nil}) {
+			return reasons[rule]
+		}
+	}
+	return ""
+}
+
+// pathToLegacy converts a gjson-style path into the colorizer's ".key[0]" notation understood by checkNoise.
+// Numeric components are taken to be array indices.
+func pathToLegacy(path string) string {
+	var builder strings.Builder
+	for _, component := range splitPath(path) {
+		if _, err := strconv.Atoi(component); err == nil {
+			builder.WriteString("[" + component + "]")
+			continue
+		}
+		builder.WriteString("." + component)
+	}
+	return builder.String()
+}
+
+// renderNoisedLeaf renders a noised difference dimmed, with the reason of its rule, on the sides where it has a
+// value.
+func renderNoisedLeaf(leaf *diffNode, reasons map[string]string, renderers valueRenderers, p palette) (string, string) {
+	dim := p.sprintFunc(color.Faint)
+	reason := noiseReason(pathToLegacy(leaf.path), reasons)
+	if reason == "" {
+		reason = "noise"
+	}
+	line := func(value interface{}) string {
+		text := fmt.Sprintf(" %s: %s  # %s", quotePath(leaf.path), renderers.format(leaf.path, value), reason)
+		return breakWithColor(text, dim, []colorRange{{Start: 0, End: len(text)}})
+	}
+	var expect, actual string
+	if leaf.op != OpAdded {
+		expect = line(leaf.expected)
+	}
+	if leaf.op != OpRemoved {
+		actual = line(leaf.actual)
+	}
+	return expect, actual
+}
+
+// WithHideNoise leaves noised fields out of the colorized output entirely instead of showing them uncolored. They
+// are still listed in the entries and counted in the stats.
+func WithHideNoise() Option {
+	return func(o *options) {
+		o.hideNoise = true
+	}
+}
+
+// noiseDisplay controls how the colorizer shows noised values.
+type noiseDisplay struct {
+	hide    bool              // hide leaves noised values out.
+	reasons map[string]string // reasons shows noised values dimmed with the reasons of their rules, if not nil.
+}
+
+// noiseDisplay returns how noised values are shown according to the options.
+func (o *options) noiseDisplay() noiseDisplay {
+	display := noiseDisplay{hide: o.hideNoise}
+	if o.renderNoiseReasons && !o.hideNoise {
+		display.reasons = o.noiseReasons
+		if display.reasons == nil {
+			display.reasons = map[string]string{}
+		}
+	}
+	return display
+}
+
+// WithNoise adds noise rules to a comparison. It is how CompareHeaders takes noise, and lets the same rules be
+// shared between bodies and headers: keys prefixed with "header." name headers, compared case-insensitively, and
+// all other keys, optionally prefixed with "body.", name body paths. E.g. {"header.Date": {}, "body.ts": {}}
+// ignores the Date header in CompareHeaders and the ts field in CompareJSON. Body rules containing a "*" are path
+// patterns ignoring the paths at and below the ones they match, e.g. "**._score" for every "_score" key; other body
+// rules match anywhere in a path.
+func WithNoise(noise map[string][]string) Option {
+	return func(o *options) {
+		if o.noise == nil {
+			o.noise = make(map[string][]string, len(noise))
+		}
+		for key, values := range noise {
+			o.noise[key] = values
+		}
+	}
+}
+
+// splitNoise splits noise rules into the rules for bodies and the rules for headers, by the "body." and "header."
+// prefixes of their keys. Header names are lower-cased; keys without a prefix are body rules.
+func splitNoise(noise map[string][]string) (body, header map[string][]string) {
+	for key, values := range noise {
+		if name, ok := strings.CutPrefix(key, "header."); ok {
+			if header == nil {
+				header = map[string][]string{}
+			}
+			header[strings.ToLower(name)] = values
+			continue
+		}
+		if body == nil {
+			body = make(map[string][]string, len(noise))
+		}
+		body[strings.TrimPrefix(key, "body.")] = values
+	}
+	return body, header
+}
+
+// headerEntries returns an entry for every header whose value differs, keyed by header name. Headers with a parser
+// registered with WithHeaderParser get an entry for every differing part of their parsed values instead.
+func (o *options) headerEntries(expected, actual map[string]string, noise map[string][]string) []DiffEntry {
+	var entries []DiffEntry
+	for _, name := range headerNames(expected, actual) {
+		expectedValue, inExpected := expected[name]
+		actualValue, inActual := actual[name]
+		_, noised := noise[strings.ToLower(name)]
+		switch {
+		case !inActual:
+			entries = append(entries, DiffEntry{Path: escapePathKey(name), Op: OpRemoved, Expected: expectedValue, Noised: noised})
+		case !inExpected:
+			entries = append(entries, DiffEntry{Path: escapePathKey(name), Op: OpAdded, Actual: actualValue, Noised: noised})
+		case expectedValue != actualValue:
+			e, a, parsed := o.parseHeaderPair(name, expectedValue, actualValue)
+			if !parsed {
+				entries = append(entries, DiffEntry{Path: escapePathKey(name), Op: OpChanged, Expected: expectedValue, Actual: actualValue, Noised: noised})
+				continue
+			}
+			for _, entry := range diffValues(escapePathKey(name), "", e, a, nil) {
+				entry.Noised = noised
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// headerNames returns the header names of both maps in sorted order without duplicates.
+func headerNames(expected, actual map[string]string) []string {
+	names := sortedKeys(expected)
+	for _, name := range sortedKeys(actual) {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,132 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithNoiseReasons(t *testing.T) {
+	json1 := `{"id":1,"ts":"10:00","name":"Cat"}`
+	json2 := `{"id":1,"ts":"10:05","name":"Dog"}`
+	reasons := map[string]string{"ts": "server clock"}
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithNoiseReasons(reasons, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := resp.At("ts")
+	if entry == nil || !entry.Noised || entry.NoiseReason != "server clock" {
+		t.Fatalf("expected a noised entry with its reason, got %+v", entry)
+	}
+	if resp.At("name").NoiseReason != "" {
+		t.Errorf("expected no reason for a difference that is not noised")
+	}
+	if strings.Contains(resp.Expected, "ts") {
+		t.Errorf("expected the noised field to be left out, got:\n%s", resp.Expected)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true, WithNoiseReasons(reasons, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Expected, `"ts": "10:00"  # server clock`) || !strings.Contains(resp.Actual, `"ts": "10:05"  # server clock`) {
+		t.Errorf("expected the noised field with its reason, got:\n%s\n%s", resp.Expected, resp.Actual)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), map[string][]string{"ts": {}}, true, WithNoiseReasons(map[string]string{"name": "renamed"}, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Expected, `"ts": "10:00"  # noise`) || !strings.Contains(resp.Expected, `"name": "Cat"  # renamed`) {
+		t.Errorf("expected both noised fields, got:\n%s", resp.Expected)
+	}
+}
+
+func TestPathToLegacy(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: ""},
+		{path: "a", want: ".a"},
+		{path: "a.0.b", want: ".a[0].b"},
+		{path: "0.1", want: "[0][1]"},
+		{path: `a\.b.c`, want: ".a.b.c"},
+	}
+	for _, tt := range tests {
+		if got := pathToLegacy(tt.path); got != tt.want {
+			t.Errorf("pathToLegacy(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWithHideNoise(t *testing.T) {
+	json1 := `{"id":1,"ts":"10:00","tags":["a","b"],"name":"Cat"}`
+	json2 := `{"id":1,"tags":["a","c","d"],"name":"Dog"}`
+	noise := map[string][]string{"ts": {}, "tags[1]": {}, "tags[2]": {}}
+
+	shown, err := CompareJSON([]byte(json1), []byte(json2), noise, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(shown.Expected, `"ts"`) || !strings.Contains(shown.Actual, `"d"`) {
+		t.Errorf("expected noised fields to be shown by default, got:\n%s\n%s", shown.Expected, shown.Actual)
+	}
+
+	hidden, err := CompareJSON([]byte(json1), []byte(json2), noise, true, WithHideNoise())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{`"ts"`, `"b"`, `"c"`, `"d"`} {
+		if strings.Contains(hidden.Expected+hidden.Actual, text) {
+			t.Errorf("expected %s to be hidden, got:\n%s\n%s", text, hidden.Expected, hidden.Actual)
+		}
+	}
+	if !strings.Contains(hidden.Expected, `"Cat"`) {
+		t.Errorf("expected the remaining differences, got:\n%s", hidden.Expected)
+	}
+	if stats := hidden.Stats(); stats.Noised != 3 || stats.Total != 1 {
+		t.Errorf("expected the noised fields to be counted, got %+v", stats)
+	}
+}
+
+func TestWithNoiseSharedByHeadersAndBody(t *testing.T) {
+	noise := map[string][]string{"header.Date": {}, "body.ts": {}}
+
+	headers := CompareHeaders(
+		map[string]string{"Date": "Mon", "Etag": "a", "Vary": "Origin"},
+		map[string]string{"Date": "Tue", "Etag": "b", "Server": "x"},
+		WithNoise(noise),
+	)
+	if strings.Contains(headers.Expected, "Date") {
+		t.Errorf("expected the noised header to be left out, got:\n%s", headers.Expected)
+	}
+	want := []DiffEntry{
+		{Path: "Date", Op: OpChanged, Expected: "Mon", Actual: "Tue", Noised: true},
+		{Path: "Etag", Op: OpChanged, Expected: "a", Actual: "b"},
+		{Path: "Server", Op: OpAdded, Actual: "x"},
+		{Path: "Vary", Op: OpRemoved, Expected: "Origin"},
+	}
+	if !reflect.DeepEqual(headers.Entries, want) {
+		t.Errorf("unexpected header entries %+v", headers.Entries)
+	}
+
+	body, err := CompareJSON([]byte(`{"ts":1,"Date":1}`), []byte(`{"ts":2,"Date":2}`), nil, true, WithNoise(noise))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !body.At("ts").Noised || body.At("Date").Noised {
+		t.Errorf("expected only the body rule to apply to the body, got %+v", body.Entries)
+	}
+}
+
+func TestSplitNoise(t *testing.T) {
+	body, header := splitNoise(map[string][]string{"header.X-Id": {}, "body.a.b": {"x"}, "c": {}})
+	if !reflect.DeepEqual(body, map[string][]string{"a.b": {"x"}, "c": {}}) {
+		t.Errorf("unexpected body noise %v", body)
+	}
+	if !reflect.DeepEqual(header, map[string][]string{"x-id": {}}) {
+		t.Errorf("unexpected header noise %v", header)
+	}
+}
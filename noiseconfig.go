@@ -0,0 +1,187 @@
+package colorisediff
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NoiseConfig holds the noise rules of a keploy noise configuration, converted into the noise maps taken by the
+// comparisons.
+// Body: The noise for the bodies, keyed by path as understood by CompareJSON, e.g. "user.ts".
+// Header: The noise for the headers, keyed by lower-case header name.
+// TestSets: The noise configured for individual test sets, by name, on top of the global noise.
+type NoiseConfig struct {
+	Body     map[string][]string
+	Header   map[string][]string
+	TestSets map[string]NoiseConfig
+}
+
+// LoadNoiseConfig reads a keploy noise configuration from a YAML or JSON file. See ParseNoiseConfig for the
+// supported layouts.
+func LoadNoiseConfig(path string) (NoiseConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NoiseConfig{}, err
+	}
+	config, err := ParseNoiseConfig(data)
+	if err != nil {
+		return NoiseConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseNoiseConfig parses a keploy noise configuration in YAML or JSON. It accepts the "test.globalNoise" section of
+// keploy.yml, with its "global" and "test-sets" noise, the "globalNoise" section on its own, a "noise" map of a test
+// case keyed by "header.<name>" and "body.<path>", and a bare section with "header" and "body" maps. The values of
+// the rules are lists of strings, e.g. {"body": {"ts": []}}.
+func ParseNoiseConfig(data []byte) (NoiseConfig, error) {
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return NoiseConfig{}, fmt.Errorf("decoding noise config: %w", err)
+	}
+	if test, ok := root["test"].(map[string]interface{}); ok {
+		// A keploy.yml holds the noise next to the other test settings, if at all.
+		globalNoise, _ := test["globalNoise"].(map[string]interface{})
+		root = globalNoise
+	} else if globalNoise, ok := root["globalNoise"].(map[string]interface{}); ok {
+		root = globalNoise
+	}
+
+	if flat, ok := root["noise"].(map[string]interface{}); ok {
+		return parseFlatNoise(flat)
+	}
+	if _, ok := root["global"]; !ok {
+		if _, ok := root["test-sets"]; !ok {
+			return parseNoiseSection(root)
+		}
+	}
+
+	var config NoiseConfig
+	if global, ok := root["global"].(map[string]interface{}); ok {
+		section, err := parseNoiseSection(global)
+		if err != nil {
+			return NoiseConfig{}, fmt.Errorf("global: %w", err)
+		}
+		config = section
+	}
+	if testSets, ok := root["test-sets"].(map[string]interface{}); ok {
+		config.TestSets = make(map[string]NoiseConfig, len(testSets))
+		for name, value := range testSets {
+			section, _ := value.(map[string]interface{})
+			parsed, err := parseNoiseSection(section)
+			if err != nil {
+				return NoiseConfig{}, fmt.Errorf("test set %q: %w", name, err)
+			}
+			config.TestSets[name] = parsed
+		}
+	}
+	return config, nil
+}
+
+// ForTestSet returns the global noise combined with the noise of the named test set.
+func (c NoiseConfig) ForTestSet(name string) NoiseConfig {
+	set := c.TestSets[name]
+	return NoiseConfig{Body: mergeNoiseMaps(c.Body, set.Body), Header: mergeNoiseMaps(c.Header, set.Header)}
+}
+
+// Rules returns the body and header noise as a single map of noise rules, with the header names prefixed with
+// "header.", to be shared by CompareJSON and CompareHeaders through WithNoise.
+func (c NoiseConfig) Rules() map[string][]string {
+	rules := make(map[string][]string, len(c.Body)+len(c.Header))
+	for key, values := range c.Body {
+		rules[key] = values
+	}
+	for name, values := range c.Header {
+		rules["header."+name] = values
+	}
+	return rules
+}
+
+// parseNoiseSection parses a section holding "header" and "body" noise maps.
+func parseNoiseSection(section map[string]interface{}) (NoiseConfig, error) {
+	var config NoiseConfig
+	for key, value := range section {
+		rules, ok := value.(map[string]interface{})
+		if !ok && value != nil {
+			return NoiseConfig{}, fmt.Errorf("%s: expected a map of noise rules", key)
+		}
+		switch strings.ToLower(key) {
+		case "body":
+			if err := addNoiseRules(&config.Body, rules); err != nil {
+				return NoiseConfig{}, fmt.Errorf("body: %w", err)
+			}
+		case "header":
+			if err := addNoiseRules(&config.Header, rules); err != nil {
+				return NoiseConfig{}, fmt.Errorf("header: %w", err)
+			}
+		default:
+			return NoiseConfig{}, fmt.Errorf("unknown noise section %q", key)
+		}
+	}
+	return config, nil
+}
+
+// parseFlatNoise parses the noise map of a test case, whose keys are prefixed with "header." or "body.". Keys
+// without either prefix are taken to be body paths.
+func parseFlatNoise(flat map[string]interface{}) (NoiseConfig, error) {
+	body, header := map[string]interface{}{}, map[string]interface{}{}
+	for key, value := range flat {
+		if name, ok := strings.CutPrefix(key, "header."); ok {
+			header[name] = value
+		} else {
+			body[strings.TrimPrefix(key, "body.")] = value
+		}
+	}
+	var config NoiseConfig
+	if err := addNoiseRules(&config.Body, body); err != nil {
+		return NoiseConfig{}, fmt.Errorf("body: %w", err)
+	}
+	if err := addNoiseRules(&config.Header, header); err != nil {
+		return NoiseConfig{}, fmt.Errorf("header: %w", err)
+	}
+	return config, nil
+}
+
+// addNoiseRules adds decoded rules to a noise map, lower-casing the keys the way the noise is matched.
+func addNoiseRules(noise *map[string][]string, rules map[string]interface{}) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	if *noise == nil {
+		*noise = make(map[string][]string, len(rules))
+	}
+	for key, value := range rules {
+		values := []string{}
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range v {
+				values = append(values, fmt.Sprint(item))
+			}
+		case string:
+			values = append(values, v)
+		default:
+			return fmt.Errorf("%s: expected a list of values", key)
+		}
+		(*noise)[strings.ToLower(key)] = values
+	}
+	return nil
+}
+
+// mergeNoiseMaps returns a new noise map holding the rules of both maps, the second one taking precedence.
+func mergeNoiseMaps(base, extra map[string][]string) map[string][]string {
+	if len(base) == 0 && len(extra) == 0 {
+		return nil
+	}
+	merged := make(map[string][]string, len(base)+len(extra))
+	for key, values := range base {
+		merged[key] = values
+	}
+	for key, values := range extra {
+		merged[key] = values
+	}
+	return merged
+}
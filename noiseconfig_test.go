@@ -0,0 +1,118 @@
+package colorisediff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseNoiseConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   NoiseConfig
+		errors bool
+	}{
+		{
+			name: "keploy.yml",
+			input: `
+path: ""
+test:
+  delay: 5
+  globalNoise:
+    global:
+      header:
+        Date: []
+      body:
+        user.ts: ["^\\d+$"]
+    test-sets:
+      test-set-1:
+        body:
+          name: []
+`,
+			want: NoiseConfig{
+				Body:     map[string][]string{"user.ts": {`^\d+$`}},
+				Header:   map[string][]string{"date": {}},
+				TestSets: map[string]NoiseConfig{"test-set-1": {Body: map[string][]string{"name": {}}}},
+			},
+		},
+		{
+			name:  "keploy.yml without noise",
+			input: "test:\n  delay: 5\n",
+			want:  NoiseConfig{},
+		},
+		{
+			name:  "test case noise",
+			input: `{"noise": {"header.Date": [], "body.id": [], "ts": []}}`,
+			want: NoiseConfig{
+				Body:   map[string][]string{"id": {}, "ts": {}},
+				Header: map[string][]string{"date": {}},
+			},
+		},
+		{
+			name:  "bare section",
+			input: `{"body": {"a.b[0]": null}, "header": {"X-Request-Id": "uuid"}}`,
+			want: NoiseConfig{
+				Body:   map[string][]string{"a.b[0]": {}},
+				Header: map[string][]string{"x-request-id": {"uuid"}},
+			},
+		},
+		{name: "unknown section", input: `{"cookies": {}}`, errors: true},
+		{name: "invalid values", input: `{"body": {"ts": {"a": 1}}}`, errors: true},
+		{name: "invalid document", input: `{`, errors: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNoiseConfig([]byte(tt.input))
+			if tt.errors {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNoiseConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadNoiseConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "noise.yaml")
+	content := "globalNoise:\n  global:\n    body:\n      ts: []\n  test-sets:\n    set-1:\n      body:\n        id: []\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadNoiseConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"ts": {}, "id": {}}
+	if got := config.ForTestSet("set-1").Body; !reflect.DeepEqual(got, want) {
+		t.Errorf("ForTestSet() body = %v, want %v", got, want)
+	}
+
+	resp, err := CompareJSON([]byte(`{"id":1,"ts":2}`), []byte(`{"id":1,"ts":3}`), config.Body, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := resp.Stats(); stats.Total != 0 || stats.Noised != 1 {
+		t.Errorf("expected the loaded noise to apply, got %+v", stats)
+	}
+
+	if _, err := LoadNoiseConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestNoiseConfigRules(t *testing.T) {
+	config := NoiseConfig{Body: map[string][]string{"ts": {}}, Header: map[string][]string{"date": {}}}
+	want := map[string][]string{"ts": {}, "header.date": {}}
+	if got := config.Rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rules() = %v, want %v", got, want)
+	}
+}
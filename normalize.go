@@ -0,0 +1,107 @@
+package colorisediff
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// StringNormalizer rewrites a string value before it is compared, e.g. to remove formatting differences.
+type StringNormalizer func(s string) string
+
+// pathStringNormalizers holds string normalizers applying to the values at and below a path.
+type pathStringNormalizers struct {
+	path        string // path is the gjson-style path the normalizers apply to, "" for the whole document.
+	normalizers []StringNormalizer
+}
+
+// WithStringNormalizers makes the comparison treat two differing strings as equal if they are equal after applying
+// the normalizers in order, e.g. WithStringNormalizers(TrimWhitespace, FoldCase). Strings that still differ are
+// shown with their original content.
+func WithStringNormalizers(normalizers ...StringNormalizer) Option {
+	return WithPathStringNormalizers("", normalizers...)
+}
+
+// WithPathStringNormalizers is like WithStringNormalizers but only applies to the strings at or below the given
+// gjson-style path, e.g. "user.name". It can be given several times; normalizers of matching paths are combined.
+func WithPathStringNormalizers(path string, normalizers ...StringNormalizer) Option {
+	return func(o *options) {
+		o.stringNormalizers = append(o.stringNormalizers, pathStringNormalizers{path: o.pathPattern(path), normalizers: normalizers})
+	}
+}
+
+// TrimWhitespace removes leading and trailing white space.
+func TrimWhitespace(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// CollapseWhitespace replaces every run of white space with a single space and trims the string.
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// FoldCase applies Unicode case folding, so strings differing only in case compare equal.
+func FoldCase(s string) string {
+	return cases.Fold().String(s)
+}
+
+// NormalizeUnicode converts the string to Unicode Normalization Form C, so composed and decomposed forms of the
+// same characters compare equal.
+func NormalizeUnicode(s string) string {
+	return norm.NFC.String(s)
+}
+
+// WithEscapeNormalization makes the comparison treat strings as equal if they only differ in how characters are
+// escaped: HTML entities ("&amp;" vs "&") and JSON escapes left in the text ("\u003c" vs "<", "\/" vs "/"), as
+// produced by encoders escaping differently. It is a shorthand for WithStringNormalizers(UnescapeText).
+func WithEscapeNormalization() Option {
+	return WithStringNormalizers(UnescapeText)
+}
+
+// literalEscapeRegex matches "\uXXXX" and "\/" escape sequences left in decoded text.
+var literalEscapeRegex = regexp.MustCompile(`(?:\\u[0-9a-fA-F]{4})+|\\/`)
+
+// UnescapeText resolves HTML entities and the "\uXXXX" and "\/" JSON escapes found in the text.
+func UnescapeText(s string) string {
+	s = literalEscapeRegex.ReplaceAllStringFunc(s, func(escape string) string {
+		if escape == `\/` {
+			return "/"
+		}
+		units := make([]uint16, 0, len(escape)/6)
+		for i := 0; i+6 <= len(escape); i += 6 {
+			unit, _ := strconv.ParseUint(escape[i+2:i+6], 16, 16)
+			units = append(units, uint16(unit))
+		}
+		return string(utf16.Decode(units))
+	})
+	return html.UnescapeString(s)
+}
+
+// normalizeStringPair returns a transform replacing the actual string with the expected one when both are equal
+// after applying the normalizers configured for their path.
+func normalizeStringPair(rules []pathStringNormalizers) pairTransform {
+	return func(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		e, a, ok := differingStrings(expected, actual)
+		if !ok {
+			return nil, nil, "", false
+		}
+		applied := false
+		for _, rule := range rules {
+			if rule.path != "" && path != rule.path && !strings.HasPrefix(path, rule.path+".") {
+				continue
+			}
+			for _, normalize := range rule.normalizers {
+				e, a, applied = normalize(e), normalize(a), true
+			}
+		}
+		if !applied || e != a {
+			return nil, nil, "", false
+		}
+		return expected, expected, "", true
+	}
+}
@@ -0,0 +1,69 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringNormalizers(t *testing.T) {
+	expected := `{"name": " Ann  Lee ", "city": "Zürich", "code": "AB-1", "note": "Hello"}`
+	actual := `{"name": "Ann Lee", "city": "Zu\u0308rich", "code": "ab-1", "note": "HELLO"}`
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{name: "none", want: []string{"city", "code", "name", "note"}},
+		{name: "global", opts: []Option{WithStringNormalizers(CollapseWhitespace, NormalizeUnicode, FoldCase)}},
+		{name: "whitespace only", opts: []Option{WithStringNormalizers(CollapseWhitespace)}, want: []string{"city", "code", "note"}},
+		{
+			name: "per path",
+			opts: []Option{WithStringNormalizers(NormalizeUnicode), WithPathStringNormalizers("code", FoldCase)},
+			want: []string{"name", "note"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range resp.Entries {
+				got = append(got, entry.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changed paths %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: `<b> &amp; co`, want: "<b> & co"},
+		{input: `http:\/\/example.com`, want: "http://example.com"},
+		{input: `smile \ud83d\ude00`, want: "smile 😀"},
+		{input: "&lt;p&gt;&#39;hi&#39;", want: "<p>'hi'"},
+		{input: `plain \n text`, want: `plain \n text`},
+	}
+	for _, tt := range tests {
+		if got := UnescapeText(tt.input); got != tt.want {
+			t.Errorf("UnescapeText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	expected := `{"html": "Tom &amp; Jerry", "escaped": "a \\u003c b", "url": "http:\\/\\/x", "other": "x"}`
+	actual := `{"html": "Tom & Jerry", "escaped": "a < b", "url": "http://x", "other": "y"}`
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithEscapeNormalization())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].Path != "other" {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+}
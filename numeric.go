@@ -0,0 +1,98 @@
+package colorisediff
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// WithNumericDeltas annotates changed numbers with their difference, e.g. "45 (+3, +7.1%)" next to the actual value
+// in the colorized output, and records it in the Delta of the entry, which is more useful than colored digits for
+// metrics-style payloads. The percentage is left out when the expected value is zero.
+func WithNumericDeltas() Option {
+	return func(o *options) {
+		o.valueRenderers.numericDeltas = true
+	}
+}
+
+// numericTolerance lets numbers at paths matching a pattern differ by up to a tolerance.
+type numericTolerance struct {
+	pattern   string  // pattern is the gjson-style path pattern of the numbers.
+	tolerance float64 // tolerance is the largest absolute difference treated as equal.
+}
+
+// WithNumericTolerance treats two numbers at paths matching the given patterns as equal if they differ by no more
+// than the tolerance, e.g. WithNumericTolerance(0.01, "items.*.price"), so rounding noise in computed values does
+// not show up as differences. Patterns follow the syntax of SeverityRule patterns; when several match a path, the
+// largest tolerance applies.
+func WithNumericTolerance(tolerance float64, patterns ...string) Option {
+	return func(o *options) {
+		for _, pattern := range o.pathPatterns(patterns) {
+			o.numericTolerances = append(o.numericTolerances, numericTolerance{pattern: pattern, tolerance: tolerance})
+		}
+	}
+}
+
+// tolerateNumbers returns a transform replacing the actual number with the expected one when they differ by no
+// more than the tolerance configured for their path.
+func tolerateNumbers(tolerances []numericTolerance) pairTransform {
+	return func(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		e, ok := expected.(float64)
+		if !ok {
+			return nil, nil, "", false
+		}
+		a, ok := actual.(float64)
+		if !ok || e == a {
+			return nil, nil, "", false
+		}
+		for _, t := range tolerances {
+			if math.Abs(a-e) <= t.tolerance && matchPathPattern(t.pattern, path) {
+				return expected, expected, "", true
+			}
+		}
+		return nil, nil, "", false
+	}
+}
+
+// annotateDelta records the difference of a changed number in its entry, if asked to.
+func (o *options) annotateDelta(entry DiffEntry) DiffEntry {
+	if o.valueRenderers.numericDeltas && entry.Op == OpChanged {
+		entry.Delta, _ = numericDelta(entry.Expected, entry.Actual)
+	}
+	return entry
+}
+
+// numericDelta describes the difference between two numbers, e.g. "+3, +7.1%", and reports whether both values are
+// numbers.
+func numericDelta(expected, actual interface{}) (string, bool) {
+	e, ok := expected.(float64)
+	if !ok {
+		return "", false
+	}
+	a, ok := actual.(float64)
+	if !ok {
+		return "", false
+	}
+	// Ten significant digits hide the rounding errors of the subtraction, e.g. 0.3 - 0.1.
+	delta := strconv.FormatFloat(a-e, 'g', 10, 64)
+	if a >= e {
+		delta = "+" + delta
+	}
+	if e == 0 {
+		return delta, true
+	}
+	return fmt.Sprintf("%s, %+.1f%%", delta, (a-e)/math.Abs(e)*100), true
+}
+
+// deltaAnnotation returns the annotation of a changed number at a path in the colorizer's ".key[0]" notation, shown
+// after its actual value, e.g. "(+3, +7.1%)". It returns "" if the values are not both numbers, if a renderer, e.g.
+// a redaction, claims them or if no annotation is asked for.
+func (r valueRenderers) deltaAnnotation(legacyPath string, expected, actual interface{}) string {
+	if !r.numericDeltas || r.claims(legacyPath, expected, actual) {
+		return ""
+	}
+	if delta, ok := numericDelta(expected, actual); ok {
+		return "(" + delta + ")"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumericDelta(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual interface{}
+		want             string
+		wantOK           bool
+	}{
+		{name: "increase", expected: 42.0, actual: 45.0, want: "+3, +7.1%", wantOK: true},
+		{name: "decrease", expected: 200.0, actual: 150.0, want: "-50, -25.0%", wantOK: true},
+		{name: "rounding errors", expected: 0.1, actual: 0.3, want: "+0.2, +200.0%", wantOK: true},
+		{name: "negative expected", expected: -10.0, actual: -5.0, want: "+5, +50.0%", wantOK: true},
+		{name: "zero expected", expected: 0.0, actual: 7.0, want: "+7", wantOK: true},
+		{name: "not numbers", expected: "42", actual: 45.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := numericDelta(tt.expected, tt.actual)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("numericDelta(%v, %v) = %q, %v, want %q, %v", tt.expected, tt.actual, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithNumericDeltas(t *testing.T) {
+	expected := []byte(`{"requests":42,"latencies":[100],"secret":1}`)
+	actual := []byte(`{"requests":45,"latencies":[150],"secret":2}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithNumericDeltas(), WithRedact("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"45 (+3, +7.1%)", "150 (+50, +50.0%)"} {
+		if !strings.Contains(resp.Actual, want) {
+			t.Errorf("expected %q in:\n%s", want, resp.Actual)
+		}
+	}
+	if strings.Contains(resp.Expected, "%") {
+		t.Errorf("expected no annotation on the expected side, got:\n%s", resp.Expected)
+	}
+	if entry := resp.At("requests"); entry == nil || entry.Delta != "+3, +7.1%" {
+		t.Errorf("expected the delta in the entry, got %+v", entry)
+	}
+	if entry := resp.At("secret"); entry == nil || entry.Delta != "" || strings.Contains(resp.Actual, "+1") {
+		t.Errorf("expected no delta for redacted values, got %+v in:\n%s", entry, resp.Actual)
+	}
+}
+
+func TestWithNumericTolerance(t *testing.T) {
+	expected := `{"items":[{"price":10,"qty":1},{"price":20,"qty":2}],"total":50}`
+	actual := `{"items":[{"price":10.004,"qty":1},{"price":20.5,"qty":2}],"total":50.004}`
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithNumericTolerance(0.01, "items.*.price"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, entry := range resp.Entries {
+		paths = append(paths, entry.Path)
+	}
+	if want := "items.1.price,total"; strings.Join(paths, ",") != want {
+		t.Errorf("got entries at %v, want %s", paths, want)
+	}
+}
@@ -0,0 +1,200 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// openAPIMethods lists the HTTP methods an OpenAPI path item can hold operations for.
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+// OperationChange holds the differences affecting a single operation of an OpenAPI document.
+// Method: The upper-case HTTP method of the operation.
+// Path: The templated path of the operation, e.g. "/users/{id}".
+// Op: Whether the operation was added, removed or changed.
+// Breaking: Whether the change can break existing clients, i.e. the operation was removed or one of its schemas
+// changed.
+// Entries: The differences found inside the operation.
+type OperationChange struct {
+	Method   string
+	Path     string
+	Op       Op
+	Breaking bool
+	Entries  []DiffEntry
+}
+
+// OpenAPIDiff holds the differences between two OpenAPI documents.
+// Diff: The differences of the whole documents with their local references resolved.
+// Operations: The differences grouped by operation, sorted by path and method.
+type OpenAPIDiff struct {
+	Diff
+	Operations []OperationChange
+}
+
+// CompareOpenAPI compares two OpenAPI (or Swagger) documents in JSON format. Local references ("$ref": "#/...")
+// are resolved before comparing, so a change to a shared schema shows up in every operation using it; recursive
+// references are left unresolved. The differences are additionally grouped by operation.
+func CompareOpenAPI(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (OpenAPIDiff, error) {
+	var expectedDoc, actualDoc interface{}
+	if err := json.Unmarshal(expected, &expectedDoc); err != nil {
+		return OpenAPIDiff{}, fmt.Errorf("decoding expected OpenAPI document: %w", err)
+	}
+	if err := json.Unmarshal(actual, &actualDoc); err != nil {
+		return OpenAPIDiff{}, fmt.Errorf("decoding actual OpenAPI document: %w", err)
+	}
+	diff, err := compareDecoded(resolveRefs(expectedDoc, expectedDoc, nil), resolveRefs(actualDoc, actualDoc, nil), noise, disableColor, opts)
+	if err != nil {
+		return OpenAPIDiff{}, err
+	}
+	return OpenAPIDiff{Diff: diff, Operations: groupByOperation(diff.Entries)}, nil
+}
+
+// Summary renders one line per changed operation, e.g. "BREAKING DELETE /users/{id}: removed", followed by the
+// paths changed inside changed operations.
+func (d OpenAPIDiff) Summary() string {
+	var builder strings.Builder
+	for _, operation := range d.Operations {
+		prefix := ""
+		if operation.Breaking {
+			prefix = "BREAKING "
+		}
+		builder.WriteString(fmt.Sprintf("%s%s %s: %s\n", prefix, operation.Method, operation.Path, operation.Op))
+		if operation.Op != OpChanged {
+			continue
+		}
+		for _, entry := range operation.Entries {
+			builder.WriteString(fmt.Sprintf("  %s %s\n", entry.Op, entry.Path))
+		}
+	}
+	return builder.String()
+}
+
+// resolveRefs replaces local references in value with the values they point to inside root. resolving holds the
+// references being resolved on the current branch to detect cycles.
+func resolveRefs(value, root interface{}, resolving []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+			for _, seen := range resolving {
+				if seen == ref {
+					return v
+				}
+			}
+			target, ok := lookupPointer(root, strings.TrimPrefix(ref, "#"))
+			if !ok {
+				return v
+			}
+			return resolveRefs(target, root, append(resolving, ref))
+		}
+		resolved := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			resolved[key] = resolveRefs(child, root, resolving)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, child := range v {
+			resolved[i] = resolveRefs(child, root, resolving)
+		}
+		return resolved
+	}
+	return value
+}
+
+// lookupPointer returns the value an RFC 6901 JSON Pointer points to.
+func lookupPointer(root interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return root, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	current := root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch v := current.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			current = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// groupByOperation groups the entries found below "paths.<path>.<method>" by operation. Added or removed path
+// items are expanded into their operations.
+func groupByOperation(entries []DiffEntry) []OperationChange {
+	operations := map[string]*OperationChange{}
+	operation := func(path, method string, op Op) *OperationChange {
+		key := path + " " + method
+		if operations[key] == nil {
+			operations[key] = &OperationChange{Method: strings.ToUpper(method), Path: path, Op: op}
+		}
+		return operations[key]
+	}
+
+	for _, entry := range entries {
+		components := splitPath(entry.Path)
+		if len(components) < 2 || components[0] != "paths" || entry.Noised {
+			continue
+		}
+		if len(components) == 2 {
+			pathItem := entry.Expected
+			if entry.Op == OpAdded {
+				pathItem = entry.Actual
+			}
+			methods, _ := pathItem.(map[string]interface{})
+			for method := range methods {
+				if openAPIMethods[method] {
+					operation(components[1], method, entry.Op)
+				}
+			}
+			continue
+		}
+		if !openAPIMethods[components[2]] {
+			continue
+		}
+		if len(components) == 3 && entry.Op != OpChanged {
+			operation(components[1], components[2], entry.Op)
+			continue
+		}
+		changed := operation(components[1], components[2], OpChanged)
+		changed.Entries = append(changed.Entries, entry)
+		for _, component := range components[3:] {
+			if component == "schema" {
+				changed.Breaking = true
+			}
+		}
+	}
+
+	result := make([]OperationChange, 0, len(operations))
+	for _, change := range operations {
+		if change.Op == OpRemoved {
+			change.Breaking = true
+		}
+		result = append(result, *change)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
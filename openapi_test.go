@@ -0,0 +1,72 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareOpenAPI(t *testing.T) {
+	expected := `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/users/{id}": {
+				"get": {"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}},
+				"delete": {"responses": {"204": {"description": "deleted"}}}
+			},
+			"/health": {"get": {"summary": "Health check"}}
+		},
+		"components": {"schemas": {
+			"User": {"type": "object", "properties": {"name": {"type": "string"}, "friend": {"$ref": "#/components/schemas/User"}}}
+		}}
+	}`
+	actual := `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/users/{id}": {
+				"get": {"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}}
+			},
+			"/health": {"get": {"summary": "Liveness check"}},
+			"/metrics": {"get": {"summary": "Metrics"}}
+		},
+		"components": {"schemas": {
+			"User": {"type": "object", "properties": {"name": {"type": "integer"}, "friend": {"$ref": "#/components/schemas/User"}}}
+		}}
+	}`
+
+	resp, err := CompareOpenAPI([]byte(expected), []byte(actual), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type summary struct {
+		Method, Path string
+		Op           Op
+		Breaking     bool
+		Entries      int
+	}
+	var got []summary
+	for _, operation := range resp.Operations {
+		got = append(got, summary{operation.Method, operation.Path, operation.Op, operation.Breaking, len(operation.Entries)})
+	}
+	want := []summary{
+		{"GET", "/health", OpChanged, false, 1},
+		{"GET", "/metrics", OpAdded, false, 0},
+		{"DELETE", "/users/{id}", OpRemoved, true, 0},
+		{"GET", "/users/{id}", OpChanged, true, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected operations %+v, want %+v", got, want)
+	}
+	if entry := resp.At("components.schemas.User.properties.name.type"); entry == nil || entry.Actual != "integer" {
+		t.Errorf("expected the shared schema change, got %+v", entry)
+	}
+}
+
+func TestLookupPointer(t *testing.T) {
+	root := map[string]interface{}{"a/b": []interface{}{map[string]interface{}{"~c": "x"}}}
+	if value, ok := lookupPointer(root, "/a~1b/0/~0c"); !ok || value != "x" {
+		t.Errorf("lookupPointer() = %v, %v", value, ok)
+	}
+	if _, ok := lookupPointer(root, "/a~1b/1"); ok {
+		t.Error("expected an out-of-range index to fail")
+	}
+}
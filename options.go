@@ -0,0 +1,120 @@
+package colorisediff
+
+// Option configures a comparison.
+type Option func(*options)
+
+// options holds the settings collected from the Option values passed to a comparison.
+type options struct {
+	nestedJSONStrings bool // nestedJSONStrings enables diffing of JSON documents embedded in string values.
+	base64Decoding    bool // base64Decoding enables decoding of base64-encoded JSON or text values.
+	jwtDecoding       bool // jwtDecoding enables decoding of JWT-shaped string values.
+	jwtSignature      bool // jwtSignature keeps the JWT signature in the comparison.
+	urlNormalization  bool // urlNormalization enables structural comparison of URL values.
+	localeNumbers     bool // localeNumbers compares strings holding locale-formatted numbers by value.
+
+	stringNormalizers []pathStringNormalizers // stringNormalizers lists the string normalizers and where they apply.
+	decompress        bool                    // decompress enables decompression of the inputs.
+	encoding          string                  // encoding is the content encoding of the inputs, empty to auto-detect.
+
+	mongoExtendedJSON   bool // mongoExtendedJSON unwraps MongoDB Extended JSON values.
+	mongoIgnoreVolatile bool // mongoIgnoreVolatile treats ObjectIds, dates and timestamps as equal.
+
+	dynamoDB      bool // dynamoDB unwraps DynamoDB AttributeValues.
+	openTelemetry bool // openTelemetry pairs OTLP spans and unwraps their attributes.
+
+	pathSyntax PathSyntax // pathSyntax is the notation of the paths given to the options that follow.
+
+	unorderedArrays     []string // unorderedArrays lists the path patterns of arrays compared regardless of order.
+	ignoreExtraElements bool     // ignoreExtraElements tolerates extra trailing elements in actual arrays.
+	ignoreExtraKeys     bool     // ignoreExtraKeys tolerates extra keys in actual objects.
+	shapeOnly           bool     // shapeOnly compares the types of leaf values instead of their content.
+	keployTemplates     bool     // keployTemplates treats keploy template variables as type matchers.
+	statusClass         bool     // statusClass makes CompareStatus compare status codes by class.
+	lengthSummaries     bool     // lengthSummaries summarizes the arrays whose length differs.
+
+	numericTolerances []numericTolerance // numericTolerances let numbers at matching paths differ slightly.
+
+	replacementDetection bool // replacementDetection collapses subtrees replaced by unrelated values.
+
+	headerParsers map[string]HeaderParser // headerParsers maps lower-cased header names to their parsers.
+
+	graphQL       bool // graphQL compares GraphQL response envelopes.
+	elasticsearch bool // elasticsearch compares Elasticsearch search responses.
+
+	jsonSchema        []byte             // jsonSchema is the JSON Schema the actual values are validated against.
+	assertions        []string           // assertions are the expressions evaluated against the actual document.
+	consistencyChecks []ConsistencyCheck // consistencyChecks compare paths of the actual document with each other.
+	severityRules     []SeverityRule     // severityRules assign labels to the entries.
+	waivers           []Waiver           // waivers accept known differences.
+
+	sectionDepth   int // sectionDepth is the number of path components naming a section, 0 to disable grouping.
+	maxOutputLines int // maxOutputLines caps the number of rendered lines per side, 0 for no limit.
+	memoryBudget   int // memoryBudget caps the approximate bytes taken by the entries, 0 for no limit.
+
+	valueRenderers valueRenderers // valueRenderers customize how values are displayed.
+	sourceKeyOrder bool           // sourceKeyOrder renders object keys in the order of the source documents.
+
+	onDiff  func(DiffEntry) // onDiff is called with every entry as soon as it is found.
+	metrics Metrics         // metrics records statistics about the comparisons.
+
+	cache      ResultCache // cache stores the results of comparisons by their inputs.
+	cacheScope string      // cacheScope tells apart cached comparisons run with different options.
+
+	metadataHeader bool      // metadataHeader shows a header block describing the comparison above the differences.
+	inputIDs       [2]string // inputIDs identifies the expected and actual inputs in the metadata header.
+	legend         bool      // legend explains the colors above the differences.
+	labels         Labels    // labels names the two sides in the rendered output.
+
+	omitContextKey bool // omitContextKey drops the context section shown above the differences.
+	contextFields  int  // contextFields is the number of fields listed in the context section, 0 for one.
+
+	noiseReasons       map[string]string // noiseReasons maps noise rules to why they are ignored.
+	renderNoiseReasons bool              // renderNoiseReasons shows noised fields dimmed with their reasons.
+	hideNoise          bool              // hideNoise leaves noised fields out of the output.
+
+	noise        map[string][]string // noise holds the noise rules given with WithNoise.
+	defaultNoise []string            // defaultNoise lists noise paths added by the enabled modes.
+
+	palette palette // palette colors the output, set from the disableColor argument of the comparison.
+}
+
+// newOptions applies the given options on top of the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// mergeNoise returns the caller's noise extended with the noise given with WithNoise and the default noise of the
+// enabled modes. The caller's map is not modified.
+func (o *options) mergeNoise(noise map[string][]string) map[string][]string {
+	if len(o.defaultNoise) == 0 && len(o.noise) == 0 {
+		return noise
+	}
+	merged := make(map[string][]string, len(noise)+len(o.noise)+len(o.defaultNoise))
+	for key, values := range noise {
+		merged[key] = values
+	}
+	for key, values := range o.noise {
+		merged[key] = values
+	}
+	for _, key := range o.defaultNoise {
+		if _, ok := merged[key]; !ok {
+			merged[key] = []string{}
+		}
+	}
+	return merged
+}
+
+// WithNestedJSONStrings makes the comparison decode string values that themselves contain a JSON object or array
+// when both sides of a changed field are such strings, and diff the embedded documents structurally instead of
+// word-diffing the escaped strings.
+func WithNestedJSONStrings() Option {
+	return func(o *options) {
+		o.nestedJSONStrings = true
+	}
+}
@@ -0,0 +1,117 @@
+package colorisediff
+
+import (
+	"reflect"
+	"sort"
+)
+
+// WithUnorderedArrays makes the comparison match the elements of the arrays at paths matching the given patterns
+// regardless of their position, e.g. WithUnorderedArrays("animals.domestic", "items.*.tags"). Elements present on
+// both sides are lined up with each other, so only elements without a counterpart show up as differences, at the
+// end of the array. Arrays at other paths stay positional. Patterns follow the syntax of SeverityRule patterns.
+func WithUnorderedArrays(patterns ...string) Option {
+	return func(o *options) {
+		o.unorderedArrays = append(o.unorderedArrays, o.pathPatterns(patterns)...)
+	}
+}
+
+// IndexPair relates a position of an array reordered by WithUnorderedArrays to the original positions of the
+// elements compared there. Expected or Actual is -1 if the position only exists on the other side.
+type IndexPair struct {
+	Expected int `json:"expected"`
+	Actual   int `json:"actual"`
+}
+
+// ArrayMapping records how the elements of an array compared regardless of order were lined up.
+// Path: The path of the array, in the form used by the entries.
+// Pairs: The original indexes of the elements compared at each position, so the entry at Path+".2" compares the
+// elements at Pairs[2]; e.g. {Expected: 0, Actual: 2} means the first expected element was found at index 2.
+type ArrayMapping struct {
+	Path  string      `json:"path"`
+	Pairs []IndexPair `json:"pairs"`
+}
+
+// reorderUnorderedArrays returns a transform reordering both arrays at paths matching the patterns so that equal
+// elements share an index. The index mapping of every reordered array is passed to record.
+func reorderUnorderedArrays(patterns []string, record func(ArrayMapping)) pairTransform {
+	return func(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		e, ok := expected.([]interface{})
+		if !ok {
+			return nil, nil, "", false
+		}
+		a, ok := actual.([]interface{})
+		if !ok || !matchesAnyPattern(patterns, path) {
+			return nil, nil, "", false
+		}
+		alignedExpected, alignedActual, pairs := alignElements(e, a)
+		if reflect.DeepEqual(alignedExpected, e) && reflect.DeepEqual(alignedActual, a) {
+			return nil, nil, "", false
+		}
+		record(ArrayMapping{Path: path, Pairs: pairs})
+		return alignedExpected, alignedActual, "elements matched regardless of order", true
+	}
+}
+
+// matchesAnyPattern reports whether the path matches one of the patterns.
+func matchesAnyPattern(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matchPathPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// alignElements reorders two arrays so that the pairs of equal elements come first, in the order of the expected
+// array, followed by the unmatched elements of each side in their original order. It also returns the original
+// indexes of the elements at every position of the reordered arrays.
+func alignElements(expected, actual []interface{}) ([]interface{}, []interface{}, []IndexPair) {
+	matched := make([]bool, len(actual))
+	var expectedOrder, actualOrder, unmatchedExpected []int
+	for i, element := range expected {
+		found := false
+		for j, candidate := range actual {
+			if !matched[j] && canonicalEqual(element, candidate) {
+				expectedOrder = append(expectedOrder, i)
+				actualOrder = append(actualOrder, j)
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			unmatchedExpected = append(unmatchedExpected, i)
+		}
+	}
+	expectedOrder = append(expectedOrder, unmatchedExpected...)
+	for j := range actual {
+		if !matched[j] {
+			actualOrder = append(actualOrder, j)
+		}
+	}
+
+	alignedExpected := make([]interface{}, len(expectedOrder))
+	for k, i := range expectedOrder {
+		alignedExpected[k] = expected[i]
+	}
+	alignedActual := make([]interface{}, len(actualOrder))
+	for k, j := range actualOrder {
+		alignedActual[k] = actual[j]
+	}
+	pairs := make([]IndexPair, max(len(expectedOrder), len(actualOrder)))
+	for k := range pairs {
+		pairs[k] = IndexPair{Expected: -1, Actual: -1}
+		if k < len(expectedOrder) {
+			pairs[k].Expected = expectedOrder[k]
+		}
+		if k < len(actualOrder) {
+			pairs[k].Actual = actualOrder[k]
+		}
+	}
+	return alignedExpected, alignedActual, pairs
+}
+
+// sortArrayMappings sorts the mappings by path.
+func sortArrayMappings(mappings []ArrayMapping) []ArrayMapping {
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Path < mappings[j].Path })
+	return mappings
+}
@@ -0,0 +1,140 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithUnorderedArrays(t *testing.T) {
+	json1 := `{"animals":{"domestic":["cat","dog","cow"],"wild":["lion","tiger"]}}`
+	json2 := `{"animals":{"domestic":["cow","cat","dog"],"wild":["tiger","lion"]}}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithUnorderedArrays("animals.domestic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range resp.Entries {
+		if entry.Path != "animals.wild.0" && entry.Path != "animals.wild.1" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+	}
+	if len(resp.Entries) != 2 {
+		t.Errorf("expected the positional array to differ, got %+v", resp.Entries)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true, WithUnorderedArrays("animals.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Expected != "" || len(resp.Entries) != 0 {
+		t.Errorf("expected no differences, got %+v", resp.Entries)
+	}
+
+	json2 = `{"animals":{"domestic":["dog","horse","cat"],"wild":["lion","tiger"]}}`
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true, WithUnorderedArrays("animals.domestic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{
+		Path:     "animals.domestic.2",
+		Op:       OpChanged,
+		Expected: "cow",
+		Actual:   "horse",
+		Note:     "elements matched regardless of order",
+	}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+}
+
+func TestAlignElements(t *testing.T) {
+	tests := []struct {
+		name         string
+		expected     []interface{}
+		actual       []interface{}
+		wantExpected []interface{}
+		wantActual   []interface{}
+		wantPairs    []IndexPair
+	}{
+		{
+			name:         "permutation",
+			expected:     []interface{}{"a", "b", "c"},
+			actual:       []interface{}{"c", "a", "b"},
+			wantExpected: []interface{}{"a", "b", "c"},
+			wantActual:   []interface{}{"a", "b", "c"},
+			wantPairs:    []IndexPair{{0, 1}, {1, 2}, {2, 0}},
+		},
+		{
+			name:         "unmatched elements move to the end",
+			expected:     []interface{}{"a", "b", "c"},
+			actual:       []interface{}{"c", "x", "a", "y"},
+			wantExpected: []interface{}{"a", "c", "b"},
+			wantActual:   []interface{}{"a", "c", "x", "y"},
+			wantPairs:    []IndexPair{{0, 2}, {2, 0}, {1, 1}, {-1, 3}},
+		},
+		{
+			name:         "duplicates are matched once",
+			expected:     []interface{}{1.0, 1.0, 2.0},
+			actual:       []interface{}{2.0, 1.0},
+			wantExpected: []interface{}{1.0, 2.0, 1.0},
+			wantActual:   []interface{}{1.0, 2.0},
+			wantPairs:    []IndexPair{{0, 1}, {2, 0}, {1, -1}},
+		},
+		{
+			name:         "objects",
+			expected:     []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+			actual:       []interface{}{map[string]interface{}{"id": 2.0}, map[string]interface{}{"id": 1.0}},
+			wantExpected: []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+			wantActual:   []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+			wantPairs:    []IndexPair{{0, 1}, {1, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotExpected, gotActual, gotPairs := alignElements(tt.expected, tt.actual)
+			if !reflect.DeepEqual(gotExpected, tt.wantExpected) || !reflect.DeepEqual(gotActual, tt.wantActual) {
+				t.Errorf("alignElements() = %v, %v, want %v, %v", gotExpected, gotActual, tt.wantExpected, tt.wantActual)
+			}
+			if !reflect.DeepEqual(gotPairs, tt.wantPairs) {
+				t.Errorf("alignElements() pairs = %v, want %v", gotPairs, tt.wantPairs)
+			}
+		})
+	}
+}
+
+func TestUnorderedArrayMappings(t *testing.T) {
+	json1 := `{"items":[{"id":1},{"id":2},{"id":3}],"tags":["a","b"]}`
+	json2 := `{"items":[{"id":3},{"id":1},{"id":4}],"tags":["b","a"]}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithUnorderedArrays("**"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ArrayMapping{
+		{Path: "items", Pairs: []IndexPair{{Expected: 0, Actual: 1}, {Expected: 2, Actual: 0}, {Expected: 1, Actual: 2}}},
+		{Path: "tags", Pairs: []IndexPair{{Expected: 0, Actual: 1}, {Expected: 1, Actual: 0}}},
+	}
+	if !reflect.DeepEqual(resp.ArrayMappings, want) {
+		t.Errorf("unexpected mappings %+v", resp.ArrayMappings)
+	}
+	if entry := resp.At("items.2.id"); entry == nil || entry.Expected != 2.0 || entry.Actual != 4.0 {
+		t.Errorf("expected the unmatched elements to be compared at index 2, got %+v", resp.Entries)
+	}
+
+	resp, err = CompareJSON([]byte(`["a","b"]`), []byte(`["b","a"]`), nil, true, WithUnorderedArrays(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []ArrayMapping{{Path: "", Pairs: []IndexPair{{Expected: 0, Actual: 1}, {Expected: 1, Actual: 0}}}}
+	if len(resp.Entries) != 0 || !reflect.DeepEqual(resp.ArrayMappings, want) {
+		t.Errorf("expected the mapping of equal documents, got %+v", resp)
+	}
+
+	resp, err = CompareJSON([]byte(json1), []byte(json2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ArrayMappings != nil {
+		t.Errorf("expected no mappings for positional arrays, got %+v", resp.ArrayMappings)
+	}
+}
@@ -0,0 +1,163 @@
+package colorisediff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// otlpNoise lists the noise paths of the OpenTelemetry preset: trace and span IDs and all timestamps. Noise paths
+// match case-insensitively, so "spanid" also covers "parentSpanId".
+var otlpNoise = []string{"traceid", "spanid", "timeunixnano"}
+
+// otlpSpanSeparator joins the names of a span and its ancestors into the key spans are paired by.
+const otlpSpanSeparator = " > "
+
+// WithOpenTelemetry applies a preset for OpenTelemetry traces in the OTLP/JSON format. Spans are paired by their
+// name and the names of their ancestors instead of their position, span and trace IDs and timestamps are treated
+// as noise, and attribute lists are compared as objects mapping the attribute keys to their plain values.
+func WithOpenTelemetry() Option {
+	return func(o *options) {
+		o.openTelemetry = true
+		o.defaultNoise = append(o.defaultNoise, otlpNoise...)
+	}
+}
+
+// otlpSpan holds what is needed to compute the key of a span.
+type otlpSpan struct {
+	name     string
+	parentID string
+}
+
+// normalizeOTLP is a normalizer rewriting a whole OTLP/JSON document: "spans" lists are keyed by the span's
+// ancestry and "attributes" lists become objects.
+func normalizeOTLP(path string, value interface{}) (interface{}, bool) {
+	if path != "" {
+		return value, false
+	}
+	spans := map[string]otlpSpan{}
+	collectOTLPSpans(value, spans)
+	return rewriteOTLP(value, spans), false
+}
+
+// collectOTLPSpans indexes the spans found anywhere in a value by their span ID.
+func collectOTLPSpans(value interface{}, spans map[string]otlpSpan) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if list, ok := v["spans"].([]interface{}); ok {
+			for _, element := range list {
+				if span, ok := element.(map[string]interface{}); ok {
+					id, _ := span["spanId"].(string)
+					name, _ := span["name"].(string)
+					parentID, _ := span["parentSpanId"].(string)
+					if id != "" {
+						spans[id] = otlpSpan{name: name, parentID: parentID}
+					}
+				}
+			}
+		}
+		for _, child := range v {
+			collectOTLPSpans(child, spans)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectOTLPSpans(child, spans)
+		}
+	}
+}
+
+// rewriteOTLP keys the "spans" lists and converts the "attributes" lists found in a value.
+func rewriteOTLP(value interface{}, spans map[string]otlpSpan) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			child = rewriteOTLP(child, spans)
+			if list, ok := child.([]interface{}); ok {
+				switch key {
+				case "spans":
+					child = keyElements(list, func(span interface{}) string { return otlpSpanKey(span, spans) })
+				case "attributes":
+					if attributes, ok := otlpAttributes(list); ok {
+						child = attributes
+					}
+				}
+			}
+			v[key] = child
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = rewriteOTLP(child, spans)
+		}
+	}
+	return value
+}
+
+// otlpSpanKey joins the names of a span and its ancestors, e.g. "GET /users > SELECT users". Ancestors missing
+// from the document end the chain.
+func otlpSpanKey(element interface{}, spans map[string]otlpSpan) string {
+	span, _ := element.(map[string]interface{})
+	name, _ := span["name"].(string)
+	names := []string{name}
+	parentID, _ := span["parentSpanId"].(string)
+	for depth := 0; parentID != "" && depth < maxDecodeDepth; depth++ {
+		parent, ok := spans[parentID]
+		if !ok {
+			break
+		}
+		names = append([]string{parent.name}, names...)
+		parentID = parent.parentID
+	}
+	return strings.Join(names, otlpSpanSeparator)
+}
+
+// otlpAttributes converts a list of OTLP key/value pairs into an object. It reports false if the list holds
+// anything else.
+func otlpAttributes(list []interface{}) (map[string]interface{}, bool) {
+	attributes := make(map[string]interface{}, len(list))
+	for _, element := range list {
+		pair, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		key, ok := pair["key"].(string)
+		if !ok {
+			return nil, false
+		}
+		attributes[key] = otlpAnyValue(pair["value"])
+	}
+	return attributes, true
+}
+
+// otlpAnyValue unwraps an OTLP AnyValue, e.g. {"intValue": "42"}, into a plain value. Unknown shapes are kept.
+func otlpAnyValue(value interface{}) interface{} {
+	wrapper, ok := value.(map[string]interface{})
+	if !ok || len(wrapper) != 1 {
+		return value
+	}
+	for kind, inner := range wrapper {
+		switch kind {
+		case "stringValue", "boolValue", "doubleValue", "bytesValue":
+			return inner
+		case "intValue":
+			if s, ok := inner.(string); ok {
+				if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+					return n
+				}
+			}
+			return inner
+		case "arrayValue":
+			container, _ := inner.(map[string]interface{})
+			values := schemaList(container["values"])
+			unwrapped := make([]interface{}, len(values))
+			for i, element := range values {
+				unwrapped[i] = otlpAnyValue(element)
+			}
+			return unwrapped
+		case "kvlistValue":
+			container, _ := inner.(map[string]interface{})
+			if attributes, ok := otlpAttributes(schemaList(container["values"])); ok {
+				return attributes
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,53 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpenTelemetry(t *testing.T) {
+	expected := `{"resourceSpans": [{"scopeSpans": [{"spans": [
+		{"traceId": "t1", "spanId": "a1", "name": "GET /users", "startTimeUnixNano": "100",
+		 "attributes": [{"key": "http.status_code", "value": {"intValue": "200"}}]},
+		{"traceId": "t1", "spanId": "b1", "parentSpanId": "a1", "name": "SELECT users", "startTimeUnixNano": "110",
+		 "attributes": [{"key": "db.system", "value": {"stringValue": "postgresql"}}]}
+	]}]}]}`
+	actual := `{"resourceSpans": [{"scopeSpans": [{"spans": [
+		{"traceId": "t2", "spanId": "b2", "parentSpanId": "a2", "name": "SELECT users", "startTimeUnixNano": "210",
+		 "attributes": [{"key": "db.system", "value": {"stringValue": "mysql"}}]},
+		{"traceId": "t2", "spanId": "a2", "name": "GET /users", "startTimeUnixNano": "200",
+		 "attributes": [{"key": "http.status_code", "value": {"intValue": "200"}}]}
+	]}]}]}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithOpenTelemetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []DiffEntry
+	for _, entry := range resp.Entries {
+		if !entry.Noised {
+			got = append(got, entry)
+		}
+	}
+	want := []DiffEntry{{
+		Path:     `resourceSpans.0.scopeSpans.0.spans.GET /users > SELECT users.attributes.db\.system`,
+		Op:       OpChanged,
+		Expected: "postgresql",
+		Actual:   "mysql",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected entries %+v, want %+v", got, want)
+	}
+}
+
+func TestOTLPAnyValue(t *testing.T) {
+	value := map[string]interface{}{"kvlistValue": map[string]interface{}{"values": []interface{}{
+		map[string]interface{}{"key": "ids", "value": map[string]interface{}{"arrayValue": map[string]interface{}{
+			"values": []interface{}{map[string]interface{}{"intValue": "1"}, map[string]interface{}{"boolValue": true}},
+		}}},
+	}}}
+	want := map[string]interface{}{"ids": []interface{}{int64(1), true}}
+	if got := otlpAnyValue(value); !reflect.DeepEqual(got, want) {
+		t.Errorf("otlpAnyValue() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Pagination names the members of a paginated list response.
+// Items: The dot-separated path of the array holding the items of a page, "items" if empty.
+// Cursors: The dot-separated paths of the pagination cursors, ["nextPageToken"] if empty.
+type Pagination struct {
+	Items   string
+	Cursors []string
+}
+
+// ComparePages compares two paginated list responses, each given as the JSON documents of its pages in order, and
+// returns the colorized differences. The pages of each side are merged into a single document first: the first page
+// with the items of all pages concatenated and the cursors of the last page. The cursors are treated as noise, so a
+// result split at different page boundaries, e.g. after a retried request, only shows the changes in the data.
+func ComparePages(expectedPages, actualPages [][]byte, pagination Pagination, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	if pagination.Items == "" {
+		pagination.Items = "items"
+	}
+	if len(pagination.Cursors) == 0 {
+		pagination.Cursors = []string{"nextPageToken"}
+	}
+	expected, err := mergePages(expectedPages, pagination)
+	if err != nil {
+		return Diff{}, fmt.Errorf("merging expected pages: %w", err)
+	}
+	actual, err := mergePages(actualPages, pagination)
+	if err != nil {
+		return Diff{}, fmt.Errorf("merging actual pages: %w", err)
+	}
+	cursorNoise := func(o *options) {
+		for _, cursor := range pagination.Cursors {
+			o.defaultNoise = append(o.defaultNoise, strings.ToLower(cursor))
+		}
+	}
+	return compareDecoded(expected, actual, noise, disableColor, append(opts[:len(opts):len(opts)], cursorNoise))
+}
+
+// mergePages decodes the pages of a list response and merges them into the first page.
+func mergePages(pages [][]byte, pagination Pagination) (map[string]interface{}, error) {
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages")
+	}
+	var merged map[string]interface{}
+	items := []interface{}{}
+	for i, data := range pages {
+		var page map[string]interface{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		if page == nil {
+			return nil, fmt.Errorf("page %d: expected an object", i+1)
+		}
+		switch pageItems := lookupMember(page, pagination.Items).(type) {
+		case nil:
+		case []interface{}:
+			items = append(items, pageItems...)
+		default:
+			return nil, fmt.Errorf("page %d: %s is not an array", i+1, pagination.Items)
+		}
+		if i == 0 {
+			merged = page
+			continue
+		}
+		for _, cursor := range pagination.Cursors {
+			setMember(merged, cursor, lookupMember(page, cursor))
+		}
+	}
+	setMember(merged, pagination.Items, items)
+	return merged, nil
+}
+
+// lookupMember returns the value at a dot-separated path of nested objects, or nil if there is none.
+func lookupMember(object map[string]interface{}, path string) interface{} {
+	parent, key := parentObject(object, path, false)
+	if parent == nil {
+		return nil
+	}
+	return parent[key]
+}
+
+// setMember sets the value at a dot-separated path of nested objects, creating the missing objects on the way. A
+// nil value removes the member.
+func setMember(object map[string]interface{}, path string, value interface{}) {
+	parent, key := parentObject(object, path, value != nil)
+	switch {
+	case parent == nil:
+	case value == nil:
+		delete(parent, key)
+	default:
+		parent[key] = value
+	}
+}
+
+// parentObject returns the object holding the last key of a dot-separated path and that key. Missing objects are
+// created if create is set; otherwise nil is returned.
+func parentObject(object map[string]interface{}, path string, create bool) (map[string]interface{}, string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := object[key].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			child = map[string]interface{}{}
+			object[key] = child
+		}
+		object = child
+	}
+	return object, keys[len(keys)-1]
+}
@@ -0,0 +1,76 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComparePages(t *testing.T) {
+	expected := [][]byte{
+		[]byte(`{"items":[{"id":1},{"id":2}],"nextPageToken":"a1","total":4}`),
+		[]byte(`{"items":[{"id":3},{"id":4}],"total":4}`),
+	}
+	actual := [][]byte{
+		[]byte(`{"items":[{"id":1}],"nextPageToken":"b1","total":4}`),
+		[]byte(`{"items":[{"id":2},{"id":3}],"nextPageToken":"b2","total":4}`),
+		[]byte(`{"items":[{"id":5}],"total":4}`),
+	}
+
+	resp, err := ComparePages(expected, actual, Pagination{}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "items.3.id", Op: OpChanged, Expected: 4.0, Actual: 5.0}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	expected[1] = []byte(`{"items":[{"id":3},{"id":4}],"nextPageToken":"a2","total":4}`)
+	actual[2] = []byte(`{"items":[{"id":4}],"nextPageToken":"b3","total":4}`)
+	resp, err = ComparePages(expected, actual, Pagination{}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := resp.Stats(); stats.Total != 0 || stats.Noised != 1 || !resp.At("nextPageToken").Noised {
+		t.Errorf("expected the cursor to be noised, got %+v", resp.Entries)
+	}
+}
+
+func TestComparePagesCustomFields(t *testing.T) {
+	pagination := Pagination{Items: "data.results", Cursors: []string{"meta.cursor"}}
+	expected := [][]byte{
+		[]byte(`{"data":{"results":["a"]},"meta":{"cursor":"x"}}`),
+		[]byte(`{"data":{"results":["b"]},"meta":{}}`),
+	}
+	actual := [][]byte{[]byte(`{"data":{"results":["a","b"]},"meta":{}}`)}
+
+	resp, err := ComparePages(expected, actual, pagination, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 0 {
+		t.Errorf("expected the merged pages to be equal, got %+v", resp.Entries)
+	}
+}
+
+func TestComparePagesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected [][]byte
+		wantErr  string
+	}{
+		{name: "no pages", expected: nil, wantErr: "merging expected pages: no pages"},
+		{name: "invalid JSON", expected: [][]byte{[]byte(`{`)}, wantErr: "page 1"},
+		{name: "not an object", expected: [][]byte{[]byte(`[]`)}, wantErr: "page 1"},
+		{name: "items not an array", expected: [][]byte{[]byte(`{"items":{}}`)}, wantErr: "page 1: items is not an array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ComparePages(tt.expected, [][]byte{[]byte(`{}`)}, Pagination{}, nil, true)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ComparePages() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package colorisediff
+
+import "github.com/fatih/color"
+
+// palette creates the color functions of a single comparison. It is passed along explicitly instead of toggling
+// the global color.NoColor, so concurrent comparisons with different color settings do not race.
+type palette struct {
+	noColor bool // noColor renders the output without ANSI colors.
+}
+
+// defaultPalette returns the palette following the global color.NoColor setting, for the functions that take no
+// color setting of their own.
+func defaultPalette() palette {
+	return palette{noColor: color.NoColor}
+}
+
+// sprintFunc returns a function wrapping its arguments in the given attributes, or leaving them uncolored when
+// the palette disables colors.
+func (p palette) sprintFunc(attributes ...color.Attribute) func(a ...interface{}) string {
+	c := color.New(attributes...)
+	if p.noColor {
+		c.DisableColor()
+	} else {
+		c.EnableColor()
+	}
+	return c.SprintFunc()
+}
@@ -0,0 +1,46 @@
+package colorisediff
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestCompareJSONConcurrentColors(t *testing.T) {
+	expected := []byte(`{"name":"Cat","age":2}`)
+	actual := []byte(`{"name":"Dog","age":3}`)
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64)
+	for i := 0; i < 64; i++ {
+		disableColor := i%2 == 0
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := CompareJSON(expected, actual, nil, disableColor)
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+			if colored := strings.Contains(resp.Expected+resp.Actual, "\x1b["); colored == disableColor {
+				errs <- "color output does not match disableColor"
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	if got := (palette{noColor: true}).sprintFunc(color.FgRed)("x"); got != "x" {
+		t.Errorf("disabled palette colored the text: %q", got)
+	}
+	if got := (palette{}).sprintFunc(color.FgRed)("x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("enabled palette returned %q", got)
+	}
+}
@@ -0,0 +1,236 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PathSyntax names a notation for paths into JSON documents. Entries always record gjson-style paths; a syntax
+// converts from and to them, so paths can be written and read in the notation an ecosystem standardizes on.
+type PathSyntax int
+
+const (
+	// GJSONPath is the default notation, e.g. "items.0.price" or "items.*.price".
+	GJSONPath PathSyntax = iota
+	// JSONPointer is the RFC 6901 notation, e.g. "/items/0/price". In patterns, "*" and "**" reference tokens are
+	// wildcards like in gjson-style patterns, e.g. "/items/*/price".
+	JSONPointer
+	// JSONPath is the JSONPath notation, e.g. "$.items[0].price". In patterns, "[*]" and ".*" match any member or
+	// element and ".." any number of components, e.g. "$.items[*].price" or "$..price". Filters are not supported.
+	JSONPath
+)
+
+// String returns the name of the syntax.
+func (s PathSyntax) String() string {
+	switch s {
+	case GJSONPath:
+		return "GJSONPath"
+	case JSONPointer:
+		return "JSONPointer"
+	case JSONPath:
+		return "JSONPath"
+	}
+	return fmt.Sprintf("PathSyntax(%d)", int(s))
+}
+
+// WithPathSyntax makes the path options given after it, such as WithUnorderedArrays, WithRedact, WithSeverityRules,
+// WithWaivers and WithPathStringNormalizers, read their paths and patterns in the given syntax, e.g.
+// WithPathSyntax(JSONPointer) followed by WithRedact("/auth/token"). Paths that are not valid in the syntax are used
+// as given.
+func WithPathSyntax(syntax PathSyntax) Option {
+	return func(o *options) {
+		o.pathSyntax = syntax
+	}
+}
+
+// pathPattern converts a path or pattern given to an option into a gjson-style one.
+func (o *options) pathPattern(pattern string) string {
+	if path, err := o.pathSyntax.Parse(pattern); err == nil {
+		return path
+	}
+	return pattern
+}
+
+// pathPatterns converts paths or patterns given to an option into gjson-style ones.
+func (o *options) pathPatterns(patterns []string) []string {
+	if o.pathSyntax == GJSONPath {
+		return patterns
+	}
+	converted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		converted[i] = o.pathPattern(pattern)
+	}
+	return converted
+}
+
+// Parse converts a path or pattern written in the syntax into a gjson-style one, e.g. JSONPointer.Parse("/a~1b/0")
+// returns "a/b.0".
+func (s PathSyntax) Parse(path string) (string, error) {
+	switch s {
+	case GJSONPath:
+		return path, nil
+	case JSONPointer:
+		return parsePointer(path)
+	case JSONPath:
+		return parseJSONPath(path)
+	}
+	return "", fmt.Errorf("unknown path syntax %v", s)
+}
+
+// Format converts a gjson-style path, such as the path of an entry, into the syntax, e.g. JSONPath.Format("a.0")
+// returns "$.a[0]". Numeric components are taken for array indexes.
+func (s PathSyntax) Format(path string) string {
+	switch s {
+	case JSONPointer:
+		return pathToPointer(path)
+	case JSONPath:
+		return formatJSONPath(path)
+	}
+	return path
+}
+
+// pathComponent escapes a component of a gjson-style pattern, leaving the wildcard components as they are.
+func pathComponent(component string) string {
+	if component == "*" || component == "**" {
+		return component
+	}
+	return escapePathKey(component)
+}
+
+// parsePointer converts an RFC 6901 JSON Pointer into a gjson-style path.
+func parsePointer(pointer string) (string, error) {
+	if pointer == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("JSON Pointer %q does not start with \"/\"", pointer)
+	}
+	var path string
+	for _, token := range strings.Split(pointer[1:], "/") {
+		var builder strings.Builder
+		for i := 0; i < len(token); i++ {
+			if token[i] != '~' {
+				builder.WriteByte(token[i])
+				continue
+			}
+			if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+				return "", fmt.Errorf("JSON Pointer %q has an invalid escape", pointer)
+			}
+			if i++; token[i] == '0' {
+				builder.WriteByte('~')
+			} else {
+				builder.WriteByte('/')
+			}
+		}
+		path = joinPath(path, pathComponent(builder.String()))
+	}
+	return path, nil
+}
+
+// parseJSONPath converts a JSONPath expression without filters into a gjson-style path.
+func parseJSONPath(expression string) (string, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return "", fmt.Errorf("JSONPath %q does not start with \"$\"", expression)
+	}
+	var path string
+	rest := expression[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			path = joinPath(path, "**")
+			rest = rest[2:]
+			if rest != "" && rest[0] != '[' {
+				rest = "." + rest
+			}
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return "", fmt.Errorf("JSONPath %q has an empty member name", expression)
+			}
+			path = joinPath(path, pathComponent(name))
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			component, length, err := parseBracket(rest)
+			if err != nil {
+				return "", fmt.Errorf("JSONPath %q: %w", expression, err)
+			}
+			path = joinPath(path, component)
+			rest = rest[length:]
+		default:
+			return "", fmt.Errorf("JSONPath %q has an unexpected %q", expression, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// parseBracket parses a JSONPath bracket selector at the start of text, such as [0], [*] or ['name'], and returns
+// the gjson-style component and the length of the selector.
+func parseBracket(text string) (string, int, error) {
+	if len(text) > 1 && (text[1] == '\'' || text[1] == '"') {
+		quote := text[1]
+		var builder strings.Builder
+		for i := 2; i < len(text); i++ {
+			switch {
+			case text[i] == '\\' && i+1 < len(text):
+				i++
+				builder.WriteByte(text[i])
+			case text[i] == quote:
+				if i+1 == len(text) || text[i+1] != ']' {
+					return "", 0, fmt.Errorf("unterminated selector %q", text)
+				}
+				return escapePathKey(builder.String()), i + 2, nil
+			default:
+				builder.WriteByte(text[i])
+			}
+		}
+		return "", 0, fmt.Errorf("unterminated selector %q", text)
+	}
+	end := strings.IndexByte(text, ']')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated selector %q", text)
+	}
+	selector := strings.TrimSpace(text[1:end])
+	if selector == "*" {
+		return "*", end + 1, nil
+	}
+	if _, err := strconv.ParseUint(selector, 10, 0); err != nil {
+		return "", 0, fmt.Errorf("unsupported selector %q", text[:end+1])
+	}
+	return selector, end + 1, nil
+}
+
+// formatJSONPath converts a gjson-style path into a JSONPath expression.
+func formatJSONPath(path string) string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, component := range splitPath(path) {
+		switch {
+		case component != "" && strings.Trim(component, "0123456789") == "":
+			builder.WriteString("[" + component + "]")
+		case isJSONPathName(component):
+			builder.WriteString("." + component)
+		default:
+			builder.WriteString("['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(component) + "']")
+		}
+	}
+	return builder.String()
+}
+
+// isJSONPathName reports whether a member name can be written in dot notation.
+func isJSONPathName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, char := range name {
+		if char != '_' && !unicode.IsLetter(char) && (i == 0 || !unicode.IsDigit(char)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathSyntaxParse(t *testing.T) {
+	tests := []struct {
+		syntax  PathSyntax
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{syntax: GJSONPath, path: "items.0.price", want: "items.0.price"},
+		{syntax: JSONPointer, path: "", want: ""},
+		{syntax: JSONPointer, path: "/items/0/price", want: "items.0.price"},
+		{syntax: JSONPointer, path: "/a~1b/c~0d/e.f", want: `a/b.c~d.e\.f`},
+		{syntax: JSONPointer, path: "/items/*/price", want: "items.*.price"},
+		{syntax: JSONPointer, path: "items", wantErr: true},
+		{syntax: JSONPointer, path: "/a~2", wantErr: true},
+		{syntax: JSONPath, path: "$", want: ""},
+		{syntax: JSONPath, path: "$.items[0].price", want: "items.0.price"},
+		{syntax: JSONPath, path: "$.items[*].price", want: "items.*.price"},
+		{syntax: JSONPath, path: "$..price", want: "**.price"},
+		{syntax: JSONPath, path: "$['a.b'][\"it's\"]", want: `a\.b.it's`},
+		{syntax: JSONPath, path: "items", wantErr: true},
+		{syntax: JSONPath, path: "$.items[?(@.x)]", wantErr: true},
+		{syntax: JSONPath, path: "$['open", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax.String()+" "+tt.path, func(t *testing.T) {
+			got, err := tt.syntax.Parse(tt.path)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Parse(%q) = %q, %v, want %q, error %v", tt.path, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPathSyntaxFormat(t *testing.T) {
+	tests := []struct {
+		syntax PathSyntax
+		path   string
+		want   string
+	}{
+		{syntax: GJSONPath, path: "items.0.price", want: "items.0.price"},
+		{syntax: JSONPointer, path: `a/b.0.c\.d`, want: "/a~1b/0/c.d"},
+		{syntax: JSONPath, path: "", want: "$"},
+		{syntax: JSONPath, path: "items.0.price", want: "$.items[0].price"},
+		{syntax: JSONPath, path: `a\.b.it's.x-y`, want: `$['a.b']['it\'s']['x-y']`},
+	}
+	for _, tt := range tests {
+		if got := tt.syntax.Format(tt.path); got != tt.want {
+			t.Errorf("%v.Format(%q) = %q, want %q", tt.syntax, tt.path, got, tt.want)
+		}
+		if parsed, err := tt.syntax.Parse(tt.want); err != nil || parsed != tt.path {
+			t.Errorf("%v.Parse(%q) = %q, %v, want %q", tt.syntax, tt.want, parsed, err, tt.path)
+		}
+	}
+}
+
+func TestWithPathSyntax(t *testing.T) {
+	expected := []byte(`{"auth":{"token":"a"},"tags":["x","y"],"items":[{"price":1}]}`)
+	actual := []byte(`{"auth":{"token":"b"},"tags":["y","x"],"items":[{"price":2}]}`)
+
+	for _, syntax := range []struct {
+		syntax              PathSyntax
+		token, tags, prices string
+	}{
+		{syntax: JSONPointer, token: "/auth/token", tags: "/tags", prices: "/items/*/price"},
+		{syntax: JSONPath, token: "$.auth.token", tags: "$.tags", prices: "$..price"},
+	} {
+		t.Run(syntax.syntax.String(), func(t *testing.T) {
+			resp, err := CompareJSON(expected, actual, nil, true, WithPathSyntax(syntax.syntax),
+				WithRedact(syntax.token), WithUnorderedArrays(syntax.tags),
+				WithSeverityRules(SeverityRule{Pattern: syntax.prices, Label: "critical"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(resp.Actual, `"b"`) {
+				t.Errorf("expected the token to be redacted, got:\n%s", resp.Actual)
+			}
+			if resp.At("tags.0") != nil {
+				t.Errorf("expected the tags to be compared regardless of order, got %+v", resp.Entries)
+			}
+			if entry := resp.At("items.0.price"); entry == nil || entry.Severity != "critical" {
+				t.Errorf("expected the price to be labelled, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAtPathSyntaxes(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"zoo":{"animals":[1,{"age":2}]}}`), []byte(`{"zoo":{"animals":[1,{"age":3}]}}`), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"zoo.animals.1.age", "/zoo/animals/1/age", "$.zoo.animals[1].age"} {
+		if resp.At(path) == nil {
+			t.Errorf("expected an entry at %q", path)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package colorisediff
+
+import "strings"
+
+// matchPathPattern reports whether a gjson-style path matches a pattern. Patterns are paths whose components may
+// contain "*" wildcards matching any run of characters within one component, and "**" components matching any
+// number of components, including none. E.g. "data.**" matches "data" and every path below it, and
+// "items.*.price" matches the price of every item.
+func matchPathPattern(pattern, path string) bool {
+	return matchComponents(splitPath(pattern), splitPath(path))
+}
+
+// matchComponents matches the components of a path against the components of a pattern.
+func matchComponents(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(path); skip++ {
+				if matchComponents(pattern[1:], path[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 || !matchWildcard(pattern[0], path[0]) {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// matchWildcard matches a single component against a pattern in which "*" matches any run of characters.
+func matchWildcard(pattern, s string) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, pattern[:star]) {
+		return false
+	}
+	rest := pattern[star+1:]
+	for i := star; i <= len(s); i++ {
+		if matchWildcard(rest, s[i:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package colorisediff
+
+import "testing"
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "data.**", path: "data", want: true},
+		{pattern: "data.**", path: "data.user.name", want: true},
+		{pattern: "data.**", path: "meta.data", want: false},
+		{pattern: "items.*.price", path: "items.3.price", want: true},
+		{pattern: "items.*.price", path: "items.3.tax", want: false},
+		{pattern: "**.id", path: "a.b.id", want: true},
+		{pattern: "**.id", path: "id", want: true},
+		{pattern: "user.*_at", path: "user.created_at", want: true},
+		{pattern: "user.*_at", path: "user.created_by", want: false},
+		{pattern: `a\.b.c`, path: `a\.b.c`, want: true},
+		{pattern: "a.b", path: `a\.b`, want: false},
+		{pattern: "", path: "", want: true},
+	}
+	for _, tt := range tests {
+		if got := matchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package colorisediff
+
+import (
+	"strings"
+)
+
+// NormalizeForTest returns the rendered text in a form suited for assertions in tests: without ANSI sequences, with
+// "\r\n" line endings turned into "\n", without white space at the end of the lines and without trailing empty lines.
+func NormalizeForTest(text string) string {
+	text = ansiRegex.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// RenderPlain renders the colorized sides of the diff as readable text, for tests and reviews that need to see what
+// was highlighted without decoding ANSI sequences: each side follows a "--- expected" or "--- actual" header, or one
+// naming its label if the diff has labels, red spans are marked as [-removed-] and green spans as {+added+}, and the
+// result is normalized like NormalizeForTest.
+func RenderPlain(d Diff) string {
+	expectedLabel, actualLabel := d.Labels.or("expected", "actual")
+	return "--- " + expectedLabel + "\n" + markHighlights(d.Expected) + "\n--- " + actualLabel + "\n" + markHighlights(d.Actual) + "\n"
+}
+
+// markHighlights replaces the red and green spans of a colorized text with textual markers and drops the other
+// ANSI sequences.
+func markHighlights(text string) string {
+	var builder strings.Builder
+	closing := ""
+	last := 0
+	for _, loc := range ansiRegex.FindAllStringIndex(text, -1) {
+		builder.WriteString(text[last:loc[0]])
+		last = loc[1]
+		builder.WriteString(closing)
+		closing = ""
+		switch text[loc[0]:loc[1]] {
+		case "\x1b[31m", "\x1b[91m":
+			builder.WriteString("[-")
+			closing = "-]"
+		case "\x1b[32m", "\x1b[92m":
+			builder.WriteString("{+")
+			closing = "+}"
+		}
+	}
+	builder.WriteString(text[last:])
+	builder.WriteString(closing)
+	return NormalizeForTest(builder.String())
+}
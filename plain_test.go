@@ -0,0 +1,40 @@
+package colorisediff
+
+import "testing"
+
+func TestNormalizeForTest(t *testing.T) {
+	got := NormalizeForTest("\x1b[31m\"Cat\"\x1b[0m  \r\n\t{\x1b[1;33m }\t\n\n")
+	if want := "\"Cat\"\n\t{ }"; got != want {
+		t.Errorf("NormalizeForTest() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderPlain(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"id":1,"name":"Cat","tags":["a"]}`), []byte(`{"id":1,"name":"Dog","tags":["a","b"]}`), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- expected
+{
+ context:
+   "id": 1
+ "name": [-"Cat"-] ,
+ "tags": [
+   [0]: "a"
+ ]
+ }
+--- actual
+{
+ context:
+   "id": 1
+ "name": {+"Dog"+} ,
+ "tags": [
+   [0]: "a"
+   [1]: {+"b"+}
+ ]
+ }
+`
+	if got := RenderPlain(resp); got != want {
+		t.Errorf("RenderPlain() =\n%s\nwant\n%s", got, want)
+	}
+}
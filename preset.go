@@ -0,0 +1,70 @@
+package colorisediff
+
+import "fmt"
+
+// Preset names a bundle of options suited to a common style of API: its array semantics, the fields that differ
+// between runs and how much the actual document may deviate from the expected one. Presets set no numeric
+// tolerances: how far numbers may drift depends on what they measure, e.g. prices or coordinates, rather than on the
+// style of the API, and the values that do drift between runs, such as scores and timings, are noise already. Add
+// WithNumericTolerance after the preset where needed.
+type Preset int
+
+const (
+	// PresetREST suits JSON REST APIs. Arrays stay positional, keys added to the actual objects are tolerated as the
+	// API evolves, and request and trace IDs, timestamps and ETags are treated as noise.
+	PresetREST Preset = iota
+	// PresetEventStream suits streams of events, e.g. webhooks or message queues. Arrays are compared as sets and
+	// may gain elements, as events arrive in any order and streams grow, and event IDs, timestamps, offsets and
+	// sequence numbers are treated as noise.
+	PresetEventStream
+	// PresetSearch suits search APIs. Arrays stay positional, as the ranking matters, the arrays whose length
+	// differs are summarized, and relevance scores and timings are treated as noise.
+	PresetSearch
+)
+
+// presetNoise lists the noise patterns of each preset. They name exact keys at any depth, matched
+// case-insensitively, so "**.requestid" covers "meta.requestId" but not "requestIdPrefix".
+var presetNoise = map[Preset][]string{
+	PresetREST: {"**.requestid", "**.request_id", "**.traceid", "**.trace_id", "**.correlationid", "**.correlation_id",
+		"**.timestamp", "**.etag"},
+	PresetEventStream: {"**.eventid", "**.event_id", "**.timestamp", "**.offset", "**.sequence", "**.receivedat",
+		"**.received_at"},
+	PresetSearch: {"**.took", "**._score", "**.max_score", "**.score", "**.elapsed", "**.querytime", "**.query_time"},
+}
+
+// String returns the name of the preset.
+func (p Preset) String() string {
+	switch p {
+	case PresetREST:
+		return "PresetREST"
+	case PresetEventStream:
+		return "PresetEventStream"
+	case PresetSearch:
+		return "PresetSearch"
+	}
+	return fmt.Sprintf("Preset(%d)", int(p))
+}
+
+// WithPreset applies the options bundled by a preset. Options given after it can refine the preset, e.g.
+// WithPreset(PresetREST) followed by WithUnorderedArrays("items").
+func WithPreset(preset Preset) Option {
+	return func(o *options) {
+		for _, opt := range preset.options() {
+			opt(o)
+		}
+		o.defaultNoise = append(o.defaultNoise, presetNoise[preset]...)
+	}
+}
+
+// options returns the options bundled by the preset, apart from its noise.
+func (p Preset) options() []Option {
+	switch p {
+	case PresetREST:
+		return []Option{WithIgnoreExtraKeys()}
+	case PresetEventStream:
+		return []Option{WithUnorderedArrays("**"), WithIgnoreExtraElements()}
+	case PresetSearch:
+		return []Option{WithArrayLengthSummaries()}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithPreset(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   Preset
+		expected string
+		actual   string
+		total    int
+	}{
+		{name: "rest extra key and request id", preset: PresetREST, expected: `{"id":1,"meta":{"requestId":"a"}}`, actual: `{"id":1,"meta":{"requestId":"b"},"new":true}`, total: 0},
+		{name: "rest reordered array", preset: PresetREST, expected: `{"tags":["a","b"]}`, actual: `{"tags":["b","a"]}`, total: 2},
+		{name: "event stream", preset: PresetEventStream, expected: `{"events":[{"type":"a","eventId":1},{"type":"b","eventId":2}]}`, actual: `{"events":[{"type":"b","eventId":2},{"type":"a","eventId":1},{"type":"c","eventId":3}]}`, total: 0},
+		{name: "event stream missing event", preset: PresetEventStream, expected: `{"events":[{"type":"a"},{"type":"b"}]}`, actual: `{"events":[{"type":"b"}]}`, total: 1},
+		{name: "search scores", preset: PresetSearch, expected: `{"took":3,"hits":[{"id":1,"_score":1.5}]}`, actual: `{"took":9,"hits":[{"id":1,"_score":0.7}]}`, total: 0},
+		{name: "search ranking", preset: PresetSearch, expected: `{"hits":[{"id":1},{"id":2}]}`, actual: `{"hits":[{"id":2},{"id":1}]}`, total: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(tt.expected), []byte(tt.actual), nil, true, WithPreset(tt.preset))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total := resp.Stats().Total; total != tt.total {
+				t.Errorf("%v: got %d differences, want %d: %+v", tt.preset, total, tt.total, resp.Entries)
+			}
+		})
+	}
+}
+
+func TestPresetString(t *testing.T) {
+	if got := PresetSearch.String(); got != "PresetSearch" {
+		t.Errorf("PresetSearch.String() = %q", got)
+	}
+	if got := Preset(9).String(); got != "Preset(9)" {
+		t.Errorf("Preset(9).String() = %q", got)
+	}
+}
+
+func TestPresetNoiseMatchesExactKeys(t *testing.T) {
+	expected := `{"hits":[{"id":1,"_score":1.5,"scoreboard":1}],"meta":{"took":1}}`
+	actual := `{"hits":[{"id":1,"_score":0.7,"scoreboard":2}],"meta":{"took":2}}`
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithPreset(PresetSearch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noised := map[string]bool{}
+	for _, entry := range resp.Entries {
+		noised[entry.Path] = entry.Noised
+	}
+	want := map[string]bool{"hits.0._score": true, "hits.0.scoreboard": false, "meta.took": true}
+	for path, wantNoised := range want {
+		if got, ok := noised[path]; !ok || got != wantNoised {
+			t.Errorf("%s: got noised %v (present %v), want %v", path, got, ok, wantNoised)
+		}
+	}
+	if strings.Contains(resp.Expected, "_score") || !strings.Contains(resp.Expected, "scoreboard") {
+		t.Errorf("expected only the exact key to be left out as noise, got\n%s", resp.Expected)
+	}
+}
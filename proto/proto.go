@@ -0,0 +1,69 @@
+// Package proto compares protobuf messages through their canonical JSON mapping. It lives in a package of its own
+// so programs comparing plain JSON do not depend on the protobuf runtime.
+package proto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsondiff "github.com/keploy/jsonDiff"
+	"google.golang.org/protobuf/encoding/protojson"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+// UnknownKey is the key under which the unknown fields of a message are compared.
+const UnknownKey = "@unknown"
+
+// Options tunes how messages are converted before they are compared. The zero value compares the unknown fields and
+// shows fields holding their default value.
+type Options struct {
+	IgnoreUnknown    bool // IgnoreUnknown drops the unknown fields of the messages instead of comparing them under UnknownKey.
+	DefaultsAsAbsent bool // DefaultsAsAbsent omits fields holding their default value, so they compare equal to unset ones.
+}
+
+// CompareMessages compares two protobuf messages through their canonical JSON mapping and returns the colorized
+// differences. Fields are named by their proto names and compared independently of their order.
+func CompareMessages(expected, actual gproto.Message, noise map[string][]string, disableColor bool, opts ...jsondiff.Option) (jsondiff.Diff, error) {
+	return Options{}.CompareMessages(expected, actual, noise, disableColor, opts...)
+}
+
+// CompareMessages compares two protobuf messages like the package-level CompareMessages, converting them according
+// to the options.
+func (o Options) CompareMessages(expected, actual gproto.Message, noise map[string][]string, disableColor bool, opts ...jsondiff.Option) (jsondiff.Diff, error) {
+	expectedValue, err := o.Value(expected)
+	if err != nil {
+		return jsondiff.Diff{}, fmt.Errorf("marshalling expected message: %w", err)
+	}
+	actualValue, err := o.Value(actual)
+	if err != nil {
+		return jsondiff.Diff{}, fmt.Errorf("marshalling actual message: %w", err)
+	}
+	return jsondiff.CompareValues(expectedValue, actualValue, noise, disableColor, opts...)
+}
+
+// Value converts a message into the JSON-compatible representation that is compared, for front-ends embedding
+// messages in larger documents.
+func (o Options) Value(message gproto.Message) (interface{}, error) {
+	if message == nil {
+		return nil, errors.New("nil message")
+	}
+	marshaller := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: !o.DefaultsAsAbsent}
+	data, err := marshaller.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	if !o.IgnoreUnknown {
+		if unknown := message.ProtoReflect().GetUnknown(); len(unknown) > 0 {
+			if object, ok := value.(map[string]interface{}); ok {
+				object[UnknownKey] = base64.StdEncoding.EncodeToString(unknown)
+			}
+		}
+	}
+	return value, nil
+}
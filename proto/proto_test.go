@@ -0,0 +1,72 @@
+package proto
+
+import (
+	"reflect"
+	"testing"
+
+	jsondiff "github.com/keploy/jsonDiff"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestCompareMessages(t *testing.T) {
+	unknown := func(m *typepb.Field, raw []byte) *typepb.Field {
+		m.ProtoReflect().SetUnknown(raw)
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		expected *typepb.Field
+		actual   *typepb.Field
+		options  Options
+		want     []jsondiff.DiffEntry
+	}{
+		{
+			name:     "changed field",
+			expected: &typepb.Field{Name: "id", Number: 1},
+			actual:   &typepb.Field{Name: "id", Number: 2},
+			want:     []jsondiff.DiffEntry{{Path: "number", Op: jsondiff.OpChanged, Expected: float64(1), Actual: float64(2)}},
+		},
+		{
+			name:     "default value shown",
+			expected: &typepb.Field{Name: "id", Number: 1},
+			actual:   &typepb.Field{Name: "id"},
+			want:     []jsondiff.DiffEntry{{Path: "number", Op: jsondiff.OpChanged, Expected: float64(1), Actual: float64(0)}},
+		},
+		{
+			name:     "default value as absent",
+			expected: &typepb.Field{Name: "id", Number: 1},
+			actual:   &typepb.Field{Name: "id"},
+			options:  Options{DefaultsAsAbsent: true},
+			want:     []jsondiff.DiffEntry{{Path: "number", Op: jsondiff.OpRemoved, Expected: float64(1)}},
+		},
+		{
+			name:     "unknown fields compared",
+			expected: &typepb.Field{Name: "id"},
+			actual:   unknown(&typepb.Field{Name: "id"}, []byte{0xa0, 0x06, 0x01}),
+			options:  Options{DefaultsAsAbsent: true},
+			want:     []jsondiff.DiffEntry{{Path: `\@unknown`, Op: jsondiff.OpAdded, Actual: "oAYB"}},
+		},
+		{
+			name:     "unknown fields ignored",
+			expected: &typepb.Field{Name: "id"},
+			actual:   unknown(&typepb.Field{Name: "id"}, []byte{0xa0, 0x06, 0x01}),
+			options:  Options{IgnoreUnknown: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.options.CompareMessages(tt.expected, tt.actual, nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Entries, tt.want) {
+				t.Errorf("unexpected entries %+v, want %+v", resp.Entries, tt.want)
+			}
+		})
+	}
+
+	if _, err := CompareMessages(nil, &typepb.Field{}, nil, true); err == nil {
+		t.Error("expected an error for a nil message")
+	}
+}
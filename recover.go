@@ -0,0 +1,202 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"unicode/utf8"
+)
+
+// maxPanicNarrowingTrials is the number of comparisons of candidate subtrees run to narrow down the reproduction of
+// a PanicError, so a panic on a large document does not cost a comparison per member.
+const maxPanicNarrowingTrials = 64
+
+// maxPanicErrorDocument is the number of bytes of each document of a PanicError shown by its Error method.
+const maxPanicErrorDocument = 200
+
+// PanicError is returned instead of crashing the caller when the comparison or a renderer panics, e.g. on a payload
+// exercising a bug. For comparisons it carries the smallest subtree found to still trigger the panic, to report or
+// reproduce the failure with.
+// Value: The value the comparison panicked with.
+// Path: The gjson-style path of the smallest subtree still panicking when compared alone, "" for the root.
+// Expected, Actual: The minimized reproduction, the documents reduced to the members and elements on the way to
+// Path, e.g. {"items":[null,{"price":1}]}, with the redacted values masked; the inputs as given if they could not
+// be decoded, or nothing if they could not be decoded and values are redacted. Empty for renderers.
+// Stack: The stack trace of the panic.
+// Narrowing re-runs the comparison on candidate subtrees a bounded number of times, so on large documents the
+// reproduction may be a larger subtree than the smallest one.
+type PanicError struct {
+	Value    interface{}
+	Path     string
+	Expected string
+	Actual   string
+	Stack    []byte
+}
+
+// Error describes the panic with its path and the start of the minimized reproduction.
+func (e *PanicError) Error() string {
+	if e.Expected == "" && e.Actual == "" {
+		return fmt.Sprintf("panicked at %s: %v", quotePath(e.Path), e.Value)
+	}
+	return fmt.Sprintf("comparison panicked at %s: %v (expected %s, actual %s)", quotePath(e.Path), e.Value,
+		capDocument(e.Expected), capDocument(e.Actual))
+}
+
+// capDocument shortens a document of a PanicError to maxPanicErrorDocument bytes, without splitting a character.
+func capDocument(document string) string {
+	if len(document) <= maxPanicErrorDocument {
+		return document
+	}
+	end := maxPanicErrorDocument
+	for end > 0 && !utf8.RuneStart(document[end]) {
+		end--
+	}
+	return fmt.Sprintf("%s… (%d bytes)", document[:end], len(document))
+}
+
+// recoverPanic turns a panic of the function deferring it into a *PanicError stored in err, for the renderers and
+// other functions returning errors.
+func recoverPanic(err *error) {
+	if value := recover(); value != nil {
+		*err = &PanicError{Value: value, Stack: debug.Stack()}
+	}
+}
+
+// compareJSONRecovered is compareJSON converting panics into a PanicError.
+func (o *options) compareJSONRecovered(expectedJSON, actualJSON []byte, noise map[string][]string) (diff Diff, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			diff, err = Diff{}, o.panicError(value, debug.Stack(), expectedJSON, actualJSON, noise)
+		}
+	}()
+	return o.compareJSON(expectedJSON, actualJSON, noise)
+}
+
+// panicError returns the PanicError for a panic of the comparison of the documents, narrowing the documents down to
+// the smallest subtree that still panics through objects and arrays. Narrowing stops after maxPanicNarrowingTrials
+// comparisons, keeping the subtree found so far. The hooks of the options are not called while narrowing.
+func (o *options) panicError(value interface{}, stack []byte, expectedJSON, actualJSON []byte, noise map[string][]string) *PanicError {
+	panicErr := &PanicError{Value: value, Stack: stack}
+	var expected, actual interface{}
+	if json.Unmarshal(expectedJSON, &expected) != nil || json.Unmarshal(actualJSON, &actual) != nil {
+		if len(o.valueRenderers.redact) == 0 {
+			panicErr.Expected, panicErr.Actual = string(expectedJSON), string(actualJSON)
+		}
+		return panicErr
+	}
+
+	trial := *o
+	trial.onDiff, trial.metrics, trial.cache = nil, nil, nil
+	var path []string
+	trials := 0
+	for {
+		narrowed := false
+		for _, key := range childComponents(lookupComponents(expected, path), lookupComponents(actual, path)) {
+			if trials == maxPanicNarrowingTrials {
+				break
+			}
+			trials++
+			candidate := append(append([]string(nil), path...), key)
+			if trial.panics(pruneTo(expected, candidate), pruneTo(actual, candidate), noise) {
+				path, narrowed = candidate, true
+				break
+			}
+		}
+		if !narrowed {
+			break
+		}
+	}
+
+	for _, component := range path {
+		panicErr.Path = joinPath(panicErr.Path, escapePathKey(component))
+	}
+	if e, err := encodeJSON(o.valueRenderers.mask("", pruneTo(expected, path))); err == nil {
+		panicErr.Expected = string(e)
+	}
+	if a, err := encodeJSON(o.valueRenderers.mask("", pruneTo(actual, path))); err == nil {
+		panicErr.Actual = string(a)
+	}
+	return panicErr
+}
+
+// panics reports whether comparing the documents panics.
+func (o *options) panics(expected, actual interface{}, noise map[string][]string) (panicked bool) {
+	expectedJSON, err := encodeJSON(expected)
+	if err != nil {
+		return false
+	}
+	actualJSON, err := encodeJSON(actual)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	_, _ = o.compareJSON(expectedJSON, actualJSON, noise)
+	return false
+}
+
+// childComponents returns the keys of the expected and actual values if either is an object, or the indexes up to
+// the longer of them if either is an array.
+func childComponents(expected, actual interface{}) []string {
+	e, eObject := expected.(map[string]interface{})
+	a, aObject := actual.(map[string]interface{})
+	if eObject || aObject {
+		return unionKeys(e, a)
+	}
+	eArray, _ := expected.([]interface{})
+	aArray, _ := actual.([]interface{})
+	var indexes []string
+	for i := 0; i < len(eArray) || i < len(aArray); i++ {
+		indexes = append(indexes, strconv.Itoa(i))
+	}
+	return indexes
+}
+
+// lookupComponents returns the value at the unescaped path components through nested objects and arrays, or nil.
+func lookupComponents(value interface{}, components []string) interface{} {
+	for _, component := range components {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[component]
+		case []interface{}:
+			i, err := strconv.Atoi(component)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// pruneTo returns the document reduced to the members and elements on the way to the unescaped path components,
+// with the subtree at their end kept whole. The elements before the one on the way are kept as null so the indexes
+// do not shift. Objects and arrays missing on the way are left empty.
+func pruneTo(value interface{}, components []string) interface{} {
+	if len(components) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		member, ok := v[components[0]]
+		if !ok {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{components[0]: pruneTo(member, components[1:])}
+	case []interface{}:
+		i, err := strconv.Atoi(components[0])
+		if err != nil || i < 0 || i >= len(v) {
+			return []interface{}{}
+		}
+		pruned := make([]interface{}, i+1)
+		pruned[i] = pruneTo(v[i], components[1:])
+		return pruned
+	}
+	return map[string]interface{}{}
+}
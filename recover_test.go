@@ -0,0 +1,148 @@
+package colorisediff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompareJSONRecoversPanics(t *testing.T) {
+	expected := []byte(`{"a":1,"b":{"c":1,"d":1},"e":[1]}`)
+	actual := []byte(`{"a":2,"b":{"c":2,"d":2},"e":[2]}`)
+	var hooked int
+	explode := ValueRendererFunc(func(path string, _ interface{}) (string, bool) {
+		if path == "b.c" {
+			panic("boom")
+		}
+		return "", false
+	})
+
+	_, err := CompareJSON(expected, actual, nil, true, WithValueRenderer(explode), WithOnDiff(func(DiffEntry) { hooked++ }))
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if panicErr.Value != "boom" || panicErr.Path != "b.c" {
+		t.Errorf("expected the panic at b.c, got %v at %q", panicErr.Value, panicErr.Path)
+	}
+	if panicErr.Expected != `{"b":{"c":1}}` || panicErr.Actual != `{"b":{"c":2}}` {
+		t.Errorf("expected a minimized reproduction, got %s and %s", panicErr.Expected, panicErr.Actual)
+	}
+	if len(panicErr.Stack) == 0 || !strings.Contains(err.Error(), `panicked at "b.c": boom`) {
+		t.Errorf("expected the stack and a description, got %q", err.Error())
+	}
+	if hooked != 4 {
+		t.Errorf("expected the hook to run for the original comparison only, got %d calls", hooked)
+	}
+}
+
+func TestCompareJSONRecoversPanicsInArrays(t *testing.T) {
+	expected := []byte(`{"items":[{"id":1,"pw":"a"},{"id":2,"pw":"b"}],"other":1}`)
+	actual := []byte(`{"items":[{"id":1,"pw":"a"},{"id":3,"pw":"c"}],"other":2}`)
+	explode := ValueRendererFunc(func(path string, _ interface{}) (string, bool) {
+		if path == "items.1.id" {
+			panic("boom")
+		}
+		return "", false
+	})
+
+	_, err := CompareJSON(expected, actual, nil, true, WithValueRenderer(explode), WithRedact("**.pw"))
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if panicErr.Path != "items.1.id" {
+		t.Errorf("expected the panic at items.1.id, got %q", panicErr.Path)
+	}
+	if panicErr.Expected != `{"items":[null,{"id":2}]}` || panicErr.Actual != `{"items":[null,{"id":3}]}` {
+		t.Errorf("expected a minimized reproduction through the array, got %s and %s", panicErr.Expected, panicErr.Actual)
+	}
+
+	// Values are redacted in the reproduction, and Error shows only the start of long documents.
+	long := strings.Repeat("x", 1000)
+	expected = []byte(`{"pw":"secret","note":"` + long + `"}`)
+	actual = []byte(`{"pw":"other","note":"` + long + `y"}`)
+	// The check needs both members, so the reproduction cannot be narrowed below the root.
+	check := ConsistencyCheck{Name: "explode", Path: "note", Other: "pw", Check: func(_, _ interface{}) error { panic("boom") }}
+	_, err = CompareJSON(expected, actual, nil, true, WithConsistencyChecks(check), WithRedact("pw"))
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if panicErr.Path != "" || !strings.Contains(panicErr.Actual, long) {
+		t.Errorf("expected the whole reproduction in the fields, got %q at %q", panicErr.Actual, panicErr.Path)
+	}
+	if strings.Contains(panicErr.Expected, "secret") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected the redacted value to be masked, got %s", panicErr.Expected)
+	}
+	if len(err.Error()) > 2*maxPanicErrorDocument+200 {
+		t.Errorf("expected Error to cap the documents, got %d bytes", len(err.Error()))
+	}
+}
+
+func TestRenderersRecoverPanics(t *testing.T) {
+	// An entry value that panics when formatted makes the renderers panic.
+	diff := Diff{Entries: []DiffEntry{{Path: "a", Op: OpChanged, Expected: panickingValue{}, Actual: 1.0}}}
+	for name, render := range map[string]func() error{
+		"plain": func() error { _, err := diff.Render(FormatPlain); return err },
+		"template": func() error {
+			_, err := RenderTemplate(diff, "{{range .Entries}}{{value .Expected}}{{end}}")
+			return err
+		},
+		"json": func() error { _, err := diff.MarshalJSON(); return err },
+	} {
+		if err := render(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	var panicErr *PanicError
+	if _, err := diff.Render(FormatPlain); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("expected a PanicError, got %v", err)
+	}
+
+	// Header parsers that panic fall back to comparing the raw strings.
+	parser := HeaderParserFunc(func(string) (interface{}, error) { panic("boom") })
+	headers := CompareHeaders(map[string]string{"X": "a"}, map[string]string{"X": "b"}, WithHeaderParser("x", parser))
+	if len(headers.Entries) != 1 {
+		t.Errorf("expected the raw values to be compared, got %+v", headers.Entries)
+	}
+}
+
+// panickingValue panics when formatted.
+type panickingValue struct{}
+
+func (panickingValue) String() string { panic("boom") }
+
+func (panickingValue) MarshalJSON() ([]byte, error) { panic("boom") }
+
+func TestPanicErrorNarrowingIsBounded(t *testing.T) {
+	var expected, actual strings.Builder
+	expected.WriteString("{")
+	actual.WriteString("{")
+	for i := 0; i < 2*maxPanicNarrowingTrials; i++ {
+		if i > 0 {
+			expected.WriteString(",")
+			actual.WriteString(",")
+		}
+		fmt.Fprintf(&expected, `"k%03d":1`, i)
+		fmt.Fprintf(&actual, `"k%03d":2`, i)
+	}
+	expected.WriteString("}")
+	actual.WriteString("}")
+	last := fmt.Sprintf("k%03d", 2*maxPanicNarrowingTrials-1)
+	explode := ValueRendererFunc(func(path string, _ interface{}) (string, bool) {
+		if path == last {
+			panic("boom")
+		}
+		return "", false
+	})
+
+	_, err := CompareJSON([]byte(expected.String()), []byte(actual.String()), nil, true, WithValueRenderer(explode))
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if panicErr.Path != "" || !strings.Contains(panicErr.Actual, last) {
+		t.Errorf("expected narrowing to stop at the root once its budget is spent, got %q", panicErr.Path)
+	}
+}
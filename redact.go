@@ -0,0 +1,90 @@
+package colorisediff
+
+import "strconv"
+
+// redactedValue replaces the redacted values in the output and in the entries.
+const redactedValue = "<redacted>"
+
+// WithRedact masks the values at paths matching the patterns, and everything below them, as <redacted> in all
+// rendered output and in the entries, so diffs can be attached to tickets without leaking passwords, tokens or
+// personal data. The values are still compared, so a changed password is reported as a change. See SeverityRule for
+// the pattern syntax, e.g. WithRedact("**.password", "auth.token").
+func WithRedact(patterns ...string) Option {
+	return func(o *options) {
+		patterns := o.pathPatterns(patterns)
+		WithRedactFunc(func(path string, _ interface{}) bool {
+			for _, pattern := range patterns {
+				if matchPathPattern(pattern, path) || matchPathPattern(pattern+".**", path) {
+					return true
+				}
+			}
+			return false
+		})(o)
+	}
+}
+
+// WithRedactFunc masks the values for which redact returns true, like WithRedact. It is called with the gjson-style
+// path and the value of every value about to be displayed, e.g. to mask anything that looks like an email address.
+func WithRedactFunc(redact func(path string, value interface{}) bool) Option {
+	return func(o *options) {
+		o.valueRenderers.redact = append(o.valueRenderers.redact, redact)
+	}
+}
+
+// redacted reports whether the value at a gjson-style path is masked.
+func (r valueRenderers) redacted(path string, value interface{}) bool {
+	for _, redact := range r.redact {
+		if redact(path, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns a copy of an object or array found at a gjson-style path with its masked members and elements, at
+// any depth, replaced by <redacted>. Other values, and all values without redaction rules, are returned as they are.
+func (r valueRenderers) mask(path string, value interface{}) interface{} {
+	if len(r.redact) == 0 {
+		return value
+	}
+	child := func(childPath string, child interface{}) interface{} {
+		if r.redacted(childPath, child) {
+			return redactedValue
+		}
+		return r.mask(childPath, child)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			masked[key] = child(joinPath(path, escapePathKey(key)), member)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, element := range v {
+			masked[i] = child(joinPath(path, strconv.Itoa(i)), element)
+		}
+		return masked
+	}
+	return value
+}
+
+// redactEntry masks the values of an entry whose value is masked on either side, and the masked values inside the
+// objects and arrays of the other entries.
+func (o *options) redactEntry(entry DiffEntry) DiffEntry {
+	renderers := o.valueRenderers
+	if !renderers.redacted(entry.Path, entry.Expected) && !renderers.redacted(entry.Path, entry.Actual) {
+		entry.Expected = renderers.mask(entry.Path, entry.Expected)
+		entry.Actual = renderers.mask(entry.Path, entry.Actual)
+		return entry
+	}
+	if entry.Expected != nil {
+		entry.Expected = redactedValue
+	}
+	if entry.Actual != nil {
+		entry.Actual = redactedValue
+	}
+	entry.Delta = ""
+	return entry
+}
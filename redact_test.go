@@ -0,0 +1,96 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithRedact(t *testing.T) {
+	expected := []byte(`{"user":{"email":"cat@example.com","password":"hunter2","name":"Cat"},"auth":{"token":"abc","scopes":["read"]},"items":[1]}`)
+	actual := []byte(`{"user":{"email":"dog@example.com","password":"hunter3","name":"Dog"},"auth":{"token":"xyz","scopes":["write"]},"items":[1,2]}`)
+	secrets := []string{"hunter", "abc", "xyz", "read", "write", "@example.com"}
+
+	resp, err := CompareJSON(expected, actual, nil, true,
+		WithRedact("**.password", "auth"),
+		WithRedactFunc(func(_ string, value interface{}) bool {
+			s, ok := value.(string)
+			return ok && strings.Contains(s, "@")
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	structured, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := resp.Render(FormatPlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, output := range []string{resp.Expected, resp.Actual, string(structured), plain} {
+		for _, secret := range secrets {
+			if strings.Contains(output, secret) {
+				t.Errorf("output leaks %q:\n%s", secret, output)
+			}
+		}
+	}
+	for _, path := range []string{"user.password", "user.email", "auth.token", "auth.scopes.0"} {
+		if entry := resp.At(path); entry == nil || entry.Expected != redactedValue || entry.Actual != redactedValue {
+			t.Errorf("expected a redacted change at %s, got %+v", path, entry)
+		}
+	}
+	if entry := resp.At("user.name"); entry == nil || entry.Expected != "Cat" {
+		t.Errorf("expected user.name to be shown, got %+v", entry)
+	}
+	if !strings.Contains(resp.Actual, "Dog") {
+		t.Errorf("expected the unredacted values to be shown, got:\n%s", resp.Actual)
+	}
+}
+
+func TestWithRedactContextKey(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"apiKey":"secret","name":"Cat"}`), []byte(`{"apiKey":"secret","name":"Dog"}`), nil, true, WithRedact("apiKey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(resp.Expected, "secret") || !strings.Contains(resp.Expected, `"apiKey": <redacted>`) {
+		t.Errorf("expected a redacted context field, got:\n%s", resp.Expected)
+	}
+}
+
+func TestWithRedactNestedInSubtrees(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		shown    bool // shown tells whether the members beside the password are rendered.
+	}{
+		{name: "removed subtree", expected: `{"user":{"password":"hunter2secret","name":"Cat"}}`, actual: `{}`, shown: true},
+		{name: "added subtree", expected: `{}`, actual: `{"user":{"password":"hunter2secret","name":"Cat"}}`, shown: true},
+		{name: "type-changed subtree", expected: `{"user":{"password":"hunter2secret","name":"Cat"}}`, actual: `{"user":"none"}`},
+		{name: "removed array element", expected: `{"users":[{"password":"hunter2secret"}]}`, actual: `{"users":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(tt.expected), []byte(tt.actual), nil, true, WithRedact("user.password", "users.*.password"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			structured, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, output := range []string{resp.Expected, resp.Actual, string(structured)} {
+				if strings.Contains(output, "hunter2secret") {
+					t.Errorf("output leaks the password:\n%s", output)
+				}
+			}
+			if !strings.Contains(string(structured), "redacted") {
+				t.Errorf("expected the password to be masked in the entries:\n%s", structured)
+			}
+			if tt.shown && !strings.Contains(resp.Expected+resp.Actual, "Cat") {
+				t.Errorf("expected the unredacted members to be shown:\n%s\n%s", resp.Expected, resp.Actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Format selects the output produced by Diff.Render.
+type Format string
+
+const (
+	// FormatTable renders the expected and actual sides next to each other in a two-column table.
+	FormatTable Format = "table"
+	// FormatPlain renders one uncolored "-"/"+" line per difference.
+	FormatPlain Format = "plain"
+	// FormatHTML renders the differences as an HTML table.
+	FormatHTML Format = "html"
+	// FormatPatch renders the differences as an RFC 6902 JSON Patch transforming expected into actual.
+	FormatPatch Format = "patch"
+)
+
+// Render renders the diff in the requested format.
+// The table format uses the colorized Expected and Actual strings when they are set and otherwise renders
+// the structured entries, so diffs restored with UnmarshalJSON can be presented without re-comparing. It fits the
+// table into the width given by the COLUMNS environment variable, if set. A panic while rendering is returned as a
+// *PanicError, as with the other renderers returning errors.
+func (d Diff) Render(format Format) (rendered string, err error) {
+	defer recoverPanic(&err)
+	switch format {
+	case FormatTable:
+		return d.renderTable(envTerminalWidth()), nil
+	case FormatPlain:
+		return renderPlain(d.Entries), nil
+	case FormatHTML:
+		return renderHTML(d.Entries, d.Labels), nil
+	case FormatPatch:
+		return renderPatch(d.Entries)
+	case FormatSummary:
+		return d.Summary(5), nil
+	}
+	return "", fmt.Errorf("unsupported format %q", format)
+}
+
+// RenderPath renders the differences at or below a path as a two-column table like FormatTable, for tools that let
+// users click into a single field instead of scrolling the whole comparison. The path can be given in gjson style,
+// e.g. "body.items.3", or with brackets, e.g. "body.items[3]"; "" selects the whole document. It returns an error if
+// nothing differs at or below the path.
+func (d Diff) RenderPath(path string) (rendered string, err error) {
+	defer recoverPanic(&err)
+	if strings.Contains(path, "[") {
+		path = legacyToPath(path)
+	}
+	var entries []DiffEntry
+	for _, entry := range d.Entries {
+		if path == "" || entry.Path == path || strings.HasPrefix(entry.Path, path+".") {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no differences at %q", path)
+	}
+	expected, actual := renderEntrySides(entries, valueRenderers{}, defaultPalette())
+	return renderTable(expected, actual, d.Labels, envTerminalWidth()), nil
+}
+
+// UnmarshalJSON restores the structured part of a diff serialized with MarshalJSON.
+func (d *Diff) UnmarshalJSON(data []byte) error {
+	var in diffJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Version > diffSchemaVersion {
+		return fmt.Errorf("unsupported diff schema version %d", in.Version)
+	}
+	entries := make([]DiffEntry, 0, len(in.Entries))
+	for _, entry := range in.Entries {
+		entries = append(entries, entry.entry())
+	}
+	*d = Diff{Entries: entries, ArrayMappings: in.ArrayMappings, LengthChanges: in.LengthChanges,
+		AssertionFailures: in.AssertionFailures, Inconsistencies: in.Inconsistencies, Spilled: in.Spilled,
+		Warnings: in.Warnings}
+	if in.Labels != nil {
+		d.Labels = *in.Labels
+	}
+	return nil
+}
+
+// formatValue serializes a value for single-line display.
+func formatValue(value interface{}) string {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(serialized)
+}
+
+// renderEntrySides renders the entries as colorized expected and actual columns, displaying the values through
+// the renderers.
+func renderEntrySides(entries []DiffEntry, renderers valueRenderers, p palette) (string, string) {
+	red := p.sprintFunc(color.FgRed)
+	green := p.sprintFunc(color.FgGreen)
+	var expected, actual strings.Builder
+
+	for _, entry := range entries {
+		if entry.Noised {
+			if entry.Op != OpAdded {
+				expected.WriteString(breakLines(fmt.Sprintf("%s: %s", entry.Path, renderers.format(entry.Path, entry.Expected))) + "\n")
+			}
+			if entry.Op != OpRemoved {
+				actual.WriteString(breakLines(fmt.Sprintf("%s: %s", entry.Path, renderers.format(entry.Path, entry.Actual))) + "\n")
+			}
+			continue
+		}
+		if entry.Op != OpAdded {
+			expected.WriteString(breakLines(fmt.Sprintf("%s: %s", entry.Path, red(renderers.format(entry.Path, entry.Expected)))) + "\n")
+		}
+		if entry.Op != OpRemoved {
+			actual.WriteString(breakLines(fmt.Sprintf("%s: %s", entry.Path, green(renderers.format(entry.Path, entry.Actual)))) + "\n")
+		}
+	}
+	return expected.String(), actual.String()
+}
+
+// minColumnWidth is the width below which the columns of the table are not narrowed, so short differences still read
+// as two columns.
+const minColumnWidth = 20
+
+// tableOverhead is the number of characters the table adds to the widths of its two columns on every line.
+const tableOverhead = 7
+
+// renderTable renders the sides of the diff as a two-column table fitting into width characters, 0 for no limit.
+func (d Diff) renderTable(width int) string {
+	expected, actual := d.Expected, d.Actual
+	if expected == "" && actual == "" {
+		expected, actual = renderEntrySides(d.Entries, valueRenderers{}, defaultPalette())
+	}
+	return renderTable(expected, actual, d.Labels, width)
+}
+
+// renderTable lays out the expected and actual strings in a two-column table headed by the labels. Each column is
+// as wide as its longest line, but at least minColumnWidth, and lines that would make the table wider than width
+// characters are wrapped. A width of 0 or less sets no limit.
+func renderTable(expected, actual string, labels Labels, width int) string {
+	buf := &bytes.Buffer{}
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	expectedLabel, actualLabel := labels.or("Expected", "Actual")
+	table.SetHeader([]string{expectedLabel, actualLabel})
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+
+	maxColumnWidth := 0
+	if width > 0 {
+		maxColumnWidth = max((width-tableOverhead)/2, minColumnWidth)
+	}
+	cells := []string{expected, actual}
+	for i, cell := range cells {
+		cells[i] = WrapANSI(cell, maxColumnWidth)
+		table.SetColMinWidth(i, minColumnWidth)
+	}
+	table.Append(cells)
+	table.Render()
+	return buf.String()
+}
+
+// envTerminalWidth returns the terminal width given by the COLUMNS environment variable, or 0 if it is not set.
+func envTerminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width < 0 {
+		return 0
+	}
+	return width
+}
+
+// renderPlain renders one "-"/"+" line per difference. Noised differences are prefixed with "~".
+func renderPlain(entries []DiffEntry) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		expectedMarker, actualMarker := "-", "+"
+		if entry.Noised {
+			expectedMarker, actualMarker = "~", "~"
+		}
+		if entry.Op != OpAdded {
+			builder.WriteString(fmt.Sprintf("%s %s: %s\n", expectedMarker, entry.Path, formatValue(entry.Expected)))
+		}
+		if entry.Op != OpRemoved {
+			builder.WriteString(fmt.Sprintf("%s %s: %s\n", actualMarker, entry.Path, formatValue(entry.Actual)))
+		}
+	}
+	return builder.String()
+}
+
+// renderHTML renders the differences as an HTML table with one row per entry, with columns headed by the labels.
+func renderHTML(entries []DiffEntry, labels Labels) string {
+	var builder strings.Builder
+	expectedLabel, actualLabel := labels.or("Expected", "Actual")
+	builder.WriteString("<table class=\"jsondiff\">\n")
+	builder.WriteString(fmt.Sprintf("<tr><th>Path</th><th>%s</th><th>%s</th></tr>\n", html.EscapeString(expectedLabel), html.EscapeString(actualLabel)))
+	for _, entry := range entries {
+		class := string(entry.Op)
+		if entry.Noised {
+			class += " noised"
+		}
+		expected, actual := "", ""
+		if entry.Op != OpAdded {
+			expected = "<del>" + html.EscapeString(formatValue(entry.Expected)) + "</del>"
+		}
+		if entry.Op != OpRemoved {
+			actual = "<ins>" + html.EscapeString(formatValue(entry.Actual)) + "</ins>"
+		}
+		builder.WriteString(fmt.Sprintf("<tr class=\"%s\"><td>%s</td><td>%s</td><td>%s</td></tr>\n", class, html.EscapeString(entry.Path), expected, actual))
+	}
+	builder.WriteString("</table>\n")
+	return builder.String()
+}
+
+// patchOperation is a single RFC 6902 JSON Patch operation.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// renderPatch renders the non-noised differences as a JSON Patch transforming the expected document into the
+// actual one. Removals are emitted last and in reverse order so array indices stay valid while applying.
+func renderPatch(entries []DiffEntry) (string, error) {
+	operations := []patchOperation{}
+	var removals []patchOperation
+	for _, entry := range entries {
+		if entry.Noised {
+			continue
+		}
+		pointer := entry.Pointer()
+		switch entry.Op {
+		case OpAdded:
+			operations = append(operations, patchOperation{Op: "add", Path: pointer, Value: json.RawMessage(formatValue(entry.Actual))})
+		case OpRemoved:
+			removals = append(removals, patchOperation{Op: "remove", Path: pointer})
+		case OpChanged:
+			operations = append(operations, patchOperation{Op: "replace", Path: pointer, Value: json.RawMessage(formatValue(entry.Actual))})
+		}
+	}
+	for i := len(removals) - 1; i >= 0; i-- {
+		operations = append(operations, removals[i])
+	}
+	patch, err := json.MarshalIndent(operations, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(patch), nil
+}
+
+// splitPath splits a gjson-style path into its unescaped components.
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	var components []string
+	var current strings.Builder
+	escaped := false
+	for _, char := range path {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '.':
+			components = append(components, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+	return append(components, current.String())
+}
+
+// pathToPointer converts a gjson-style path into an RFC 6901 JSON Pointer.
+func pathToPointer(path string) string {
+	var builder strings.Builder
+	for _, component := range splitPath(path) {
+		builder.WriteString("/")
+		builder.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(component))
+	}
+	return builder.String()
+}
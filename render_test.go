@@ -0,0 +1,158 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRenderRestoredDiff(t *testing.T) {
+	json1 := `{"name":"Cat","tags":["a","b","c"],"meta":{"a/b":1}}`
+	json2 := `{"name":"Dog","tags":["a"],"meta":{"a/b":2},"new":null}`
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialized, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored Diff
+	if err := json.Unmarshal(serialized, &restored); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: FormatPlain,
+			expected: "- meta.a/b: 1\n+ meta.a/b: 2\n- name: \"Cat\"\n+ name: \"Dog\"\n+ new: null\n" +
+				"- tags.1: \"b\"\n- tags.2: \"c\"\n",
+		},
+		{
+			format: FormatPatch,
+			expected: `[
+  {
+    "op": "replace",
+    "path": "/meta/a~1b",
+    "value": 2
+  },
+  {
+    "op": "replace",
+    "path": "/name",
+    "value": "Dog"
+  },
+  {
+    "op": "add",
+    "path": "/new",
+    "value": null
+  },
+  {
+    "op": "remove",
+    "path": "/tags/2"
+  },
+  {
+    "op": "remove",
+    "path": "/tags/1"
+  }
+]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			fresh, err := resp.Render(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := restored.Render(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Errorf("unexpected %s output\n got: %q\nwant: %q", tt.format, got, tt.expected)
+			}
+			if fresh != got {
+				t.Errorf("restored diff renders differently\nfresh: %q\nrestored: %q", fresh, got)
+			}
+		})
+	}
+
+	table, err := restored.Render(FormatTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table, `name: "Cat"`) || !strings.Contains(table, `name: "Dog"`) {
+		t.Errorf("table is missing the changed values:\n%s", table)
+	}
+
+	if _, err := restored.Render(Format("pdf")); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestRenderTableWidth(t *testing.T) {
+	lineWidths := func(table string) (widest int) {
+		for _, line := range strings.Split(strings.TrimRight(table, "\n"), "\n") {
+			widest = max(widest, utf8.RuneCountInString(ansiRegex.ReplaceAllString(line, "")))
+		}
+		return widest
+	}
+	short := Diff{Expected: "a: 1\n", Actual: "a: 2\n"}
+	long := Diff{Expected: strings.Repeat("x", 80) + "\n", Actual: "y\n"}
+
+	t.Setenv("COLUMNS", "")
+	table, err := short.Render(FormatTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := lineWidths(table); width > 2*minColumnWidth+tableOverhead {
+		t.Errorf("expected a narrow diff not to be padded, got %d columns:\n%s", width, table)
+	}
+	table, _ = long.Render(FormatTable)
+	if !strings.Contains(table, strings.Repeat("x", 80)) {
+		t.Errorf("expected no wrapping without a width, got:\n%s", table)
+	}
+
+	t.Setenv("COLUMNS", "60")
+	table, _ = long.Render(FormatTable)
+	if width := lineWidths(table); width > 60 {
+		t.Errorf("expected the table to fit into 60 columns, got %d:\n%s", width, table)
+	}
+	if strings.Count(table, "x") != 80 {
+		t.Errorf("expected the wrapped line to be complete, got:\n%s", table)
+	}
+}
+
+func TestRenderPath(t *testing.T) {
+	json1 := `{"body":{"items":[{"price":1},{"price":2},{"price":3},{"price":4,"sku":"a"}],"total":10},"status":200}`
+	json2 := `{"body":{"items":[{"price":1},{"price":2},{"price":3},{"price":5,"sku":"b"}],"total":11},"status":201}`
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"body.items[3]", "body.items.3"} {
+		out, err := resp.RenderPath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "body.items.3.price") || !strings.Contains(out, "body.items.3.sku") ||
+			strings.Contains(out, "total") || strings.Contains(out, "status") {
+			t.Errorf("RenderPath(%q) rendered more or less than the subtree:\n%s", path, out)
+		}
+	}
+	if out, err := resp.RenderPath("body"); err != nil || !strings.Contains(out, "body.total") || strings.Contains(out, "status") {
+		t.Errorf("RenderPath(body) = %v:\n%s", err, out)
+	}
+	if _, err := resp.RenderPath("body.items[0]"); err == nil {
+		t.Error("expected an error for a path without differences")
+	}
+	if _, err := resp.RenderPath("body.item"); err == nil {
+		t.Error("expected the path to match whole keys only")
+	}
+}
@@ -0,0 +1,58 @@
+package colorisediff
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+)
+
+// TemplateData is the data passed to the templates executed by RenderTemplate.
+// Entries: The structured differences.
+// Stats: The counts of the differences.
+// Expected, Actual: The colorized sides, empty for diffs restored with UnmarshalJSON.
+type TemplateData struct {
+	Entries  []DiffEntry
+	Stats    Stats
+	Expected string
+	Actual   string
+}
+
+// TemplateFuncs returns the functions available to templates executed by RenderTemplate, for templates parsed
+// separately:
+//
+//   - red, green, yellow, bold and dim color their arguments, following the global color setting.
+//   - value formats a value as single-line JSON, e.g. {{value .Expected}}.
+//   - pointer converts a gjson-style path into a JSON Pointer, e.g. {{pointer .Path}}.
+func TemplateFuncs() template.FuncMap {
+	p := defaultPalette()
+	return template.FuncMap{
+		"red":     p.sprintFunc(color.FgRed),
+		"green":   p.sprintFunc(color.FgGreen),
+		"yellow":  p.sprintFunc(color.FgYellow),
+		"bold":    p.sprintFunc(color.Bold),
+		"dim":     p.sprintFunc(color.Faint),
+		"value":   formatValue,
+		"pointer": pathToPointer,
+	}
+}
+
+// RenderTemplate renders a diff through a text/template, so teams can produce reports in their own style without
+// forking the renderers. The template is executed with a TemplateData and can use the functions of TemplateFuncs,
+// e.g.
+//
+//	{{range .Entries}}{{if not .Noised}}{{.Path}}: {{red (value .Expected)}} -> {{green (value .Actual)}}
+//	{{end}}{{end}}{{.Stats.Total}} differences
+func RenderTemplate(diff Diff, tmpl string) (rendered string, err error) {
+	defer recoverPanic(&err)
+	parsed, err := template.New("diff").Funcs(TemplateFuncs()).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	data := TemplateData{Entries: diff.Entries, Stats: diff.Stats(), Expected: diff.Expected, Actual: diff.Actual}
+	if err := parsed.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
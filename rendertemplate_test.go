@@ -0,0 +1,53 @@
+package colorisediff
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"a/b":1,"name":"Cat","ts":1}`), []byte(`{"a/b":2,"name":"Dog","ts":2}`), map[string][]string{"ts": {}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	tests := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		{
+			name:     "entries",
+			tmpl:     "{{range .Entries}}{{if not .Noised}}{{pointer .Path}}: {{red (value .Expected)}} -> {{green (value .Actual)}}\n{{end}}{{end}}",
+			expected: "/a~1b: 1 -> 2\n/name: \"Cat\" -> \"Dog\"\n",
+		},
+		{
+			name:     "stats",
+			tmpl:     "{{.Stats.Total}} changed, {{.Stats.Noised}} noised",
+			expected: "2 changed, 1 noised",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(resp, tt.tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := RenderTemplate(resp, "{{.Missing"); err == nil {
+		t.Error("expected a parse error")
+	}
+	if _, err := RenderTemplate(resp, "{{.Missing}}"); err == nil {
+		t.Error("expected an execution error")
+	}
+}
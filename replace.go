@@ -0,0 +1,167 @@
+package colorisediff
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// replacementPreviewLength is the number of characters of each side shown for a replaced subtree.
+const replacementPreviewLength = 40
+
+// replacedNote is the note of the entry standing for a replaced subtree.
+const replacedNote = "subtree replaced"
+
+// WithReplacementDetection collapses objects and arrays replaced by a structurally unrelated value, e.g. an object
+// sharing no key with the expected one, into a single changed entry noted "subtree replaced", shown with a short
+// preview of both sides instead of pages of element-by-element differences. Values are unrelated if they are of
+// different kinds, objects sharing no key, or arrays without a common element whose first elements are unrelated;
+// only subtrees with at least two differences are collapsed.
+func WithReplacementDetection() Option {
+	return func(o *options) {
+		o.replacementDetection = true
+	}
+}
+
+// replacement holds the two sides of a replaced subtree.
+type replacement struct {
+	expected, actual interface{}
+}
+
+// replacedSubtrees returns the replaced subtrees by path, or nil if no detection is asked for. Like the tree of
+// differences, it only descends into objects present on both sides; noised subtrees are left out.
+func (o *options) replacedSubtrees(expected, actual interface{}, noise map[string][]string) map[string]replacement {
+	if !o.replacementDetection {
+		return nil
+	}
+	replaced := map[string]replacement{}
+	var walk func(path, noisePath string, expected, actual interface{})
+	walk = func(path, noisePath string, expected, actual interface{}) {
+		if checkNoise(noisePath, noise) {
+			return
+		}
+		if isContainer(expected) && isContainer(actual) && !related(expected, actual) && len(diffValues(path, noisePath, expected, actual, nil)) >= 2 {
+			replaced[path] = replacement{expected: expected, actual: actual}
+			return
+		}
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return
+		}
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range unionKeys(e, nil) {
+			if actualValue, ok := a[key]; ok {
+				walk(joinPath(path, escapePathKey(key)), noisePath+"."+key, e[key], actualValue)
+			}
+		}
+	}
+	walk("", "", expected, actual)
+	if len(replaced) == 0 {
+		return nil
+	}
+	return replaced
+}
+
+// isContainer reports whether a decoded JSON value is a non-empty object or array.
+func isContainer(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// related reports whether two decoded JSON values share structure: scalars of the same kind, objects sharing a key,
+// or arrays sharing an element or starting with related elements. Empty objects and arrays are related to any
+// value of their kind.
+func related(expected, actual interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if len(e) == 0 || len(a) == 0 {
+			return true
+		}
+		for key := range e {
+			if _, ok := a[key]; ok {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+		if len(e) == 0 || len(a) == 0 {
+			return true
+		}
+		actualKeys := map[string]bool{}
+		for _, key := range canonicalKeys(a) {
+			actualKeys[key] = true
+		}
+		for _, key := range canonicalKeys(e) {
+			if actualKeys[key] {
+				return true
+			}
+		}
+		return related(e[0], a[0])
+	}
+	return jsonTypeName(expected) == jsonTypeName(actual)
+}
+
+// collapseEntries passes the entries to visit, replacing the entries within a replaced subtree by a single entry
+// for the subtree, visited in the place of the first of them.
+func collapseEntries(replaced map[string]replacement, visit func(DiffEntry)) func(DiffEntry) {
+	if replaced == nil {
+		return visit
+	}
+	visited := map[string]bool{}
+	return func(entry DiffEntry) {
+		for path, r := range replaced {
+			if !isPathWithin(entry.Path, path) {
+				continue
+			}
+			if !visited[path] {
+				visited[path] = true
+				visit(DiffEntry{Path: path, Op: OpChanged, Expected: r.expected, Actual: r.actual, Note: replacedNote})
+			}
+			return
+		}
+		visit(entry)
+	}
+}
+
+// collapseReplaced turns the nodes of replaced subtrees into leaves marked as replaced.
+func (n *diffNode) collapseReplaced(replaced map[string]replacement) {
+	if n == nil || replaced == nil {
+		return
+	}
+	if r, ok := replaced[n.path]; ok {
+		*n = diffNode{path: n.path, op: OpChanged, expected: r.expected, actual: r.actual, replaced: true}
+		return
+	}
+	for _, child := range n.children {
+		child.collapseReplaced(replaced)
+	}
+}
+
+// renderReplaced renders a replaced subtree as one line per side holding a preview of the value.
+func renderReplaced(leaf *diffNode, red, green func(a ...interface{}) string, renderers valueRenderers) (string, string) {
+	line := func(value interface{}, paint func(a ...interface{}) string) string {
+		prefix := fmt.Sprintf(" %s: <%s> ", quotePath(leaf.path), replacedNote)
+		preview := renderers.format(leaf.path, value)
+		if utf8.RuneCountInString(preview) > replacementPreviewLength {
+			preview = string([]rune(preview)[:replacementPreviewLength]) + renderers.markers.ellipsis()
+		}
+		text := prefix + preview
+		return renderers.breakWithColor(text, paint, []colorRange{{Start: len(prefix), End: len(text)}})
+	}
+	return line(leaf.expected, red), line(leaf.actual, green)
+}
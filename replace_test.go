@@ -0,0 +1,66 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, document string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestRelated(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual string
+		want             bool
+	}{
+		{name: "objects sharing a key", expected: `{"a":1,"b":2}`, actual: `{"b":3,"c":4}`, want: true},
+		{name: "objects sharing no key", expected: `{"a":1,"b":2}`, actual: `{"c":3,"d":4}`},
+		{name: "arrays of numbers", expected: `[1,2]`, actual: `[3,4]`, want: true},
+		{name: "arrays sharing an element", expected: `[{"a":1},"x"]`, actual: `["x",{"b":2}]`, want: true},
+		{name: "arrays of unrelated objects", expected: `[{"a":1}]`, actual: `[{"b":2},{"c":3}]`},
+		{name: "different kinds", expected: `"1"`, actual: `1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := related(decodeJSON(t, tt.expected), decodeJSON(t, tt.actual)); got != tt.want {
+				t.Errorf("related(%s, %s) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithReplacementDetection(t *testing.T) {
+	expected := []byte(`{"id":1,"payload":{"user":"alice","roles":["admin"],"age":30},"meta":{"v":1,"w":2}}`)
+	actual := []byte(`{"id":1,"payload":{"error":"not found","code":404},"meta":{"v":2,"w":3}}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithReplacementDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := resp.At("payload")
+	if entry == nil || entry.Note != "subtree replaced" || entry.Op != OpChanged {
+		t.Fatalf("expected a single replaced entry for payload, got %+v", resp.Entries)
+	}
+	for _, e := range resp.Entries {
+		if strings.HasPrefix(e.Path, "payload.") {
+			t.Errorf("expected no entries below the replaced subtree, got %q", e.Path)
+		}
+	}
+	if resp.At("meta.v") == nil {
+		t.Errorf("expected related objects to be compared member by member, got %+v", resp.Entries)
+	}
+	if !strings.Contains(resp.Expected, `"payload": <subtree replaced> {"age":30`) || !strings.Contains(resp.Actual, `"payload": <subtree replaced> {"code":404`) {
+		t.Errorf("expected previews of both sides, got:\n%s\n%s", resp.Expected, resp.Actual)
+	}
+	if strings.Contains(resp.Actual, `"error"`+":") {
+		t.Errorf("expected no member-level output for the replaced subtree, got:\n%s", resp.Actual)
+	}
+}
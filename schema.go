@@ -0,0 +1,324 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithJSONSchema makes the comparison validate the actual document against a JSON Schema and annotate every entry
+// whose actual value no longer validates with the reason in DiffEntry.Violation, telling cosmetic differences from
+// contract violations. The validator understands the structural and validation keywords shared by drafts 4 to
+// 2020-12 and local "$ref"s; other keywords are ignored.
+func WithJSONSchema(schema []byte) Option {
+	return func(o *options) {
+		o.jsonSchema = schema
+	}
+}
+
+// schemaValidator validates documents against a decoded JSON Schema.
+type schemaValidator struct {
+	root interface{} // root is the decoded schema, the target of local references.
+}
+
+// schemaValidator returns the validator for the schema given with WithJSONSchema, or nil if none was given.
+func (o *options) schemaValidator() (*schemaValidator, error) {
+	if o.jsonSchema == nil {
+		return nil, nil
+	}
+	var root interface{}
+	if err := json.Unmarshal(o.jsonSchema, &root); err != nil {
+		return nil, err
+	}
+	return &schemaValidator{root: root}, nil
+}
+
+// violations validates the actual document and returns the violations by path. It returns nil for a nil validator.
+func (v *schemaValidator) violations(actual interface{}) map[string][]string {
+	if v == nil {
+		return nil
+	}
+	violations := map[string][]string{}
+	v.validate("", v.root, actual, violations, 0)
+	return violations
+}
+
+// annotateViolations records the violations affecting an entry on it: violations at or below the entry's path, and
+// for added or removed values also those of the enclosing object or array.
+func annotateViolations(entry DiffEntry, violations map[string][]string) DiffEntry {
+	if violations == nil {
+		return entry
+	}
+	var reasons []string
+	for path, messages := range violations {
+		covered := entry.Path == "" || path == entry.Path || strings.HasPrefix(path, entry.Path+".")
+		if !covered && entry.Op != OpChanged {
+			covered = path == parentPath(entry.Path)
+		}
+		if covered {
+			for _, message := range messages {
+				reasons = append(reasons, labelViolation(path, message))
+			}
+		}
+	}
+	sort.Strings(reasons)
+	entry.Violation = strings.Join(reasons, "; ")
+	return entry
+}
+
+// labelViolation prefixes a violation message with its path.
+func labelViolation(path, message string) string {
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + message
+}
+
+// parentPath returns the path of the value enclosing the one at the given gjson-style path.
+func parentPath(path string) string {
+	components := splitPath(path)
+	parent := ""
+	for _, component := range components[:max(len(components)-1, 0)] {
+		parent = joinPath(parent, escapePathKey(component))
+	}
+	return parent
+}
+
+// validate checks a value against a schema and records the violations by path.
+func (v *schemaValidator) validate(path string, schema, value interface{}, violations map[string][]string, depth int) {
+	report := func(format string, args ...interface{}) {
+		violations[path] = append(violations[path], fmt.Sprintf(format, args...))
+	}
+	if depth > maxDecodeDepth {
+		report("schema nested too deeply")
+		return
+	}
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if allowed, isBool := schema.(bool); isBool && !allowed {
+			report("not allowed")
+		}
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, found := lookupPointer(v.root, strings.TrimPrefix(ref, "#"))
+		if !strings.HasPrefix(ref, "#") || !found {
+			report("unresolvable reference %q", ref)
+			return
+		}
+		v.validate(path, target, value, violations, depth+1)
+	}
+
+	if types, ok := s["type"]; ok && !matchesType(types, value) {
+		report("expected type %s, got %s", formatValue(types), jsonType(value))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		report("value not in enum %s", formatValue(enum))
+	}
+	if constant, ok := s["const"]; ok && !reflect.DeepEqual(constant, value) {
+		report("expected constant %s", formatValue(constant))
+	}
+
+	switch val := value.(type) {
+	case float64:
+		v.validateNumber(s, val, report)
+	case string:
+		v.validateString(s, val, report)
+	case []interface{}:
+		if n, ok := s["minItems"].(float64); ok && float64(len(val)) < n {
+			report("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := s["maxItems"].(float64); ok && float64(len(val)) > n {
+			report("expected at most %v items, got %d", n, len(val))
+		}
+		prefix, _ := s["prefixItems"].([]interface{})
+		if tuple, ok := s["items"].([]interface{}); ok {
+			prefix = tuple
+		}
+		for i, item := range val {
+			itemPath := joinPath(path, strconv.Itoa(i))
+			if i < len(prefix) {
+				v.validate(itemPath, prefix[i], item, violations, depth+1)
+			} else if items, ok := s["items"]; ok && !isArray(items) {
+				v.validate(itemPath, items, item, violations, depth+1)
+			} else if additional, ok := s["additionalItems"]; ok {
+				v.validate(itemPath, additional, item, violations, depth+1)
+			}
+		}
+	case map[string]interface{}:
+		v.validateObject(path, s, val, violations, depth)
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		v.validate(path, sub, value, violations, depth+1)
+	}
+	if anyOf := schemaList(s["anyOf"]); len(anyOf) > 0 && v.countValid(anyOf, value, depth) == 0 {
+		report("does not match any schema of anyOf")
+	}
+	if oneOf := schemaList(s["oneOf"]); len(oneOf) > 0 {
+		if n := v.countValid(oneOf, value, depth); n != 1 {
+			report("matches %d schemas of oneOf instead of exactly one", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.countValid([]interface{}{not}, value, depth) == 1 {
+		report("matches the schema of not")
+	}
+}
+
+// validateObject checks the object keywords and descends into the properties. Missing required and disallowed
+// additional properties are reported at the path of the property.
+func (v *schemaValidator) validateObject(path string, s, object map[string]interface{}, violations map[string][]string, depth int) {
+	for _, name := range schemaList(s["required"]) {
+		if key, ok := name.(string); ok {
+			if _, exists := object[key]; !exists {
+				propertyPath := joinPath(path, escapePathKey(key))
+				violations[propertyPath] = append(violations[propertyPath], "required property is missing")
+			}
+		}
+	}
+	properties, _ := s["properties"].(map[string]interface{})
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	for key, child := range object {
+		childPath := joinPath(path, escapePathKey(key))
+		matched := false
+		if property, ok := properties[key]; ok {
+			v.validate(childPath, property, child, violations, depth+1)
+			matched = true
+		}
+		for pattern, property := range patterns {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+				v.validate(childPath, property, child, violations, depth+1)
+				matched = true
+			}
+		}
+		if additional, ok := s["additionalProperties"]; ok && !matched {
+			if allowed, isBool := additional.(bool); isBool && !allowed {
+				violations[childPath] = append(violations[childPath], "additional property is not allowed")
+			} else {
+				v.validate(childPath, additional, child, violations, depth+1)
+			}
+		}
+	}
+}
+
+// validateNumber checks the numeric keywords.
+func (v *schemaValidator) validateNumber(s map[string]interface{}, n float64, report func(string, ...interface{})) {
+	if minimum, ok := s["minimum"].(float64); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); (exclusive && n <= minimum) || n < minimum {
+			report("expected a value of at least %v, got %v", minimum, n)
+		}
+	}
+	if maximum, ok := s["maximum"].(float64); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); (exclusive && n >= maximum) || n > maximum {
+			report("expected a value of at most %v, got %v", maximum, n)
+		}
+	}
+	if minimum, ok := s["exclusiveMinimum"].(float64); ok && n <= minimum {
+		report("expected a value greater than %v, got %v", minimum, n)
+	}
+	if maximum, ok := s["exclusiveMaximum"].(float64); ok && n >= maximum {
+		report("expected a value less than %v, got %v", maximum, n)
+	}
+	if divisor, ok := s["multipleOf"].(float64); ok && divisor > 0 {
+		if quotient := n / divisor; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			report("expected a multiple of %v, got %v", divisor, n)
+		}
+	}
+}
+
+// validateString checks the string keywords.
+func (v *schemaValidator) validateString(s map[string]interface{}, str string, report func(string, ...interface{})) {
+	length := utf8.RuneCountInString(str)
+	if n, ok := s["minLength"].(float64); ok && float64(length) < n {
+		report("expected at least %v characters, got %d", n, length)
+	}
+	if n, ok := s["maxLength"].(float64); ok && float64(length) > n {
+		report("expected at most %v characters, got %d", n, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(str) {
+			report("does not match pattern %q", pattern)
+		}
+	}
+}
+
+// countValid returns how many of the schemas the value validates against.
+func (v *schemaValidator) countValid(schemas []interface{}, value interface{}, depth int) int {
+	valid := 0
+	for _, sub := range schemas {
+		violations := map[string][]string{}
+		v.validate("", sub, value, violations, depth+1)
+		if len(violations) == 0 {
+			valid++
+		}
+	}
+	return valid
+}
+
+// matchesType reports whether the value has one of the types named by a "type" keyword.
+func matchesType(types, value interface{}) bool {
+	names := schemaList(types)
+	if name, ok := types.(string); ok {
+		names = []interface{}{name}
+	}
+	actual := jsonType(value)
+	for _, name := range names {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value. Numbers without a fractional part are
+// integers.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// containsValue reports whether the list holds a value deeply equal to the given one.
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaList returns the value as a list, or nil if it is not an array.
+func schemaList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
+
+// isArray reports whether the value is a JSON array.
+func isArray(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
@@ -0,0 +1,83 @@
+package colorisediff
+
+import (
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": "string", "minLength": 1},
+			"status": {"$ref": "#/$defs/status"},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		},
+		"additionalProperties": false,
+		"$defs": {"status": {"enum": ["active", "inactive"]}}
+	}`
+	expected := `{"id": 1, "name": "Ann", "status": "active", "tags": ["a"], "nick": "annie"}`
+	actual := `{"id": "1", "name": "Bob", "status": "deleted", "tags": ["a", "b", "c"], "extra": true}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithJSONSchema([]byte(schema)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"extra":  "extra: additional property is not allowed",
+		"id":     `id: expected type "integer", got string`,
+		"name":   "",
+		"nick":   "",
+		"status": `status: value not in enum ["active","inactive"]`,
+		"tags.1": "tags: expected at most 2 items, got 3",
+		"tags.2": "tags: expected at most 2 items, got 3",
+	}
+	for path, violation := range want {
+		entry := resp.At(path)
+		if entry == nil {
+			t.Errorf("missing entry at %q", path)
+			continue
+		}
+		if entry.Violation != violation {
+			t.Errorf("violation at %q = %q, want %q", path, entry.Violation, violation)
+		}
+	}
+
+	if _, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithJSONSchema([]byte("{"))); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestSchemaValidator(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  interface{}
+		valid  bool
+	}{
+		{name: "integer as number", schema: `{"type": "number"}`, value: float64(3), valid: true},
+		{name: "fraction as integer", schema: `{"type": "integer"}`, value: 1.5},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, value: nil, valid: true},
+		{name: "exclusive minimum", schema: `{"exclusiveMinimum": 1}`, value: float64(1)},
+		{name: "pattern", schema: `{"pattern": "^[a-z]+$"}`, value: "abc", valid: true},
+		{name: "one of both", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`, value: float64(2)},
+		{name: "any of", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, value: float64(2), valid: true},
+		{name: "not", schema: `{"not": {"type": "null"}}`, value: nil},
+		{name: "false schema", schema: `false`, value: "x"},
+		{name: "required", schema: `{"required": ["a"]}`, value: map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := (&options{jsonSchema: []byte(tt.schema)}).schemaValidator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			violations := map[string][]string{}
+			v.validate("", v.root, tt.value, violations, 0)
+			if valid := len(violations) == 0; valid != tt.valid {
+				t.Errorf("valid = %v, want %v (violations %v)", valid, tt.valid, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	// schemaMapKeywords maps the keywords holding schemas by name, e.g. "properties", to what they call a member.
+	schemaMapKeywords = map[string]string{
+		"properties": "property", "patternProperties": "pattern property", "$defs": "definition",
+		"definitions": "definition", "dependentSchemas": "dependent schema",
+	}
+	// schemaListKeywords lists the keywords holding lists of schemas, e.g. "allOf".
+	schemaListKeywords = map[string]bool{"allOf": true, "anyOf": true, "oneOf": true, "prefixItems": true}
+	// schemaValueKeywords lists the keywords holding a single schema, e.g. "items".
+	schemaValueKeywords = map[string]bool{
+		"items": true, "additionalItems": true, "additionalProperties": true, "unevaluatedItems": true,
+		"unevaluatedProperties": true, "not": true, "if": true, "then": true, "else": true, "contains": true,
+		"propertyNames": true,
+	}
+	// schemaLowerBounds and schemaUpperBounds list the keywords whose raising, respectively lowering, rejects
+	// documents that validated before.
+	schemaLowerBounds = map[string]bool{
+		"minimum": true, "exclusiveMinimum": true, "minLength": true, "minItems": true, "minProperties": true,
+		"minContains": true,
+	}
+	schemaUpperBounds = map[string]bool{
+		"maximum": true, "exclusiveMaximum": true, "maxLength": true, "maxItems": true, "maxProperties": true,
+		"maxContains": true,
+	}
+	// schemaConstraints lists the keywords whose introduction or change rejects documents that validated before.
+	schemaConstraints = map[string]bool{"pattern": true, "format": true, "const": true, "multipleOf": true}
+)
+
+// SchemaChange describes a difference between two JSON Schemas in terms of the keyword it affects.
+// Path: The gjson-style path of the difference, as in the entries.
+// Op: Whether the value at Path was added, removed or changed.
+// Keyword: The keyword the difference falls under, e.g. "required", "" for the root.
+// Breaking: Whether documents valid under the expected schema may be rejected by the actual one, e.g. because a
+// property became required or an enum value was removed.
+// Reason: A description of the change, e.g. `"email" became required`.
+type SchemaChange struct {
+	Path     string
+	Op       Op
+	Keyword  string
+	Breaking bool
+	Reason   string
+}
+
+// String describes the change, e.g. `BREAKING "required.1": "email" became required`.
+func (c SchemaChange) String() string {
+	prefix := ""
+	if c.Breaking {
+		prefix = "BREAKING "
+	}
+	return prefix + quotePath(c.Path) + ": " + c.Reason
+}
+
+// SchemaDiff holds the differences between two JSON Schemas.
+// Diff: The differences of the whole schemas with their local references resolved.
+// Changes: A change per noticed difference, in the order of the entries.
+type SchemaDiff struct {
+	Diff
+	Changes []SchemaChange
+}
+
+// Breaking reports whether any of the changes is breaking.
+func (d SchemaDiff) Breaking() bool {
+	for _, change := range d.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary renders one line per change, breaking changes prefixed with "BREAKING".
+func (d SchemaDiff) Summary() string {
+	var builder strings.Builder
+	for _, change := range d.Changes {
+		builder.WriteString(change.String() + "\n")
+	}
+	return builder.String()
+}
+
+// CompareJSONSchemas compares two JSON Schema documents with awareness of the semantics of their keywords, for API
+// governance reviews. Local references ("$ref": "#/...") are resolved first, and "required", "enum" and "type"
+// lists are compared regardless of order. Every difference is classified by its keyword: a property becoming
+// required, an enum value or a type being removed, a property being removed, a lower bound such as minLength being
+// raised or an upper bound being lowered, and a pattern, format, const or multipleOf being introduced or changed
+// are breaking; other changes, e.g. added properties or edited descriptions, are not. Enums and types are compared
+// as sets of members, a single type counting as a set of one, so widening "string" to ["string", "null"] is
+// reported as the added member "null". The changes are listed below the colorized output, breaking ones in red.
+func CompareJSONSchemas(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (SchemaDiff, error) {
+	var expectedSchema, actualSchema interface{}
+	if err := json.Unmarshal(expected, &expectedSchema); err != nil {
+		return SchemaDiff{}, fmt.Errorf("decoding expected JSON Schema: %w", err)
+	}
+	if err := json.Unmarshal(actual, &actualSchema); err != nil {
+		return SchemaDiff{}, fmt.Errorf("decoding actual JSON Schema: %w", err)
+	}
+	opts = append([]Option{WithUnorderedArrays("required", "**.required", "enum", "**.enum", "type", "**.type")}, opts...)
+	expectedSchema, actualSchema = resolveRefs(expectedSchema, expectedSchema, nil), resolveRefs(actualSchema, actualSchema, nil)
+	diff, err := compareDecoded(expectedSchema, actualSchema, noise, disableColor, opts)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+
+	var changes []SchemaChange
+	compared := map[string]bool{}
+	for _, entry := range diff.Entries {
+		if entry.Noised {
+			continue
+		}
+		// Enums and types present on both sides are compared once, as sets.
+		components := splitPath(entry.Path)
+		keyword, rest := schemaKeyword(components)
+		if keyword == "enum" || keyword == "type" {
+			keywordPath := ""
+			for _, component := range components[:len(components)-len(rest)] {
+				keywordPath = joinPath(keywordPath, escapePathKey(component))
+			}
+			e, inExpected := lookupPath(expectedSchema, keywordPath)
+			a, inActual := lookupPath(actualSchema, keywordPath)
+			if inExpected && inActual {
+				if !compared[keywordPath] {
+					compared[keywordPath] = true
+					changes = append(changes, schemaSetChanges(keywordPath, keyword, e, a)...)
+				}
+				continue
+			}
+		}
+		changes = append(changes, classifySchemaEntry(entry))
+	}
+	p := palette{noColor: disableColor}
+	var rendered strings.Builder
+	for _, change := range changes {
+		line := change.String()
+		if change.Breaking {
+			line = p.sprintFunc(color.FgRed)(line)
+		}
+		rendered.WriteString(breakLines(line) + "\n")
+	}
+	diff.Expected += rendered.String()
+	diff.Actual += rendered.String()
+	return SchemaDiff{Diff: diff, Changes: changes}, nil
+}
+
+// schemaKeyword returns the innermost keyword a path within a schema falls under, along with the components
+// following it, e.g. "required" and ["1"] for "properties.user.required.1". Property names and indexes of schema
+// lists are skipped, so a property named "required" is not taken for the keyword.
+func schemaKeyword(components []string) (string, []string) {
+	keyword, rest := "", components
+	for i := 0; i < len(components); {
+		keyword, rest = components[i], components[i+1:]
+		switch {
+		case schemaMapKeywords[keyword] != "", schemaListKeywords[keyword]:
+			i += 2
+		case schemaValueKeywords[keyword]:
+			i++
+			// Before draft 2020-12, items can hold a list of schemas.
+			if keyword == "items" && i < len(components) {
+				if _, err := strconv.Atoi(components[i]); err == nil {
+					i++
+				}
+			}
+		default:
+			return keyword, rest
+		}
+	}
+	return keyword, rest
+}
+
+// classifySchemaEntry describes an entry of a schema comparison and tells whether it is breaking.
+func classifySchemaEntry(entry DiffEntry) SchemaChange {
+	keyword, rest := schemaKeyword(splitPath(entry.Path))
+	change := SchemaChange{Path: entry.Path, Op: entry.Op, Keyword: keyword}
+	value := entry.Actual
+	if entry.Op == OpRemoved {
+		value = entry.Expected
+	}
+	described := formatValue(value)
+	if entry.Op == OpChanged {
+		change.Reason = fmt.Sprintf("%s changed from %s to %s", keywordLabel(keyword, rest), formatValue(entry.Expected), formatValue(entry.Actual))
+	} else {
+		change.Reason = fmt.Sprintf("%s %s %s", keywordLabel(keyword, rest), described, entry.Op)
+	}
+
+	switch {
+	case schemaMapKeywords[keyword] != "" && len(rest) == 1:
+		change.Reason = fmt.Sprintf("%s %s %s", schemaMapKeywords[keyword], quoteKey(rest[0]), entry.Op)
+		change.Breaking = keyword == "properties" && entry.Op != OpAdded
+	case keyword == "properties" && len(rest) == 0:
+		change.Breaking = entry.Op == OpRemoved
+	case keyword == "required" && len(rest) == 1:
+		change.Breaking = entry.Op != OpRemoved
+		switch entry.Op {
+		case OpAdded:
+			change.Reason = described + " became required"
+		case OpRemoved:
+			change.Reason = described + " is no longer required"
+		}
+	case keyword == "required":
+		change.Breaking = entry.Op == OpChanged || entry.Op == OpAdded && valueHasElements(entry.Actual)
+	case (keyword == "enum" || keyword == "type") && len(rest) == 1:
+		change.Breaking = entry.Op != OpAdded
+	case keyword == "enum" || keyword == "type":
+		change.Breaking = entry.Op != OpRemoved
+	case schemaLowerBounds[keyword] || schemaUpperBounds[keyword]:
+		e, eOK := entry.Expected.(float64)
+		a, aOK := entry.Actual.(float64)
+		switch entry.Op {
+		case OpAdded:
+			change.Breaking = true
+		case OpChanged:
+			change.Breaking = !eOK || !aOK || schemaLowerBounds[keyword] && a > e || schemaUpperBounds[keyword] && a < e
+		}
+	case schemaConstraints[keyword]:
+		change.Breaking = entry.Op != OpRemoved
+	case keyword == "additionalProperties" && len(rest) == 0:
+		change.Breaking = entry.Op != OpRemoved && entry.Actual != true
+	case keyword == "uniqueItems":
+		change.Breaking = entry.Op != OpRemoved && entry.Actual == true
+	}
+	return change
+}
+
+// schemaSetChanges describes the members removed from and added to the enum or type at a path, in the order of the
+// schemas. Removing a member is breaking, except removing "integer" from a type still allowing "number".
+func schemaSetChanges(path, keyword string, expected, actual interface{}) []SchemaChange {
+	members := func(value interface{}) ([]interface{}, map[string]bool) {
+		list, ok := value.([]interface{})
+		if !ok {
+			list = []interface{}{value}
+		}
+		set := make(map[string]bool, len(list))
+		for _, member := range list {
+			set[formatValue(member)] = true
+		}
+		return list, set
+	}
+	expectedMembers, expectedSet := members(expected)
+	actualMembers, actualSet := members(actual)
+	label := keywordLabel(keyword, []string{""})
+
+	var changes []SchemaChange
+	for _, member := range expectedMembers {
+		if described := formatValue(member); !actualSet[described] {
+			breaking := keyword != "type" || member != "integer" || !actualSet[`"number"`]
+			changes = append(changes, SchemaChange{Path: path, Op: OpRemoved, Keyword: keyword, Breaking: breaking, Reason: fmt.Sprintf("%s %s %s", label, described, OpRemoved)})
+		}
+	}
+	for _, member := range actualMembers {
+		if described := formatValue(member); !expectedSet[described] {
+			changes = append(changes, SchemaChange{Path: path, Op: OpAdded, Keyword: keyword, Reason: fmt.Sprintf("%s %s %s", label, described, OpAdded)})
+		}
+	}
+	return changes
+}
+
+// keywordLabel names a keyword and the components following it in the reasons of changes, e.g. "enum value" for
+// an element of an enum.
+func keywordLabel(keyword string, rest []string) string {
+	switch {
+	case keyword == "":
+		return "schema"
+	case len(rest) == 1 && (keyword == "enum" || keyword == "type" || keyword == "required"):
+		return keyword + " value"
+	}
+	return keyword
+}
+
+// valueHasElements reports whether a value is a non-empty array.
+func valueHasElements(value interface{}) bool {
+	elements, ok := value.([]interface{})
+	return ok && len(elements) > 0
+}
@@ -0,0 +1,146 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareJSONSchemas(t *testing.T) {
+	expected := `{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"name": {"type": "string", "maxLength": 50, "description": "Full name"},
+			"status": {"enum": ["active", "blocked", "deleted"]},
+			"address": {"$ref": "#/$defs/Address"},
+			"nickname": {"type": "string"}
+		},
+		"$defs": {"Address": {"type": "object", "properties": {"city": {"type": "string"}}}}
+	}`
+	actual := `{
+		"type": "object",
+		"required": ["name", "id", "email"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"name": {"type": "string", "maxLength": 40, "description": "Display name"},
+			"status": {"enum": ["blocked", "active", "archived"]},
+			"address": {"$ref": "#/$defs/Address"},
+			"email": {"type": "string"}
+		},
+		"$defs": {"Address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}}
+	}`
+
+	resp, err := CompareJSONSchemas([]byte(expected), []byte(actual), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SchemaChange{
+		{Path: "$defs.Address.required", Op: OpAdded, Keyword: "required", Breaking: true, Reason: `required ["city"] added`},
+		{Path: "properties.address.required", Op: OpAdded, Keyword: "required", Breaking: true, Reason: `required ["city"] added`},
+		{Path: "properties.email", Op: OpAdded, Keyword: "properties", Reason: `property "email" added`},
+		{Path: "properties.name.description", Op: OpChanged, Keyword: "description", Reason: `description changed from "Full name" to "Display name"`},
+		{Path: "properties.name.maxLength", Op: OpChanged, Keyword: "maxLength", Breaking: true, Reason: "maxLength changed from 50 to 40"},
+		{Path: "properties.nickname", Op: OpRemoved, Keyword: "properties", Breaking: true, Reason: `property "nickname" removed`},
+		{Path: "properties.status.enum", Op: OpRemoved, Keyword: "enum", Breaking: true, Reason: `enum value "deleted" removed`},
+		{Path: "properties.status.enum", Op: OpAdded, Keyword: "enum", Reason: `enum value "archived" added`},
+		{Path: "required.2", Op: OpAdded, Keyword: "required", Breaking: true, Reason: `"email" became required`},
+	}
+	if !reflect.DeepEqual(resp.Changes, want) {
+		t.Errorf("unexpected changes\n%s", resp.Summary())
+	}
+	if !resp.Breaking() {
+		t.Error("expected the changes to be breaking")
+	}
+	if !strings.Contains(strings.ReplaceAll(resp.Actual, "\n", ""), `BREAKING "required.2": "email" became required`) {
+		t.Errorf("expected the changes below the output, got %s", resp.Actual)
+	}
+
+	if _, err := CompareJSONSchemas([]byte(`{`), []byte(actual), nil, true); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestCompareJSONSchemasSets(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []SchemaChange
+	}{
+		{
+			name:     "type widened to an array",
+			expected: `{"properties":{"a":{"type":"string"}}}`,
+			actual:   `{"properties":{"a":{"type":["string","null"]}}}`,
+			want:     []SchemaChange{{Path: "properties.a.type", Op: OpAdded, Keyword: "type", Reason: `type value "null" added`}},
+		},
+		{
+			name:     "type narrowed to a string",
+			expected: `{"type":["null","string"]}`,
+			actual:   `{"type":"string"}`,
+			want:     []SchemaChange{{Path: "type", Op: OpRemoved, Keyword: "type", Breaking: true, Reason: `type value "null" removed`}},
+		},
+		{
+			name:     "integer widened to number",
+			expected: `{"type":"integer"}`,
+			actual:   `{"type":"number"}`,
+			want: []SchemaChange{
+				{Path: "type", Op: OpRemoved, Keyword: "type", Reason: `type value "integer" removed`},
+				{Path: "type", Op: OpAdded, Keyword: "type", Reason: `type value "number" added`},
+			},
+		},
+		{
+			name:     "enum values added",
+			expected: `{"enum":["a","b"]}`,
+			actual:   `{"enum":["c","b","a","d"]}`,
+			want: []SchemaChange{
+				{Path: "enum", Op: OpAdded, Keyword: "enum", Reason: `enum value "c" added`},
+				{Path: "enum", Op: OpAdded, Keyword: "enum", Reason: `enum value "d" added`},
+			},
+		},
+		{
+			name:     "enum introduced",
+			expected: `{"type":"string"}`,
+			actual:   `{"type":"string","enum":["a"]}`,
+			want:     []SchemaChange{{Path: "enum", Op: OpAdded, Keyword: "enum", Breaking: true, Reason: `enum ["a"] added`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSONSchemas([]byte(tt.expected), []byte(tt.actual), nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Changes, tt.want) {
+				t.Errorf("unexpected changes\n%s", resp.Summary())
+			}
+		})
+	}
+}
+
+func TestClassifySchemaEntry(t *testing.T) {
+	tests := []struct {
+		entry    DiffEntry
+		keyword  string
+		breaking bool
+	}{
+		{entry: DiffEntry{Path: "required.0", Op: OpRemoved, Expected: "id"}, keyword: "required"},
+		{entry: DiffEntry{Path: "properties.required", Op: OpRemoved, Expected: map[string]interface{}{}}, keyword: "properties", breaking: true},
+		{entry: DiffEntry{Path: "properties.tags.items.enum.1", Op: OpAdded, Actual: "x"}, keyword: "enum"},
+		{entry: DiffEntry{Path: "properties.tags.items.enum.1", Op: OpRemoved, Expected: "x"}, keyword: "enum", breaking: true},
+		{entry: DiffEntry{Path: "items.0.type.1", Op: OpRemoved, Expected: "null"}, keyword: "type", breaking: true},
+		{entry: DiffEntry{Path: "allOf.1.minLength", Op: OpChanged, Expected: 1.0, Actual: 0.0}, keyword: "minLength"},
+		{entry: DiffEntry{Path: "minItems", Op: OpAdded, Actual: 1.0}, keyword: "minItems", breaking: true},
+		{entry: DiffEntry{Path: "pattern", Op: OpRemoved, Expected: "^a"}, keyword: "pattern"},
+		{entry: DiffEntry{Path: "additionalProperties", Op: OpChanged, Expected: true, Actual: false}, keyword: "additionalProperties", breaking: true},
+		{entry: DiffEntry{Path: "additionalProperties.type", Op: OpChanged, Expected: "string", Actual: "integer"}, keyword: "type", breaking: true},
+		{entry: DiffEntry{Path: "title", Op: OpAdded, Actual: "User"}, keyword: "title"},
+	}
+	for _, tt := range tests {
+		change := classifySchemaEntry(tt.entry)
+		if change.Keyword != tt.keyword || change.Breaking != tt.breaking {
+			t.Errorf("classifySchemaEntry(%s) = %q, breaking %v, want %q, breaking %v", tt.entry.Path, change.Keyword, change.Breaking, tt.keyword, tt.breaking)
+		}
+	}
+}
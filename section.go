@@ -0,0 +1,114 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// WithSectionGrouping renders the differences grouped into sections named by the first depth components of
+// their paths, each under a header with its number of changes, e.g. "headers (2 changes)" for depth 1 or
+// "body.items (14 changes)" for depth 2, instead of one stream of lines. Noised differences are listed dimmed
+// and prefixed with "~" but not counted, or left out with WithHideNoise.
+func WithSectionGrouping(depth int) Option {
+	return func(o *options) {
+		o.sectionDepth = depth
+	}
+}
+
+// section holds the entries sharing the first components of their paths.
+type section struct {
+	name    string
+	entries []DiffEntry
+}
+
+// groupSections groups the entries by the first depth components of their paths, in the order of the entries.
+func groupSections(entries []DiffEntry, depth int) []section {
+	var sections []section
+	index := map[string]int{}
+	for _, entry := range entries {
+		name := sectionName(entry.Path, depth)
+		i, ok := index[name]
+		if !ok {
+			i = len(sections)
+			index[name] = i
+			sections = append(sections, section{name: name})
+		}
+		sections[i].entries = append(sections[i].entries, entry)
+	}
+	return sections
+}
+
+// sectionName returns the path made of the first depth components of a path, or "(root)" for the root.
+func sectionName(path string, depth int) string {
+	components := splitPath(path)
+	if len(components) == 0 {
+		return "(root)"
+	}
+	if len(components) > depth {
+		components = components[:depth]
+	}
+	name := ""
+	for _, component := range components {
+		name = joinPath(name, escapePathKey(component))
+	}
+	return name
+}
+
+// renderNoisedSectionEntry renders a noised entry of a section dimmed and prefixed with "~", as in the plain format,
+// so it does not read as a change.
+func renderNoisedSectionEntry(entry DiffEntry, renderers valueRenderers, p palette) (string, string) {
+	dim := p.sprintFunc(color.Faint)
+	expected, actual := "", ""
+	if entry.Op != OpAdded {
+		expected = breakLines(dim(fmt.Sprintf("~ %s: %s", entry.Path, renderers.format(entry.Path, entry.Expected)))) + "\n"
+	}
+	if entry.Op != OpRemoved {
+		actual = breakLines(dim(fmt.Sprintf("~ %s: %s", entry.Path, renderers.format(entry.Path, entry.Actual)))) + "\n"
+	}
+	return expected, actual
+}
+
+// renderSections renders the entries as colorized expected and actual columns grouped into sections, along with
+// the spans of the entries. With hideNoise set, noised entries are left out, and so are the sections holding only
+// noised entries.
+func renderSections(entries []DiffEntry, depth int, hideNoise bool, renderers valueRenderers, p palette) (string, string, []outputSpan) {
+	bold := p.sprintFunc(color.Bold)
+	var expected, actual strings.Builder
+	spans := make([]outputSpan, 0, len(entries))
+	if hideNoise {
+		var shown []DiffEntry
+		for _, entry := range entries {
+			if !entry.Noised {
+				shown = append(shown, entry)
+			}
+		}
+		entries = shown
+	}
+	for _, s := range groupSections(entries, depth) {
+		changes := 0
+		for _, entry := range s.entries {
+			if !entry.Noised {
+				changes++
+			}
+		}
+		unit := "changes"
+		if changes == 1 {
+			unit = "change"
+		}
+		header := breakLines(bold(fmt.Sprintf("%s (%d %s)", s.name, changes, unit))) + "\n"
+		expected.WriteString(header)
+		actual.WriteString(header)
+		for _, entry := range s.entries {
+			expectedLines, actualLines := renderEntrySides([]DiffEntry{entry}, renderers, p)
+			if entry.Noised {
+				expectedLines, actualLines = renderNoisedSectionEntry(entry, renderers, p)
+			}
+			expected.WriteString(expectedLines)
+			actual.WriteString(actualLines)
+			spans = append(spans, outputSpan{path: entry.Path, expectedEnd: expected.Len(), actualEnd: actual.Len()})
+		}
+	}
+	return expected.String(), actual.String(), spans
+}
@@ -0,0 +1,69 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSectionGrouping(t *testing.T) {
+	expected := `{"headers": {"etag": "a", "date": "mon"}, "body": {"items": [1, 2], "total": 2}}`
+	actual := `{"headers": {"etag": "b", "date": "tue"}, "body": {"items": [1, 3, 4], "total": 3}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), map[string][]string{"date": {}}, true, WithSectionGrouping(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantExpected := "body.items (2 changes)\n" +
+		"body.items.1: 2\n" +
+		"body.total (1 change)\n" +
+		"body.total: 2\n" +
+		"headers.date (0 changes)\n" +
+		"~ headers.date: \"mon\"\n" +
+		"headers.etag (1 change)\n" +
+		"headers.etag: \"a\"\n"
+	if resp.Expected != wantExpected {
+		t.Errorf("unexpected expected output:\n%s", resp.Expected)
+	}
+
+	var names []string
+	for _, s := range groupSections(resp.Entries, 1) {
+		names = append(names, s.name)
+	}
+	if want := []string{"body", "headers"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sections %v, want %v", names, want)
+	}
+}
+
+func TestSectionGroupingHideNoise(t *testing.T) {
+	expected := `{"body": {"meta": {"ts": 1, "id": "a"}, "status": "ok"}}`
+	actual := `{"body": {"meta": {"ts": 2, "id": "b"}, "status": "ok"}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), map[string][]string{"ts": {}}, true, WithSectionGrouping(2), WithHideNoise())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantExpected := "body.meta (1 change)\n" +
+		"body.meta.id: \"a\"\n"
+	wantActual := "body.meta (1 change)\n" +
+		"body.meta.id: \"b\"\n"
+	if resp.Expected != wantExpected || resp.Actual != wantActual {
+		t.Errorf("unexpected output:\n%s\n%s", resp.Expected, resp.Actual)
+	}
+}
+
+func TestSectionGroupingNoiseMarked(t *testing.T) {
+	expected := `{"body": {"ts": 1, "id": "a"}}`
+	actual := `{"body": {"ts": 2, "id": "b"}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), map[string][]string{"ts": {}}, false, WithSectionGrouping(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Actual, "\x1b[2m~ body.ts: 2") {
+		t.Errorf("expected the noised entry to be dimmed and marked, got %q", resp.Actual)
+	}
+	if strings.Contains(resp.Actual, "\x1b[32m2") {
+		t.Errorf("expected the noised entry not to be colored as a change, got %q", resp.Actual)
+	}
+}
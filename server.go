@@ -0,0 +1,114 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxRequestSize caps the size of the request bodies accepted by Handler.
+const maxRequestSize = 32 << 20
+
+// FormatJSON selects the structured serialization of Diff.MarshalJSON in the responses of Handler.
+const FormatJSON Format = "json"
+
+// compareRequest is the body of a request to Handler.
+type compareRequest struct {
+	Expected json.RawMessage     `json:"expected"`
+	Actual   json.RawMessage     `json:"actual"`
+	Noise    map[string][]string `json:"noise"`
+	Format   Format              `json:"format"`
+}
+
+// Handler returns an HTTP handler exposing CompareJSON to services that are not written in Go. It accepts POST
+// requests with a JSON body holding the two documents and optional noise, e.g.
+//
+//	{"expected": {"name": "Cat"}, "actual": {"name": "Dog"}, "noise": {"ts": []}, "format": "html"}
+//
+// and responds with the diff in the requested format: "json" (the default, see Diff.MarshalJSON), or any format of
+// Diff.Render, such as "html", "patch" and "plain". The format can also be given with the "format" query parameter.
+// The options apply to every comparison.
+func Handler(opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		// A format given in the query is checked before the body is read, one given in the body before comparing.
+		query := Format(r.URL.Query().Get("format"))
+		if query != "" && !supportedFormat(query) {
+			http.Error(w, fmt.Sprintf("unsupported format %q", query), http.StatusBadRequest)
+			return
+		}
+		var req compareRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, fmt.Sprintf("decoding request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Expected == nil || req.Actual == nil {
+			http.Error(w, `the request needs both "expected" and "actual" documents`, http.StatusBadRequest)
+			return
+		}
+		format := req.Format
+		if query != "" {
+			format = query
+		}
+		if format == "" {
+			format = FormatJSON
+		}
+		if !supportedFormat(format) {
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+			return
+		}
+
+		diff, err := CompareJSON(req.Expected, req.Actual, req.Noise, true, opts...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		var body []byte
+		if format == FormatJSON {
+			body, err = json.Marshal(diff)
+		} else {
+			var out string
+			out, err = diff.Render(format)
+			body = []byte(out)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", contentType(format))
+		w.Write(body)
+	})
+}
+
+// supportedFormat reports whether Handler can respond in a format. Rendering an empty diff keeps the check in line
+// with the formats of Diff.Render.
+func supportedFormat(format Format) bool {
+	if format == FormatJSON {
+		return true
+	}
+	_, err := Diff{}.Render(format)
+	return err == nil
+}
+
+// contentType returns the media type of a rendered format.
+func contentType(format Format) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatPatch:
+		return "application/json-patch+json"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
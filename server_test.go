@@ -0,0 +1,96 @@
+package colorisediff
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		query       string
+		body        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{
+			name:        "default json",
+			method:      http.MethodPost,
+			body:        `{"expected":{"name":"Cat","ts":1},"actual":{"name":"Dog","ts":2},"noise":{"ts":[]}}`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			contains:    `{"path":"name","pointer":"/name","op":"changed","expected":"Cat","actual":"Dog"}`,
+		},
+		{
+			name:        "patch",
+			method:      http.MethodPost,
+			body:        `{"expected":{"name":"Cat"},"actual":{"name":"Dog"},"format":"patch"}`,
+			status:      http.StatusOK,
+			contentType: "application/json-patch+json",
+			contains:    `"op": "replace"`,
+		},
+		{
+			name:        "query format",
+			method:      http.MethodPost,
+			query:       "?format=html",
+			body:        `{"expected":{"name":"Cat"},"actual":{"name":"Dog"},"format":"plain"}`,
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			contains:    "<table",
+		},
+		{name: "unknown format", method: http.MethodPost, query: "?format=xml", body: `{"expected":1,"actual":2}`, status: http.StatusBadRequest, contains: "unsupported format"},
+		{name: "unknown format before reading", method: http.MethodPost, query: "?format=xml", body: `{`, status: http.StatusBadRequest, contains: "unsupported format"},
+		{name: "missing document", method: http.MethodPost, body: `{"expected":1}`, status: http.StatusBadRequest, contains: "both"},
+		{name: "malformed request", method: http.MethodPost, body: `{`, status: http.StatusBadRequest, contains: "decoding request"},
+		{name: "wrong method", method: http.MethodGet, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.query, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var body strings.Builder
+			if _, err := io.Copy(&body, resp.Body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("got status %d, want %d: %s", resp.StatusCode, tt.status, body.String())
+			}
+			if tt.contentType != "" && resp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("got content type %q, want %q", resp.Header.Get("Content-Type"), tt.contentType)
+			}
+			if !strings.Contains(body.String(), tt.contains) {
+				t.Errorf("expected the response to contain %q, got:\n%s", tt.contains, body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerValidatesFormatBeforeComparing(t *testing.T) {
+	compared := 0
+	server := httptest.NewServer(Handler(WithOnDiff(func(DiffEntry) { compared++ })))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"expected":1,"actual":2,"format":"xml"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || compared != 0 {
+		t.Errorf("expected the format to be rejected before comparing, got status %d after %d differences", resp.StatusCode, compared)
+	}
+}
@@ -0,0 +1,72 @@
+package colorisediff
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// SeverityRule assigns a label, e.g. a severity such as "critical" or "info", to the differences at paths matching
+// a pattern. Patterns are gjson-style paths in which "*" matches within a component and a "**" component matches
+// any number of components, e.g. "data.**" or "items.*.price".
+type SeverityRule struct {
+	Pattern string
+	Label   string
+}
+
+// WithSeverityRules labels every entry with the label of the first rule whose pattern matches its path, in
+// DiffEntry.Severity, and lists the labelled paths below the colorized output, so large diffs can be triaged.
+func WithSeverityRules(rules ...SeverityRule) Option {
+	return func(o *options) {
+		for _, rule := range rules {
+			o.severityRules = append(o.severityRules, SeverityRule{Pattern: o.pathPattern(rule.Pattern), Label: rule.Label})
+		}
+	}
+}
+
+// ParseSeverityRules parses rules written one per line as "pattern = label", e.g. "data.** = critical". Blank
+// lines and lines starting with "#" are ignored.
+func ParseSeverityRules(text string) ([]SeverityRule, error) {
+	var rules []SeverityRule
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		rule := strings.TrimSpace(scanner.Text())
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+		pattern, label, found := strings.Cut(rule, "=")
+		pattern, label = strings.TrimSpace(pattern), strings.TrimSpace(label)
+		if !found || label == "" {
+			return nil, fmt.Errorf("line %d: expected \"pattern = label\", got %q", line, rule)
+		}
+		rules = append(rules, SeverityRule{Pattern: pattern, Label: label})
+	}
+	return rules, scanner.Err()
+}
+
+// labelEntry sets the severity of an entry matched by the severity rules.
+func (o *options) labelEntry(entry DiffEntry) DiffEntry {
+	for _, rule := range o.severityRules {
+		if matchPathPattern(rule.Pattern, entry.Path) {
+			entry.Severity = rule.Label
+			break
+		}
+	}
+	return entry
+}
+
+// renderSeverities renders the labelled entries that are not noised as lines to be appended below a rendered side.
+func renderSeverities(entries []DiffEntry) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		if entry.Severity == "" || entry.Noised {
+			continue
+		}
+		path := entry.Path
+		if path == "" {
+			path = "(root)"
+		}
+		builder.WriteString(breakLines(fmt.Sprintf("[%s] %s", entry.Severity, path)) + "\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,42 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSeverityRules(t *testing.T) {
+	rules, err := ParseSeverityRules(`
+		# payload changes break clients
+		data.** = critical
+		meta.** = info
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"data": {"id": 1}, "meta": {"took": 3}, "other": "a"}`
+	actual := `{"data": {"id": 2}, "meta": {"took": 5}, "other": "b"}`
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithSeverityRules(rules...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range resp.Entries {
+		got = append(got, entry.Path+"="+entry.Severity)
+	}
+	want := []string{"data.id=critical", "meta.took=info", "other="}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("severities %v, want %v", got, want)
+	}
+	for _, side := range []string{resp.Expected, resp.Actual} {
+		if !strings.Contains(side, "[critical] data.id") || !strings.Contains(side, "[info] meta.took") {
+			t.Errorf("expected severity labels in the output, got %q", side)
+		}
+	}
+
+	if _, err := ParseSeverityRules("data.**"); err == nil {
+		t.Error("expected an error for a rule without a label")
+	}
+}
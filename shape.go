@@ -0,0 +1,20 @@
+package colorisediff
+
+// WithShapeOnly makes the comparison check the structure of the documents only: which keys are present, the types
+// of the values and the lengths of the arrays. Leaf values are replaced with the names of their JSON types, "string",
+// "number", "boolean" or "null", before comparing, so a changed type shows up as e.g. "string" vs "number" and
+// changed content does not show up at all.
+func WithShapeOnly() Option {
+	return func(o *options) {
+		o.shapeOnly = true
+	}
+}
+
+// shapeOf is a document normalizer replacing leaf values with the names of their JSON types.
+func shapeOf(_ string, value interface{}) (interface{}, bool) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return value, false
+	}
+	return jsonTypeName(value), false
+}
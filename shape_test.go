@@ -0,0 +1,49 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithShapeOnly(t *testing.T) {
+	json1 := `{"id":1,"name":"Cat","tags":["a","b"],"owner":null,"meta":{"active":true}}`
+
+	tests := []struct {
+		name   string
+		actual string
+		want   []DiffEntry
+	}{
+		{
+			name:   "same shape different values",
+			actual: `{"id":2,"name":"Dog","tags":["c","d"],"owner":null,"meta":{"active":false}}`,
+		},
+		{
+			name:   "changed type",
+			actual: `{"id":"2","name":"Dog","tags":["c","d"],"owner":{},"meta":{"active":false}}`,
+			want: []DiffEntry{
+				{Path: "id", Op: OpChanged, Expected: "number", Actual: "string"},
+				{Path: "owner", Op: OpChanged, Expected: "null", Actual: map[string]interface{}{}},
+			},
+		},
+		{
+			name:   "missing key and shorter array",
+			actual: `{"id":2,"tags":["c"],"owner":null,"meta":{"active":false,"admin":true}}`,
+			want: []DiffEntry{
+				{Path: "meta.admin", Op: OpAdded, Actual: "boolean"},
+				{Path: "name", Op: OpRemoved, Expected: "string"},
+				{Path: "tags.1", Op: OpRemoved, Expected: "string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(json1), []byte(tt.actual), nil, true, WithShapeOnly())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Entries, tt.want) {
+				t.Errorf("unexpected entries %+v", resp.Entries)
+			}
+		})
+	}
+}
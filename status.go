@@ -0,0 +1,48 @@
+package colorisediff
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// WithStatusClass makes CompareStatus treat status codes of the same class, e.g. 200 and 204, as equal.
+func WithStatusClass() Option {
+	return func(o *options) {
+		o.statusClass = true
+	}
+}
+
+// CompareStatus compares two HTTP status codes and returns the colorized differences, following the global color
+// setting like CompareHeaders. The codes are shown with their reason phrases, e.g. "200 OK". With WithStatusClass,
+// codes of the same class are reported as a noised entry and not rendered.
+func CompareStatus(expected, actual int, opts ...Option) Diff {
+	o := newOptions(opts)
+	if expected == actual {
+		return Diff{Labels: o.labels}
+	}
+	entry := DiffEntry{Path: "status", Op: OpChanged, Expected: expected, Actual: actual}
+	if o.statusClass && expected/100 == actual/100 {
+		entry.Noised = true
+		entry.Note = fmt.Sprintf("same status class %dxx", expected/100)
+		return Diff{Entries: []DiffEntry{entry}, Labels: o.labels}
+	}
+
+	p := defaultPalette()
+	expectedText, actualText := statusText(expected), statusText(actual)
+	offsetsExpected, offsetsActual, _ := diffArrayRange(expectedText, actualText)
+	return Diff{
+		Expected: breakLines("status: "+breakSliceWithColor(expectedText, p.sprintFunc(color.FgHiRed), offsetsExpected)) + "\n",
+		Actual:   breakLines("status: "+breakSliceWithColor(actualText, p.sprintFunc(color.FgHiGreen), offsetsActual)) + "\n",
+		Entries:  []DiffEntry{entry},
+		Labels:   o.labels,
+	}
+}
+
+// statusText returns a status code followed by its reason phrase, if it has one.
+func statusText(code int) string {
+	return strings.TrimSpace(strconv.Itoa(code) + " " + http.StatusText(code))
+}
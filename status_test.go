@@ -0,0 +1,74 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected int
+		actual   int
+		opts     []Option
+		want     []DiffEntry
+		rendered bool
+	}{
+		{name: "equal", expected: 200, actual: 200},
+		{
+			name:     "different",
+			expected: 200,
+			actual:   404,
+			want:     []DiffEntry{{Path: "status", Op: OpChanged, Expected: 200, Actual: 404}},
+			rendered: true,
+		},
+		{
+			name:     "same class without the option",
+			expected: 200,
+			actual:   204,
+			want:     []DiffEntry{{Path: "status", Op: OpChanged, Expected: 200, Actual: 204}},
+			rendered: true,
+		},
+		{
+			name:     "same class",
+			expected: 200,
+			actual:   204,
+			opts:     []Option{WithStatusClass()},
+			want:     []DiffEntry{{Path: "status", Op: OpChanged, Expected: 200, Actual: 204, Noised: true, Note: "same status class 2xx"}},
+		},
+		{
+			name:     "different class",
+			expected: 201,
+			actual:   500,
+			opts:     []Option{WithStatusClass()},
+			want:     []DiffEntry{{Path: "status", Op: OpChanged, Expected: 201, Actual: 500}},
+			rendered: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := CompareStatus(tt.expected, tt.actual, tt.opts...)
+			if !reflect.DeepEqual(resp.Entries, tt.want) {
+				t.Errorf("unexpected entries %+v", resp.Entries)
+			}
+			if rendered := resp.Expected != ""; rendered != tt.rendered {
+				t.Errorf("rendered = %v, want %v:\n%s", rendered, tt.rendered, resp.Expected)
+			}
+		})
+	}
+
+	resp := CompareStatus(200, 404)
+	if got := NormalizeForTest(resp.Expected); !strings.Contains(got, "status: 200 OK") {
+		t.Errorf("unexpected expected output %q", got)
+	}
+	if got := NormalizeForTest(resp.Actual); !strings.Contains(got, "status: 404 Not Found") {
+		t.Errorf("unexpected actual output %q", got)
+	}
+}
+
+func TestStatusText(t *testing.T) {
+	if got := statusText(599); got != "599" {
+		t.Errorf("statusText(599) = %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package colorisediff
+
+import "fmt"
+
+// Strictness names a preset of options controlling how much of the actual document has to match the expected one.
+type Strictness int
+
+const (
+	// Strict compares every key, element and value exactly. It is the default.
+	Strict Strictness = iota
+	// SubsetKeys tolerates keys present in the actual objects only, like WithIgnoreExtraKeys.
+	SubsetKeys
+	// SubsetArrays compares arrays as sets and tolerates elements present in the actual arrays only, like
+	// WithUnorderedArrays("**") combined with WithIgnoreExtraElements.
+	SubsetArrays
+	// Lenient combines SubsetKeys and SubsetArrays and also ignores differences in white space and escaping, like
+	// WithStringNormalizers(CollapseWhitespace) and WithEscapeNormalization, so only what the expected document
+	// states is checked.
+	Lenient
+)
+
+// String returns the name of the strictness level.
+func (s Strictness) String() string {
+	switch s {
+	case Strict:
+		return "Strict"
+	case SubsetKeys:
+		return "SubsetKeys"
+	case SubsetArrays:
+		return "SubsetArrays"
+	case Lenient:
+		return "Lenient"
+	}
+	return fmt.Sprintf("Strictness(%d)", int(s))
+}
+
+// WithStrictness applies the options bundled by a strictness level. Options given after it can refine the preset,
+// e.g. WithStrictness(SubsetKeys) followed by WithUnorderedArrays("items").
+func WithStrictness(level Strictness) Option {
+	return func(o *options) {
+		for _, opt := range level.options() {
+			opt(o)
+		}
+	}
+}
+
+// options returns the options bundled by the strictness level.
+func (s Strictness) options() []Option {
+	subsetArrays := []Option{WithUnorderedArrays("**"), WithIgnoreExtraElements()}
+	switch s {
+	case SubsetKeys:
+		return []Option{WithIgnoreExtraKeys()}
+	case SubsetArrays:
+		return subsetArrays
+	case Lenient:
+		return append(subsetArrays, WithIgnoreExtraKeys(), WithStringNormalizers(CollapseWhitespace), WithEscapeNormalization())
+	}
+	return nil
+}
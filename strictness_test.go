@@ -0,0 +1,42 @@
+package colorisediff
+
+import "testing"
+
+func TestWithStrictness(t *testing.T) {
+	expected := `{"name":"Cat &amp; Dog","tags":["a","b"]}`
+	tests := []struct {
+		name   string
+		actual string
+		level  Strictness
+		total  int
+	}{
+		{name: "strict extra key", actual: `{"name":"Cat &amp; Dog","tags":["a","b"],"id":1}`, level: Strict, total: 1},
+		{name: "subset keys extra key", actual: `{"name":"Cat &amp; Dog","tags":["a","b"],"id":1}`, level: SubsetKeys, total: 0},
+		{name: "subset keys reordered array", actual: `{"name":"Cat &amp; Dog","tags":["b","a"]}`, level: SubsetKeys, total: 2},
+		{name: "subset arrays reordered array", actual: `{"name":"Cat &amp; Dog","tags":["c","b","a"]}`, level: SubsetArrays, total: 0},
+		{name: "subset arrays missing element", actual: `{"name":"Cat &amp; Dog","tags":["b"]}`, level: SubsetArrays, total: 1},
+		{name: "subset arrays extra key", actual: `{"name":"Cat &amp; Dog","tags":["a","b"],"id":1}`, level: SubsetArrays, total: 1},
+		{name: "lenient", actual: `{"name":" Cat  & Dog","tags":["b","a","c"],"id":1}`, level: Lenient, total: 0},
+		{name: "lenient changed value", actual: `{"name":"Cat","tags":["a","b"]}`, level: Lenient, total: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := CompareJSON([]byte(expected), []byte(tt.actual), nil, true, WithStrictness(tt.level))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total := resp.Stats().Total; total != tt.total {
+				t.Errorf("%v: got %d differences, want %d: %+v", tt.level, total, tt.total, resp.Entries)
+			}
+		})
+	}
+}
+
+func TestStrictnessString(t *testing.T) {
+	if got := Lenient.String(); got != "Lenient" {
+		t.Errorf("Lenient.String() = %q", got)
+	}
+	if got := Strictness(9).String(); got != "Strictness(9)" {
+		t.Errorf("Strictness(9).String() = %q", got)
+	}
+}
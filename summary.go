@@ -0,0 +1,108 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// summarySnippetLength caps the length of the values quoted in a summary.
+const summarySnippetLength = 40
+
+// FormatSummary renders a short message sized for chat webhooks, listing the first five differences. See
+// Diff.Summary.
+const FormatSummary Format = "summary"
+
+// Summary renders a compact message for Slack, Teams or other Markdown-capable chats: a pass/fail emoji with the
+// counts of the differences, followed by up to maxPaths changed paths with shortened old → new values, and the
+// failed assertions and inconsistencies likewise. Noised differences are counted but not listed.
+func (d Diff) Summary(maxPaths int) string {
+	stats := d.Stats()
+	var builder strings.Builder
+	if !stats.Failed() {
+		builder.WriteString("✅ No differences")
+		if stats.Noised > 0 {
+			builder.WriteString(fmt.Sprintf(" (%d noised)", stats.Noised))
+		}
+		builder.WriteString("\n")
+		return builder.String()
+	}
+	if stats.Total > 0 {
+		d.summarizeDifferences(&builder, stats, maxPaths)
+	}
+	summarizeFailures(&builder, "failed assertion", "failed assertions", len(d.AssertionFailures), maxPaths, func(i int) (string, string) {
+		return d.AssertionFailures[i].Expression, d.AssertionFailures[i].Reason
+	})
+	summarizeFailures(&builder, "inconsistency", "inconsistencies", len(d.Inconsistencies), maxPaths, func(i int) (string, string) {
+		return d.Inconsistencies[i].Check, d.Inconsistencies[i].Reason
+	})
+	return builder.String()
+}
+
+// summarizeFailures writes the count of failures of a kind, e.g. failed assertions, and up to maxPaths of them
+// described by what failed and why.
+func summarizeFailures(builder *strings.Builder, unit, units string, count, maxPaths int, failure func(i int) (string, string)) {
+	if count == 0 {
+		return
+	}
+	if count != 1 {
+		unit = units
+	}
+	builder.WriteString(fmt.Sprintf("❌ %d %s\n", count, unit))
+	for i := 0; i < count; i++ {
+		if i == maxPaths {
+			builder.WriteString(fmt.Sprintf("…and %d more\n", count-i))
+			break
+		}
+		what, why := failure(i)
+		builder.WriteString(fmt.Sprintf("• `%s`: %s\n", summarySnippet(what), why))
+	}
+}
+
+// summarizeDifferences writes the counts of the differences and up to maxPaths of them.
+func (d Diff) summarizeDifferences(builder *strings.Builder, stats Stats, maxPaths int) {
+
+	unit := "differences"
+	if stats.Total == 1 {
+		unit = "difference"
+	}
+	builder.WriteString(fmt.Sprintf("❌ %d %s (%d added, %d removed, %d changed", stats.Total, unit, stats.Added, stats.Removed, stats.Changed))
+	if stats.Noised > 0 {
+		builder.WriteString(fmt.Sprintf(", %d noised", stats.Noised))
+	}
+	builder.WriteString(")\n")
+
+	listed := 0
+	for _, entry := range d.Entries {
+		if entry.Noised {
+			continue
+		}
+		if listed == maxPaths {
+			builder.WriteString(fmt.Sprintf("…and %d more\n", stats.Total-listed))
+			break
+		}
+		path := entry.Path
+		if path == "" {
+			path = "(root)"
+		}
+		expected, actual := "_missing_", "_missing_"
+		if entry.Op != OpAdded {
+			expected = "`" + summarySnippet(formatValue(entry.Expected)) + "`"
+		}
+		if entry.Op != OpRemoved {
+			actual = "`" + summarySnippet(formatValue(entry.Actual)) + "`"
+		}
+		builder.WriteString(fmt.Sprintf("• `%s`: %s → %s\n", summarySnippet(path), expected, actual))
+		listed++
+	}
+}
+
+// summarySnippet shortens text for a summary to summarySnippetLength runes and replaces backticks, which would end
+// the inline code span.
+func summarySnippet(text string) string {
+	text = strings.ReplaceAll(text, "`", "'")
+	if utf8.RuneCountInString(text) > summarySnippetLength {
+		text = string([]rune(text)[:summarySnippetLength-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,84 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	long := strings.Repeat("x", 60)
+	diff := Diff{Entries: []DiffEntry{
+		{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"},
+		{Path: "new", Op: OpAdded, Actual: nil},
+		{Path: "tags.1", Op: OpRemoved, Expected: "b`c"},
+		{Path: "ts", Op: OpChanged, Expected: 1.0, Actual: 2.0, Noised: true},
+		{Path: "text", Op: OpChanged, Expected: long, Actual: "y"},
+	}}
+
+	tests := []struct {
+		name     string
+		diff     Diff
+		maxPaths int
+		expected string
+	}{
+		{
+			name:     "all paths",
+			diff:     diff,
+			maxPaths: 5,
+			expected: "❌ 4 differences (1 added, 1 removed, 2 changed, 1 noised)\n" +
+				"• `name`: `\"Cat\"` → `\"Dog\"`\n" +
+				"• `new`: _missing_ → `null`\n" +
+				"• `tags.1`: `\"b'c\"` → _missing_\n" +
+				"• `text`: `\"" + strings.Repeat("x", 38) + "…` → `\"y\"`\n",
+		},
+		{
+			name:     "top paths",
+			diff:     diff,
+			maxPaths: 1,
+			expected: "❌ 4 differences (1 added, 1 removed, 2 changed, 1 noised)\n" +
+				"• `name`: `\"Cat\"` → `\"Dog\"`\n" +
+				"…and 3 more\n",
+		},
+		{
+			name:     "noised only",
+			diff:     Diff{Entries: diff.Entries[3:4]},
+			maxPaths: 5,
+			expected: "✅ No differences (1 noised)\n",
+		},
+		{
+			name:     "empty",
+			maxPaths: 5,
+			expected: "✅ No differences\n",
+		},
+		{
+			name: "failed assertions",
+			diff: Diff{Entries: diff.Entries[3:4], AssertionFailures: []AssertionFailure{
+				{Expression: "total >= 0", Reason: "total is -1"},
+				{Expression: "count > 0", Reason: "count is 0"},
+			}},
+			maxPaths: 1,
+			expected: "❌ 2 failed assertions\n" +
+				"• `total >= 0`: total is -1\n" +
+				"…and 1 more\n",
+		},
+		{
+			name:     "inconsistencies",
+			diff:     Diff{Inconsistencies: []Inconsistency{{Check: "items vs count", Path: "items", Other: "count", Reason: "items has length 3, count is 2"}}},
+			maxPaths: 5,
+			expected: "❌ 1 inconsistency\n" +
+				"• `items vs count`: items has length 3, count is 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.diff.Summary(tt.maxPaths); got != tt.expected {
+				t.Errorf("Summary() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	rendered, err := diff.Render(FormatSummary)
+	if err != nil || rendered != diff.Summary(5) {
+		t.Errorf("Render(FormatSummary) = %q, %v", rendered, err)
+	}
+}
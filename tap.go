@@ -0,0 +1,104 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NamedDiff is the diff of a named comparison, e.g. of a test case, as reported by RenderTAP.
+type NamedDiff struct {
+	Name string
+	Diff Diff
+}
+
+// tapDiagnostic is the YAML diagnostic block attached to a failing TAP test point.
+type tapDiagnostic struct {
+	Message           string             `yaml:"message"`
+	Stats             Stats              `yaml:"stats"`
+	Differences       []tapDifference    `yaml:"differences,omitempty"`
+	AssertionFailures []AssertionFailure `yaml:"assertionFailures,omitempty"`
+	Inconsistencies   []Inconsistency    `yaml:"inconsistencies,omitempty"`
+}
+
+// tapDifference describes a single difference in a TAP diagnostic block.
+type tapDifference struct {
+	Path     string      `yaml:"path"`
+	Op       Op          `yaml:"op"`
+	Expected interface{} `yaml:"expected,omitempty"`
+	Actual   interface{} `yaml:"actual,omitempty"`
+	Note     string      `yaml:"note,omitempty"`
+}
+
+// RenderTAP renders the results of named comparisons in the Test Anything Protocol, version 13: one "ok" test point
+// per comparison whose diff is OK, and one "not ok" test point with a YAML diagnostic block listing the differences
+// failed assertions and inconsistencies otherwise, e.g.
+//
+//	TAP version 13
+//	1..2
+//	ok 1 - GET /users
+//	not ok 2 - POST /orders
+//	  ---
+//	  message: 1 difference
+//	  ...
+func RenderTAP(results []NamedDiff) (rendered string, err error) {
+	defer recoverPanic(&err)
+	var builder strings.Builder
+	builder.WriteString("TAP version 13\n")
+	builder.WriteString(fmt.Sprintf("1..%d\n", len(results)))
+	for i, result := range results {
+		name := strings.ReplaceAll(result.Name, "#", `\#`)
+		stats := result.Diff.Stats()
+		if !stats.Failed() {
+			builder.WriteString(fmt.Sprintf("ok %d - %s\n", i+1, name))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("not ok %d - %s\n", i+1, name))
+
+		diagnostic := tapDiagnostic{Message: tapMessage(stats), Stats: stats, AssertionFailures: result.Diff.AssertionFailures,
+			Inconsistencies: result.Diff.Inconsistencies}
+		for _, entry := range result.Diff.Entries {
+			if !entry.Noised {
+				diagnostic.Differences = append(diagnostic.Differences, tapDifference{
+					Path:     entry.Path,
+					Op:       entry.Op,
+					Expected: entry.Expected,
+					Actual:   entry.Actual,
+					Note:     entry.Note,
+				})
+			}
+		}
+		encoded, err := yaml.Marshal(diagnostic)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString("  ---\n")
+		for _, line := range strings.Split(strings.TrimRight(string(encoded), "\n"), "\n") {
+			builder.WriteString("  " + line + "\n")
+		}
+		builder.WriteString("  ...\n")
+	}
+	return builder.String(), nil
+}
+
+// tapMessage counts the failures of a comparison for its diagnostic block, e.g. "2 differences, 1 failed assertion".
+func tapMessage(stats Stats) string {
+	var parts []string
+	for _, count := range []struct {
+		n           int
+		unit, units string
+	}{
+		{stats.Total, "difference", "differences"},
+		{stats.AssertionFailures, "failed assertion", "failed assertions"},
+		{stats.Inconsistencies, "inconsistency", "inconsistencies"},
+	} {
+		switch {
+		case count.n == 1:
+			parts = append(parts, "1 "+count.unit)
+		case count.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.units))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,78 @@
+package colorisediff
+
+import "testing"
+
+func TestRenderTAP(t *testing.T) {
+	results := []NamedDiff{
+		{Name: "GET /users"},
+		{Name: "POST /orders #2", Diff: Diff{Entries: []DiffEntry{
+			{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"},
+			{Path: "ts", Op: OpChanged, Expected: 1.0, Actual: 2.0, Noised: true},
+			{Path: "tags.1", Op: OpRemoved, Expected: "b"},
+		}}},
+		{Name: "GET /health", Diff: Diff{Entries: []DiffEntry{{Path: "ts", Op: OpAdded, Actual: 1.0, Noised: true}}}},
+		{Name: "GET /totals", Diff: Diff{AssertionFailures: []AssertionFailure{{Expression: "total >= 0", Reason: "total is -1"}}}},
+		{Name: "GET /items", Diff: Diff{Inconsistencies: []Inconsistency{{Check: "items vs count", Path: "items", Other: "count", Reason: "items has length 3, count is 2"}}}},
+	}
+
+	got, err := RenderTAP(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `TAP version 13
+1..5
+ok 1 - GET /users
+not ok 2 - POST /orders \#2
+  ---
+  message: 2 differences
+  stats:
+      added: 0
+      removed: 1
+      changed: 1
+      noised: 1
+      total: 2
+  differences:
+      - path: name
+        op: changed
+        expected: Cat
+        actual: Dog
+      - path: tags.1
+        op: removed
+        expected: b
+  ...
+ok 3 - GET /health
+not ok 4 - GET /totals
+  ---
+  message: 1 failed assertion
+  stats:
+      added: 0
+      removed: 0
+      changed: 0
+      noised: 0
+      total: 0
+      assertionFailures: 1
+  assertionFailures:
+      - expression: total >= 0
+        reason: total is -1
+  ...
+not ok 5 - GET /items
+  ---
+  message: 1 inconsistency
+  stats:
+      added: 0
+      removed: 0
+      changed: 0
+      noised: 0
+      total: 0
+      inconsistencies: 1
+  inconsistencies:
+      - check: items vs count
+        path: items
+        other: count
+        reason: items has length 3, count is 2
+  ...
+`
+	if got != expected {
+		t.Errorf("RenderTAP() =\n%s\nwant\n%s", got, expected)
+	}
+}
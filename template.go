@@ -0,0 +1,104 @@
+package colorisediff
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// keployTemplateRegex matches a keploy template variable such as "{{int .id}}" or "{{.token}}", capturing its type.
+var keployTemplateRegex = regexp.MustCompile(`\{\{\s*(?:(int|float|string|bool)\s+)?\.[A-Za-z_][A-Za-z0-9_]*\s*\}\}`)
+
+// typeMatcherRegex matches a string consisting of a type matcher such as "{{uuid}}", capturing its name.
+var typeMatcherRegex = regexp.MustCompile(`^\{\{\s*(uuid|timestamp)\s*\}\}$`)
+
+// uuidRegex matches a UUID in its canonical textual form.
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// typeMatchers lists the type matchers by name, in the order GenerateTemplate tries them, with the test of the
+// strings they match.
+var typeMatchers = []struct {
+	name    string
+	matches func(s string) bool
+}{
+	{name: "uuid", matches: uuidRegex.MatchString},
+	{name: "timestamp", matches: isTimestamp},
+}
+
+// isTimestamp reports whether a string holds an RFC 3339 timestamp, e.g. "2024-01-02T03:04:05Z".
+func isTimestamp(s string) bool {
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
+
+// WithKeployTemplates makes the comparison recognize keploy template variables in the expected document, e.g.
+// "{{int .id}}" or "{{string .token}}", and treat them as matchers of the corresponding type instead of literal
+// values. A value consisting of a typed variable matches any actual value of that type; "int" matches integral
+// numbers, "float" any number. Untyped variables such as "{{.id}}" match any scalar, and variables embedded in a
+// longer string, e.g. "Bearer {{.token}}", match any string with the same surrounding text. The type matchers
+// "{{uuid}}" and "{{timestamp}}", as written by GenerateTemplate, match any UUID and any RFC 3339 timestamp.
+func WithKeployTemplates() Option {
+	return func(o *options) {
+		o.keployTemplates = true
+	}
+}
+
+// matchKeployTemplate is a transform replacing an expected keploy template with the actual value it matches.
+func matchKeployTemplate(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	template, ok := expected.(string)
+	if !ok || !strings.Contains(template, "{{") || expected == actual {
+		return nil, nil, "", false
+	}
+	if !templateMatches(template, actual) {
+		return nil, nil, "", false
+	}
+	return actual, actual, "", true
+}
+
+// templateMatches reports whether an actual value matches a string holding keploy template variables.
+func templateMatches(template string, actual interface{}) bool {
+	if match := typeMatcherRegex.FindStringSubmatch(template); match != nil {
+		s, ok := actual.(string)
+		for _, matcher := range typeMatchers {
+			if matcher.name == match[1] {
+				return ok && matcher.matches(s)
+			}
+		}
+		return false
+	}
+	if match := keployTemplateRegex.FindStringSubmatchIndex(template); match != nil && match[0] == 0 && match[1] == len(template) {
+		kind := ""
+		if match[2] >= 0 {
+			kind = template[match[2]:match[3]]
+		}
+		switch a := actual.(type) {
+		case float64:
+			return kind == "" || kind == "float" || kind == "int" && a == math.Trunc(a)
+		case string:
+			return kind == "" || kind == "string"
+		case bool:
+			return kind == "" || kind == "bool"
+		}
+		return false
+	}
+
+	s, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range keployTemplateRegex.FindAllStringIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:match[0]]))
+		pattern.WriteString("(.*?)")
+		last = match[1]
+	}
+	if last == 0 {
+		return false
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]) + "$")
+	matched, err := regexp.MatchString(pattern.String(), s)
+	return err == nil && matched
+}
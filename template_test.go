@@ -0,0 +1,60 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithKeployTemplates(t *testing.T) {
+	expected := `{"id":"{{int .id}}","token":"{{string .token}}","auth":"Bearer {{.token}}","price":"{{float .price}}","name":"Cat"}`
+	actual := `{"id":42,"token":"abc","auth":"Bearer abc","price":9.5,"name":"Dog"}`
+
+	plain, err := CompareJSON([]byte(expected), []byte(actual), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := plain.Stats(); stats.Total != 5 {
+		t.Errorf("without the option the templates should differ, got %+v", plain.Entries)
+	}
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithKeployTemplates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "name", Op: OpChanged, Expected: "Cat", Actual: "Dog"}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+}
+
+func TestTemplateMatches(t *testing.T) {
+	tests := []struct {
+		template string
+		actual   interface{}
+		want     bool
+	}{
+		{template: "{{int .id}}", actual: 42.0, want: true},
+		{template: "{{int .id}}", actual: 4.2, want: false},
+		{template: "{{int .id}}", actual: "42", want: false},
+		{template: "{{ float .price }}", actual: 4.2, want: true},
+		{template: "{{string .token}}", actual: "abc", want: true},
+		{template: "{{string .token}}", actual: 1.0, want: false},
+		{template: "{{bool .ok}}", actual: true, want: true},
+		{template: "{{.any}}", actual: 1.0, want: true},
+		{template: "{{.any}}", actual: map[string]interface{}{}, want: false},
+		{template: "Bearer {{.token}}", actual: "Bearer abc", want: true},
+		{template: "Bearer {{.token}}", actual: "Basic abc", want: false},
+		{template: "/users/{{int .id}}/posts/{{.post}}", actual: "/users/1/posts/x", want: true},
+		{template: "{{not a template}}", actual: "x", want: false},
+		{template: "{{uuid}}", actual: "123e4567-e89b-12d3-a456-426614174000", want: true},
+		{template: "{{ uuid }}", actual: "123e4567", want: false},
+		{template: "{{timestamp}}", actual: "2024-01-02T03:04:05.123+02:00", want: true},
+		{template: "{{timestamp}}", actual: "yesterday", want: false},
+		{template: "{{timestamp}}", actual: 1.7e9, want: false},
+	}
+	for _, tt := range tests {
+		if got := templateMatches(tt.template, tt.actual); got != tt.want {
+			t.Errorf("templateMatches(%q, %v) = %v, want %v", tt.template, tt.actual, got, tt.want)
+		}
+	}
+}
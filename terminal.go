@@ -0,0 +1,30 @@
+package colorisediff
+
+import "os"
+
+// EnableANSI prepares the terminal behind the file for ANSI color sequences and reports whether they will be
+// interpreted. On Windows it turns on virtual terminal processing of the console, which older consoles do not
+// support; elsewhere it checks that the file is a terminal other than TERM=dumb. Callers can pass the negated result
+// as the disableColor argument of the comparisons.
+func EnableANSI(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	return enableANSI(f)
+}
+
+// RenderForTerminal renders the diff for the terminal behind the file: the colorized side-by-side table when the
+// terminal interprets ANSI sequences, and the plain "-"/"+" marker lines otherwise, so no raw escape sequences show
+// up on consoles without color support or when the output is redirected. The table is fitted into the width of the
+// terminal.
+func (d Diff) RenderForTerminal(f *os.File) (rendered string, err error) {
+	defer recoverPanic(&err)
+	if EnableANSI(f) {
+		width, ok := terminalWidth(f)
+		if !ok {
+			width = envTerminalWidth()
+		}
+		return d.renderTable(width), nil
+	}
+	return d.Render(FormatPlain)
+}
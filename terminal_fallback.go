@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package colorisediff
+
+import "os"
+
+// terminalWidth reports that the width of the terminal is unknown on platforms without a way to query it.
+func terminalWidth(*os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package colorisediff
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// enableANSI reports whether the file is a terminal interpreting ANSI sequences.
+func enableANSI(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) && os.Getenv("TERM") != "dumb"
+}
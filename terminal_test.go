@@ -0,0 +1,31 @@
+package colorisediff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderForTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if EnableANSI(f) || EnableANSI(nil) {
+		t.Error("a regular file should not be reported to interpret ANSI sequences")
+	}
+
+	diff, err := CompareJSON([]byte(`{"name":"Cat"}`), []byte(`{"name":"Dog"}`), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := diff.RenderForTerminal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "\x1b[") || out != "- name: \"Cat\"\n+ name: \"Dog\"\n" {
+		t.Errorf("expected the plain fallback, got %q", out)
+	}
+}
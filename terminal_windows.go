@@ -0,0 +1,36 @@
+//go:build windows
+
+package colorisediff
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"golang.org/x/sys/windows"
+)
+
+// enableANSI turns on virtual terminal processing of the console behind the file, if it is not on yet, and reports
+// whether the console interprets ANSI sequences. Cygwin and MSYS2 terminals interpret them natively.
+func enableANSI(f *os.File) bool {
+	if isatty.IsCygwinTerminal(f.Fd()) {
+		return true
+	}
+	handle := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
+
+// terminalWidth returns the number of columns of the visible window of the console behind the file.
+func terminalWidth(f *os.File) (int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(f.Fd()), &info); err != nil {
+		return 0, false
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, true
+}
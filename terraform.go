@@ -0,0 +1,167 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// terraformInternalFields lists the members of a plan describing the plan itself rather than the infrastructure.
+var terraformInternalFields = []string{"format_version", "terraform_version", "timestamp", "applyable", "complete", "errored", "relevant_attributes", "checks"}
+
+// terraformAddressedLists lists the members of a plan holding lists of resources or modules with an "address".
+var terraformAddressedLists = map[string]bool{"resources": true, "child_modules": true, "resource_changes": true, "resource_drift": true}
+
+// ResourceChange holds the differences affecting a single resource of a Terraform plan.
+// Address: The resource address, e.g. "module.vpc.aws_subnet.this[0]".
+// Actions: The actions the actual plan takes on the resource, e.g. ["update"], if it lists the resource.
+// Entries: The differences found for the resource.
+type ResourceChange struct {
+	Address string
+	Actions []string
+	Entries []DiffEntry
+}
+
+// PlanDiff holds the differences between two Terraform plans.
+// Diff: The differences of the whole plans.
+// Resources: The differences grouped by resource address, sorted by address.
+type PlanDiff struct {
+	Diff
+	Resources []ResourceChange
+}
+
+// CompareTerraformPlan compares two plans in the JSON format of `terraform show -json`. Resources and modules are
+// matched by address instead of position, the fields describing the plan itself (versions, timestamp, relevant
+// attributes, ...) are ignored and the differences are additionally grouped by resource address.
+func CompareTerraformPlan(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (PlanDiff, error) {
+	var expectedPlan, actualPlan interface{}
+	if err := json.Unmarshal(expected, &expectedPlan); err != nil {
+		return PlanDiff{}, fmt.Errorf("decoding expected plan: %w", err)
+	}
+	if err := json.Unmarshal(actual, &actualPlan); err != nil {
+		return PlanDiff{}, fmt.Errorf("decoding actual plan: %w", err)
+	}
+	actualPlan = normalizePlan(actualPlan)
+	diff, err := compareDecoded(normalizePlan(expectedPlan), actualPlan, noise, disableColor, opts)
+	if err != nil {
+		return PlanDiff{}, err
+	}
+	return PlanDiff{Diff: diff, Resources: groupByResource(diff.Entries, actualPlan)}, nil
+}
+
+// Summary renders one block per changed resource: its address and planned actions followed by the changed paths.
+func (d PlanDiff) Summary() string {
+	var builder strings.Builder
+	for _, resource := range d.Resources {
+		builder.WriteString(resource.Address)
+		if len(resource.Actions) > 0 {
+			builder.WriteString(" (" + strings.Join(resource.Actions, ", ") + ")")
+		}
+		builder.WriteString("\n")
+		for _, entry := range resource.Entries {
+			builder.WriteString(fmt.Sprintf("  %s %s\n", entry.Op, entry.Path))
+		}
+	}
+	return builder.String()
+}
+
+// normalizePlan drops the plan-internal fields, including the versions of the embedded prior state, and keys
+// every list of addressed resources or modules by address.
+func normalizePlan(plan interface{}) interface{} {
+	if root, ok := plan.(map[string]interface{}); ok {
+		for _, field := range terraformInternalFields {
+			delete(root, field)
+		}
+		if priorState, ok := root["prior_state"].(map[string]interface{}); ok {
+			delete(priorState, "format_version")
+			delete(priorState, "terraform_version")
+		}
+	}
+	return keyByAddress(plan)
+}
+
+// keyByAddress replaces the lists of addressed resources or modules found in a value with objects keyed by address.
+func keyByAddress(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			child = keyByAddress(child)
+			if list, ok := child.([]interface{}); ok && terraformAddressedLists[key] && allAddressed(list) {
+				child = keyElements(list, terraformAddress)
+			}
+			v[key] = child
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = keyByAddress(child)
+		}
+	}
+	return value
+}
+
+// allAddressed reports whether every element of a list is an object with a string "address".
+func allAddressed(list []interface{}) bool {
+	for _, element := range list {
+		fields, ok := element.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := fields["address"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// terraformAddress returns the "address" of a resource or module.
+func terraformAddress(element interface{}) string {
+	fields, _ := element.(map[string]interface{})
+	address, _ := fields["address"].(string)
+	return address
+}
+
+// groupByResource groups the entries by the resource address following the first addressed list in their path.
+// The planned actions are taken from the actual plan's resource changes.
+func groupByResource(entries []DiffEntry, actualPlan interface{}) []ResourceChange {
+	resources := map[string]*ResourceChange{}
+	for _, entry := range entries {
+		if entry.Noised {
+			continue
+		}
+		components := splitPath(entry.Path)
+		for i := 0; i < len(components)-1; i++ {
+			if !terraformAddressedLists[components[i]] || components[i] == "child_modules" {
+				continue
+			}
+			address := components[i+1]
+			if resources[address] == nil {
+				resources[address] = &ResourceChange{Address: address, Actions: plannedActions(actualPlan, address)}
+			}
+			resources[address].Entries = append(resources[address].Entries, entry)
+			break
+		}
+	}
+
+	result := make([]ResourceChange, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, *resource)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+	return result
+}
+
+// plannedActions returns the actions a normalized plan lists for the resource address.
+func plannedActions(plan interface{}, address string) []string {
+	root, _ := plan.(map[string]interface{})
+	changes, _ := root["resource_changes"].(map[string]interface{})
+	resource, _ := changes[address].(map[string]interface{})
+	change, _ := resource["change"].(map[string]interface{})
+	var actions []string
+	for _, action := range schemaList(change["actions"]) {
+		if s, ok := action.(string); ok {
+			actions = append(actions, s)
+		}
+	}
+	return actions
+}
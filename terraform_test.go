@@ -0,0 +1,61 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareTerraformPlan(t *testing.T) {
+	expected := `{
+		"format_version": "1.2",
+		"terraform_version": "1.6.0",
+		"timestamp": "2024-01-01T00:00:00Z",
+		"planned_values": {"root_module": {
+			"resources": [
+				{"address": "aws_s3_bucket.logs", "values": {"bucket": "logs"}},
+				{"address": "aws_instance.web", "values": {"instance_type": "t3.micro"}}
+			]
+		}},
+		"resource_changes": [
+			{"address": "aws_instance.web", "change": {"actions": ["create"], "after": {"instance_type": "t3.micro"}}},
+			{"address": "aws_s3_bucket.logs", "change": {"actions": ["no-op"], "after": {"bucket": "logs"}}}
+		]
+	}`
+	actual := `{
+		"format_version": "1.2",
+		"terraform_version": "1.7.1",
+		"timestamp": "2024-02-01T00:00:00Z",
+		"planned_values": {"root_module": {
+			"resources": [
+				{"address": "aws_instance.web", "values": {"instance_type": "t3.large"}},
+				{"address": "aws_s3_bucket.logs", "values": {"bucket": "logs"}}
+			]
+		}},
+		"resource_changes": [
+			{"address": "aws_s3_bucket.logs", "change": {"actions": ["no-op"], "after": {"bucket": "logs"}}},
+			{"address": "aws_instance.web", "change": {"actions": ["update"], "after": {"instance_type": "t3.large"}}}
+		]
+	}`
+
+	resp, err := CompareTerraformPlan([]byte(expected), []byte(actual), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Resources) != 1 {
+		t.Fatalf("expected one changed resource, got %+v", resp.Resources)
+	}
+	resource := resp.Resources[0]
+	if resource.Address != "aws_instance.web" || !reflect.DeepEqual(resource.Actions, []string{"update"}) || len(resource.Entries) != 3 {
+		t.Errorf("unexpected resource %+v", resource)
+	}
+	if len(resp.Entries) != 3 {
+		t.Errorf("expected only resource differences, got %+v", resp.Entries)
+	}
+	want := "aws_instance.web (update)\n" +
+		"  changed planned_values.root_module.resources.aws_instance\\.web.values.instance_type\n" +
+		"  changed resource_changes.aws_instance\\.web.change.actions.0\n" +
+		"  changed resource_changes.aws_instance\\.web.change.after.instance_type\n"
+	if got := resp.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
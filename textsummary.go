@@ -0,0 +1,84 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxEditCells is the size of the largest table of word pairs the edit distance is computed with, beyond which the
+// summary of a text change is left out.
+const maxEditCells = 4 << 20
+
+// WithTextChangeSummary adds a summary line below changed strings of at least minLength characters on either side,
+// e.g. "(1 word changed of 214; similarity 99.5%)", telling reviewers whether a long text change is cosmetic or
+// substantive. The number of changed words is the word-level edit distance between the values, and the similarity is
+// the share of words left unchanged.
+func WithTextChangeSummary(minLength int) Option {
+	return func(o *options) {
+		o.valueRenderers.textSummaryLength = minLength
+	}
+}
+
+// textSummary returns the summary line of a changed value at a path in the colorizer's ".key[0]" notation, or "" if
+// the values are not long strings, a renderer claims them or no summary is asked for.
+func (r valueRenderers) textSummary(legacyPath string, expected, actual interface{}) string {
+	e, ok := expected.(string)
+	if !ok || r.textSummaryLength <= 0 || r.claims(legacyPath, expected, actual) {
+		return ""
+	}
+	a, ok := actual.(string)
+	if !ok || max(utf8.RuneCountInString(e), utf8.RuneCountInString(a)) < r.textSummaryLength {
+		return ""
+	}
+	expectedWords, actualWords := strings.Fields(e), strings.Fields(a)
+	distance, ok := wordEditDistance(expectedWords, actualWords)
+	if !ok {
+		return ""
+	}
+	total := max(len(expectedWords), len(actualWords))
+	similarity := 100.0
+	if total > 0 {
+		similarity = float64(total-distance) / float64(total) * 100
+	}
+	unit := "words"
+	if distance == 1 {
+		unit = "word"
+	}
+	return fmt.Sprintf("(%d %s changed of %d; similarity %.1f%%)", distance, unit, total, similarity)
+}
+
+// wordEditDistance returns the number of words inserted, deleted or replaced to turn one text into the other. It
+// reports false if the words differing after the common prefix and suffix are too many to compare.
+func wordEditDistance(a, b []string) (int, bool) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return max(len(a), len(b)), true
+	}
+	if len(a)*len(b) > maxEditCells {
+		return 0, false
+	}
+
+	// Keep two rows of the Levenshtein table.
+	previous, current := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)], true
+}
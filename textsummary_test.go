@@ -0,0 +1,45 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordEditDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{name: "equal", a: "a b c", b: "a b c", want: 0},
+		{name: "replaced", a: "a b c", b: "a x c", want: 1},
+		{name: "inserted", a: "a b c", b: "a b x c", want: 1},
+		{name: "deleted", a: "a b c d", b: "a d", want: 2},
+		{name: "empty", a: "", b: "a b", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := wordEditDistance(strings.Fields(tt.a), strings.Fields(tt.b))
+			if !ok || got != tt.want {
+				t.Errorf("wordEditDistance(%q, %q) = %d, %v, want %d", tt.a, tt.b, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTextChangeSummary(t *testing.T) {
+	paragraph := strings.Repeat("lorem ipsum dolor sit amet ", 40)
+	expected := []byte(`{"text":"` + paragraph + `quick fox ` + paragraph + `","short":"a"}`)
+	actual := []byte(`{"text":"` + paragraph + `quick dog ` + paragraph + `","short":"b"}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithTextChangeSummary(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(1 word changed of 402; similarity 99.8%)"
+	for _, side := range []string{resp.Expected, resp.Actual} {
+		if strings.Count(side, want) != 1 {
+			t.Errorf("expected the summary %q once in:\n%s", want, side)
+		}
+	}
+}
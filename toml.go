@@ -0,0 +1,21 @@
+package colorisediff
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CompareTOML compares two TOML documents and returns the colorized differences.
+// Tables become objects, arrays of tables become arrays of objects and date-times become RFC 3339 strings; the
+// decoded documents are then compared like CompareJSON would.
+func CompareTOML(expected, actual []byte, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	var expectedValue, actualValue map[string]interface{}
+	if err := toml.Unmarshal(expected, &expectedValue); err != nil {
+		return Diff{}, fmt.Errorf("decoding expected TOML: %w", err)
+	}
+	if err := toml.Unmarshal(actual, &actualValue); err != nil {
+		return Diff{}, fmt.Errorf("decoding actual TOML: %w", err)
+	}
+	return compareDecoded(expectedValue, actualValue, noise, disableColor, opts)
+}
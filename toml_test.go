@@ -0,0 +1,47 @@
+package colorisediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareTOML(t *testing.T) {
+	expected := `
+title = "service"
+
+[server]
+port = 8080
+started = 2024-01-02T03:04:05Z
+
+[[routes]]
+path = "/users"
+`
+	actual := `
+title = "service"
+
+[server]
+port = 9090
+started = 2024-01-02T03:04:05Z
+
+[[routes]]
+path = "/users"
+
+[[routes]]
+path = "/orders"
+`
+	resp, err := CompareTOML([]byte(expected), []byte(actual), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "routes.1", Op: OpAdded, Actual: map[string]interface{}{"path": "/orders"}},
+		{Path: "server.port", Op: OpChanged, Expected: float64(8080), Actual: float64(9090)},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	if _, err := CompareTOML([]byte("title = "), []byte(actual), nil, true); err == nil {
+		t.Error("expected an error for invalid TOML")
+	}
+}
@@ -0,0 +1,68 @@
+package colorisediff
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HTTPTransfer describes how an HTTP message body was framed on the wire, as opposed to its content.
+// TransferEncoding: The transfer codings applied to the body, e.g. ["chunked"].
+// ContentLength: The declared length of the body, -1 if unknown.
+// ChunkExtensions: The extensions of the chunks in order, e.g. "checksum=abc", empty strings for chunks without.
+// Trailers: The trailer fields sent after the body.
+type HTTPTransfer struct {
+	TransferEncoding []string
+	ContentLength    int64
+	ChunkExtensions  []string
+	Trailers         map[string]string
+}
+
+// HTTPTransferFromResponse returns the framing of a response body as seen by net/http, which does not expose chunk
+// extensions. The trailers are only complete once the body has been read to the end.
+func HTTPTransferFromResponse(resp *http.Response) HTTPTransfer {
+	transfer := HTTPTransfer{TransferEncoding: resp.TransferEncoding, ContentLength: resp.ContentLength}
+	if len(resp.Trailer) > 0 {
+		transfer.Trailers = make(map[string]string, len(resp.Trailer))
+		for name, values := range resp.Trailer {
+			transfer.Trailers[name] = strings.Join(values, ", ")
+		}
+	}
+	return transfer
+}
+
+// CompareHTTPTransfer compares the framing of two HTTP message bodies and returns the colorized differences, so
+// replay mismatches caused by streaming semantics, such as a chunked response replayed with a fixed length or a
+// missing trailer, are reported separately from differences in the body. The differences are reported at the paths
+// "transferEncoding", "contentLength", "chunkExtensions.<i>" and "trailers.<name>", with trailer names compared
+// case-insensitively; noise uses the same paths, e.g. {"trailers.grpc-message": {}}.
+func CompareHTTPTransfer(expected, actual HTTPTransfer, noise map[string][]string, disableColor bool, opts ...Option) (Diff, error) {
+	return compareDecoded(transferToValue(expected), transferToValue(actual), noise, disableColor, opts)
+}
+
+// transferToValue converts the framing of a body into an object, leaving out the parts that are unknown.
+func transferToValue(transfer HTTPTransfer) map[string]interface{} {
+	value := map[string]interface{}{}
+	if len(transfer.TransferEncoding) > 0 {
+		codings := make([]interface{}, len(transfer.TransferEncoding))
+		for i, coding := range transfer.TransferEncoding {
+			codings[i] = strings.ToLower(coding)
+		}
+		value["transferEncoding"] = codings
+	}
+	if transfer.ContentLength >= 0 {
+		value["contentLength"] = transfer.ContentLength
+	}
+	if len(transfer.ChunkExtensions) > 0 {
+		extensions := make([]interface{}, len(transfer.ChunkExtensions))
+		for i, extension := range transfer.ChunkExtensions {
+			extensions[i] = extension
+		}
+		value["chunkExtensions"] = extensions
+	}
+	trailers := map[string]interface{}{}
+	for name, fieldValue := range transfer.Trailers {
+		trailers[strings.ToLower(name)] = fieldValue
+	}
+	value["trailers"] = trailers
+	return value
+}
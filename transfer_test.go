@@ -0,0 +1,58 @@
+package colorisediff
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCompareHTTPTransfer(t *testing.T) {
+	expected := HTTPTransfer{
+		TransferEncoding: []string{"chunked"},
+		ContentLength:    -1,
+		ChunkExtensions:  []string{"", "checksum=abc"},
+		Trailers:         map[string]string{"Grpc-Status": "0", "Grpc-Message": "ok"},
+	}
+	actual := HTTPTransfer{
+		ContentLength: 42,
+		Trailers:      map[string]string{"grpc-status": "0", "Grpc-Message": "done"},
+	}
+
+	resp, err := CompareHTTPTransfer(expected, actual, map[string][]string{"trailers.grpc-message": {}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "chunkExtensions", Op: OpRemoved, Expected: []interface{}{"", "checksum=abc"}},
+		{Path: "contentLength", Op: OpAdded, Actual: 42.0},
+		{Path: "trailers.grpc-message", Op: OpChanged, Expected: "ok", Actual: "done", Noised: true},
+		{Path: "transferEncoding", Op: OpRemoved, Expected: []interface{}{"chunked"}},
+	}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+
+	same, err := CompareHTTPTransfer(expected, expected, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(same.Entries) != 0 {
+		t.Errorf("expected no differences, got %+v", same.Entries)
+	}
+}
+
+func TestHTTPTransferFromResponse(t *testing.T) {
+	resp := &http.Response{
+		TransferEncoding: []string{"chunked"},
+		ContentLength:    -1,
+		Trailer:          http.Header{"Grpc-Status": {"0"}, "X-Multi": {"a", "b"}},
+	}
+	want := HTTPTransfer{
+		TransferEncoding: []string{"chunked"},
+		ContentLength:    -1,
+		Trailers:         map[string]string{"Grpc-Status": "0", "X-Multi": "a, b"},
+	}
+	if got := HTTPTransferFromResponse(resp); !reflect.DeepEqual(got, want) {
+		t.Errorf("HTTPTransferFromResponse() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,323 @@
+package colorisediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pairTransform rewrites a pair of expected and actual values found at the given gjson-style path before they are
+// compared. It returns the replacement values and true if it applied, or false to leave the values untouched.
+// A non-empty note annotates the path in the rendered output and the resulting entries.
+type pairTransform func(path string, expected, actual interface{}) (e, a interface{}, note string, ok bool)
+
+// maxTransformRounds bounds how often transforms are re-applied to the values they produced at a single node.
+const maxTransformRounds = 8
+
+// transforms returns the pairwise transforms enabled by the options, in the order they are tried. Transforms that
+// reorder arrays record their index mappings on the transformer.
+func (o *options) transforms(t *transformer) []pairTransform {
+	var transforms []pairTransform
+	if o.graphQL {
+		transforms = append(transforms, keyGraphQLErrors)
+	}
+	if o.elasticsearch {
+		transforms = append(transforms, keyElasticsearchHits)
+	}
+	if o.keployTemplates {
+		transforms = append(transforms, matchKeployTemplate)
+	}
+	if len(o.unorderedArrays) > 0 {
+		transforms = append(transforms, reorderUnorderedArrays(o.unorderedArrays, t.addMapping))
+	}
+	if len(o.stringNormalizers) > 0 {
+		transforms = append(transforms, normalizeStringPair(o.stringNormalizers))
+	}
+	if o.jwtDecoding {
+		transforms = append(transforms, decodeJWTPair(o.jwtSignature))
+	}
+	if o.nestedJSONStrings {
+		transforms = append(transforms, reportInvalidEmbeddedJSON(t.addWarning), decodeNestedJSONStrings)
+	}
+	if o.urlNormalization {
+		transforms = append(transforms, decodeURLPair)
+	}
+	if o.base64Decoding {
+		transforms = append(transforms, decodeBase64Pair)
+	}
+	if o.localeNumbers {
+		transforms = append(transforms, compareLocaleNumbers)
+	}
+	if len(o.numericTolerances) > 0 {
+		transforms = append(transforms, tolerateNumbers(o.numericTolerances))
+	}
+	return transforms
+}
+
+// documentNormalizer rewrites a single value found at the given gjson-style path of one document. It is applied
+// bottom-up to every value of both documents before the pairwise transforms run. volatile marks the value as one
+// that is expected to differ between runs, e.g. a generated identifier; values that are volatile on both sides
+// are treated as equal.
+type documentNormalizer func(path string, value interface{}) (normalized interface{}, volatile bool)
+
+// normalizers returns the document normalizers enabled by the options, in the order they are applied.
+func (o *options) normalizers() []documentNormalizer {
+	var normalizers []documentNormalizer
+	if o.mongoExtendedJSON {
+		normalizers = append(normalizers, unwrapExtendedJSON(o.mongoIgnoreVolatile))
+	}
+	if o.dynamoDB {
+		normalizers = append(normalizers, unwrapDynamoDB)
+	}
+	if o.openTelemetry {
+		normalizers = append(normalizers, normalizeOTLP)
+	}
+	if o.shapeOnly {
+		normalizers = append(normalizers, shapeOf)
+	}
+	return normalizers
+}
+
+// prepare normalizes both documents and applies the pairwise transforms enabled by the options. It returns the
+// prepared documents, the transformer holding the notes produced on the way and whether anything was applied.
+func (o *options) prepare(expected, actual interface{}) (interface{}, interface{}, *transformer, bool) {
+	tr := &transformer{}
+	tr.transforms = o.transforms(tr)
+	normalizers := o.normalizers()
+	if len(normalizers) > 0 {
+		expectedVolatile, actualVolatile := map[string]bool{}, map[string]bool{}
+		expected = normalizeDocument("", expected, normalizers, expectedVolatile)
+		actual = normalizeDocument("", actual, normalizers, actualVolatile)
+		if len(expectedVolatile) > 0 && len(actualVolatile) > 0 {
+			tr.transforms = append([]pairTransform{equalizeVolatile(expectedVolatile, actualVolatile)}, tr.transforms...)
+		}
+	}
+	if len(tr.transforms) == 0 {
+		return expected, actual, tr, len(normalizers) > 0
+	}
+	expected, actual = tr.transformPair("", expected, actual)
+	return expected, actual, tr, true
+}
+
+// normalizeDocument applies the normalizers bottom-up to every value of a document and records the paths of the
+// values they marked as volatile.
+func normalizeDocument(path string, value interface{}, normalizers []documentNormalizer, volatile map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = normalizeDocument(joinPath(path, escapePathKey(key)), child, normalizers, volatile)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = normalizeDocument(joinPath(path, strconv.Itoa(i)), child, normalizers, volatile)
+		}
+	}
+	for _, normalize := range normalizers {
+		normalized, isVolatile := normalize(path, value)
+		value = normalized
+		if isVolatile {
+			volatile[path] = true
+		}
+	}
+	return value
+}
+
+// equalizeVolatile returns a transform replacing the actual value with the expected one at paths that were marked
+// volatile in both documents.
+func equalizeVolatile(expectedVolatile, actualVolatile map[string]bool) pairTransform {
+	return func(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		if !expectedVolatile[path] || !actualVolatile[path] || reflect.DeepEqual(expected, actual) {
+			return nil, nil, "", false
+		}
+		return expected, expected, "", true
+	}
+}
+
+// transformer applies pairwise transforms to two documents and remembers the notes they produced.
+type transformer struct {
+	transforms []pairTransform
+	notes      map[string]string // notes maps paths to the annotations produced by the transforms.
+
+	extra      map[string]bool   // extra holds the paths of the extra actual members left out by trimExtra.
+	extraNotes map[string]string // extraNotes maps the arrays and objects trimmed by trimExtra to their notes.
+
+	mappings []ArrayMapping // mappings holds the index mappings of the arrays reordered by the transforms.
+	warnings []Warning      // warnings holds the issues the transforms ran into.
+}
+
+// transformPair walks the expected and actual values in tandem and applies the matching transforms at every node,
+// top-down, before descending into the (possibly replaced) children. A transform's output is offered to the
+// transforms again, so e.g. a decoded payload can itself be decoded further.
+func (t *transformer) transformPair(path string, expected, actual interface{}) (interface{}, interface{}) {
+	for round := 0; round < maxTransformRounds; round++ {
+		applied := false
+		for _, transform := range t.transforms {
+			if e, a, note, ok := transform(path, expected, actual); ok {
+				expected, actual, applied = e, a, true
+				if note != "" {
+					t.addNote(path, note)
+				}
+				break
+			}
+		}
+		if !applied {
+			break
+		}
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		if a, ok := actual.(map[string]interface{}); ok {
+			for key, expectedValue := range e {
+				if actualValue, exists := a[key]; exists {
+					e[key], a[key] = t.transformPair(joinPath(path, escapePathKey(key)), expectedValue, actualValue)
+				}
+			}
+		}
+	case []interface{}:
+		if a, ok := actual.([]interface{}); ok {
+			for i := 0; i < len(e) && i < len(a); i++ {
+				e[i], a[i] = t.transformPair(joinPath(path, strconv.Itoa(i)), e[i], a[i])
+			}
+		}
+	}
+	return expected, actual
+}
+
+// addNote records an annotation for a path, appending to any note already recorded there.
+func (t *transformer) addNote(path, note string) {
+	if t.notes == nil {
+		t.notes = map[string]string{}
+	}
+	if existing, ok := t.notes[path]; ok && existing != note {
+		note = existing + "; " + note
+	}
+	t.notes[path] = note
+}
+
+// addMapping records the index mapping of a reordered array.
+func (t *transformer) addMapping(mapping ArrayMapping) {
+	t.mappings = append(t.mappings, mapping)
+}
+
+// addWarning records an issue a transform ran into.
+func (t *transformer) addWarning(warning Warning) {
+	t.warnings = append(t.warnings, warning)
+}
+
+// noteFor returns the annotations recorded for the path or any of its ancestors.
+func (t *transformer) noteFor(path string) string {
+	var notes []string
+	for _, notedPath := range sortedKeys(t.notes) {
+		if notedPath == "" || notedPath == path || strings.HasPrefix(path, notedPath+".") {
+			notes = append(notes, t.notes[notedPath])
+		}
+	}
+	return strings.Join(notes, "; ")
+}
+
+// annotate copies the recorded annotation onto an entry at or below an annotated path and marks the extra members
+// left out by trimExtra as noised, with the note of the array or object they were left out of.
+func (t *transformer) annotate(entry DiffEntry) DiffEntry {
+	if note := t.noteFor(entry.Path); note != "" {
+		entry.Note = note
+	}
+	if entry.Op == OpAdded && t.extra[entry.Path] {
+		entry.Noised = true
+		if note := t.extraNotes[parentPath(entry.Path)]; note != "" && entry.Note != "" {
+			entry.Note += "; " + note
+		} else if note != "" {
+			entry.Note = note
+		}
+	}
+	return entry
+}
+
+// renderNotes renders the recorded annotations as lines to be appended below a rendered side.
+func (t *transformer) renderNotes() string {
+	var builder strings.Builder
+	for _, path := range sortedKeys(t.notes) {
+		label := path
+		if label == "" {
+			label = "(root)"
+		}
+		builder.WriteString(breakLines(fmt.Sprintf("note: %s: %s", label, t.notes[path])) + "\n")
+	}
+	return builder.String()
+}
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeNestedJSONStrings replaces two differing strings that both hold a JSON object or array with the decoded
+// documents.
+func decodeNestedJSONStrings(_ string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+	e, a, ok := differingStrings(expected, actual)
+	if !ok {
+		return nil, nil, "", false
+	}
+	expectedDoc, ok := decodeEmbeddedJSON(e)
+	if !ok {
+		return nil, nil, "", false
+	}
+	actualDoc, ok := decodeEmbeddedJSON(a)
+	if !ok {
+		return nil, nil, "", false
+	}
+	return expectedDoc, actualDoc, "", true
+}
+
+// keyElements converts a list into an object keyed by the given function, so its elements are matched by key
+// instead of position. Elements sharing a key are told apart by a "#n" suffix in their order of appearance.
+func keyElements(elements []interface{}, key func(interface{}) string) map[string]interface{} {
+	keyed := make(map[string]interface{}, len(elements))
+	for _, element := range elements {
+		base := key(element)
+		unique := base
+		for n := 2; ; n++ {
+			if _, exists := keyed[unique]; !exists {
+				break
+			}
+			unique = fmt.Sprintf("%s#%d", base, n)
+		}
+		keyed[unique] = element
+	}
+	return keyed
+}
+
+// differingStrings reports whether both values are strings with different content and returns them.
+func differingStrings(expected, actual interface{}) (string, string, bool) {
+	e, ok := expected.(string)
+	if !ok {
+		return "", "", false
+	}
+	a, ok := actual.(string)
+	if !ok || e == a {
+		return "", "", false
+	}
+	return e, a, true
+}
+
+// decodeEmbeddedJSON decodes a string holding a JSON object or array.
+func decodeEmbeddedJSON(s string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+		return nil, false
+	}
+	kind := reflect.TypeOf(doc).Kind()
+	return doc, kind == reflect.Map || kind == reflect.Slice
+}
@@ -0,0 +1,55 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNestedJSONStrings(t *testing.T) {
+	json1 := `{"payload":"{\"user\":{\"id\":1,\"name\":\"Cat\"}}","id":1}`
+	json2 := `{"payload":"{\"user\":{\"id\":1,\"name\":\"Dog\"}}","id":1}`
+
+	plain, err := CompareJSON([]byte(json1), []byte(json2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.At("payload") == nil {
+		t.Errorf("without the option the payload should differ as a whole, got %+v", plain.Entries)
+	}
+
+	nested, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithNestedJSONStrings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DiffEntry{{Path: "payload.user.name", Op: OpChanged, Expected: "Cat", Actual: "Dog"}}
+	if !reflect.DeepEqual(nested.Entries, expected) {
+		t.Errorf("unexpected entries %+v", nested.Entries)
+	}
+	if strings.Contains(nested.Expected, `\"`) {
+		t.Errorf("expected the embedded document to be rendered structurally, got:\n%s", nested.Expected)
+	}
+}
+
+func TestDecodeNestedJSONStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		applies  bool
+	}{
+		{name: "both objects", expected: `{"a":1}`, actual: `{"a":2}`, applies: true},
+		{name: "both arrays", expected: `[1]`, actual: ` [2]`, applies: true},
+		{name: "equal strings", expected: `{"a":1}`, actual: `{"a":1}`, applies: false},
+		{name: "only one side is JSON", expected: `{"a":1}`, actual: `plain text`, applies: false},
+		{name: "scalar JSON", expected: `1`, actual: `2`, applies: false},
+		{name: "not strings", expected: float64(1), actual: `{"a":1}`, applies: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, ok := decodeNestedJSONStrings("", tt.expected, tt.actual); ok != tt.applies {
+				t.Errorf("expected applies=%v, got %v", tt.applies, ok)
+			}
+		})
+	}
+}
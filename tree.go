@@ -0,0 +1,89 @@
+package colorisediff
+
+import (
+	"reflect"
+
+	"github.com/tidwall/gjson"
+)
+
+// diffNode is a node of the tree of differences between two JSON documents. Objects present on both sides are
+// inner nodes holding their differing members in document order; every other differing value is a leaf.
+type diffNode struct {
+	path     string      // path is the gjson-style path of the value, "" for the root.
+	op       Op          // op is the kind of change, OpChanged for inner nodes.
+	expected interface{} // expected is the decoded expected value of a leaf, nil for added values.
+	actual   interface{} // actual is the decoded actual value of a leaf, nil for removed values.
+	children []*diffNode // children are the differing members of an object present on both sides.
+	replaced bool        // replaced marks a leaf standing for a subtree replaced by an unrelated value.
+}
+
+// buildDiffTree returns the tree of differences between two parsed JSON documents, or nil if they are equal.
+func buildDiffTree(expected, actual gjson.Result) *diffNode {
+	return diffResults("", expected, actual)
+}
+
+// diffResults returns the node for the values at a path, recursing into objects present on both sides, or nil
+// if the values are equal.
+func diffResults(path string, expected, actual gjson.Result) *diffNode {
+	node := &diffNode{path: path, op: OpChanged}
+	if !expected.IsObject() || !actual.IsObject() {
+		node.expected, node.actual = expected.Value(), actual.Value()
+		if reflect.DeepEqual(node.expected, node.actual) {
+			return nil
+		}
+		return node
+	}
+
+	expectedKeys, expectedMembers := objectMembers(expected)
+	actualKeys, actualMembers := objectMembers(actual)
+	for _, key := range expectedKeys {
+		childPath := joinPath(path, escapePathKey(key))
+		actualValue, ok := actualMembers[key]
+		if !ok {
+			node.children = append(node.children, &diffNode{path: childPath, op: OpRemoved, expected: expectedMembers[key].Value()})
+		} else if child := diffResults(childPath, expectedMembers[key], actualValue); child != nil {
+			node.children = append(node.children, child)
+		}
+	}
+	for _, key := range actualKeys {
+		if _, ok := expectedMembers[key]; !ok {
+			childPath := joinPath(path, escapePathKey(key))
+			node.children = append(node.children, &diffNode{path: childPath, op: OpAdded, actual: actualMembers[key].Value()})
+		}
+	}
+	if len(node.children) == 0 {
+		return nil
+	}
+	return node
+}
+
+// objectMembers returns the keys of a parsed object in the order of their first appearance, without duplicates,
+// and its members by key. The last of duplicate keys wins, as it does when encoding/json decodes the object for
+// the entries and the context.
+func objectMembers(object gjson.Result) ([]string, map[string]gjson.Result) {
+	var keys []string
+	members := map[string]gjson.Result{}
+	object.ForEach(func(key, value gjson.Result) bool {
+		if _, ok := members[key.String()]; !ok {
+			keys = append(keys, key.String())
+		}
+		members[key.String()] = value
+		return true
+	})
+	return keys, members
+}
+
+// leaves returns the leaves below a node in depth-first order.
+func (n *diffNode) leaves() []*diffNode {
+	if n == nil {
+		return nil
+	}
+	if len(n.children) == 0 {
+		return []*diffNode{n}
+	}
+	var leaves []*diffNode
+	for _, child := range n.children {
+		leaves = append(leaves, child.leaves()...)
+	}
+	return leaves
+}
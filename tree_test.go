@@ -0,0 +1,98 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestBuildDiffTree(t *testing.T) {
+	type leaf struct {
+		Path     string
+		Op       Op
+		Expected interface{}
+		Actual   interface{}
+	}
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []leaf
+	}{
+		{
+			name:     "nested change",
+			expected: `{"level1":{"level2":{"name":"Cat","id":3}}}`,
+			actual:   `{"level1":{"level2":{"name":"Dog","id":3}}}`,
+			want:     []leaf{{"level1.level2.name", OpChanged, "Cat", "Dog"}},
+		},
+		{
+			name:     "members in document order",
+			expected: `{"z":{"b":1,"c":2},"a":true}`,
+			actual:   `{"z":{"c":2,"e":3},"a":false}`,
+			want: []leaf{
+				{"z.b", OpRemoved, 1.0, nil},
+				{"z.e", OpAdded, nil, 3.0},
+				{"a", OpChanged, true, false},
+			},
+		},
+		{
+			name:     "arrays and type changes are leaves",
+			expected: `{"a":[1,{"b":2}],"c":{"d":1}}`,
+			actual:   `{"a":[1,{"b":3}],"c":"d"}`,
+			want: []leaf{
+				{"a", OpChanged, []interface{}{1.0, map[string]interface{}{"b": 2.0}}, []interface{}{1.0, map[string]interface{}{"b": 3.0}}},
+				{"c", OpChanged, map[string]interface{}{"d": 1.0}, "d"},
+			},
+		},
+		{
+			name:     "values with newlines and colons",
+			expected: `{"a":"x: 1\ny: 2"}`,
+			actual:   `{"a":"x: 1\ny: 3"}`,
+			want:     []leaf{{"a", OpChanged, "x: 1\ny: 2", "x: 1\ny: 3"}},
+		},
+		{
+			name:     "last of duplicate keys wins",
+			expected: `{"a":1,"a":2,"b":1}`,
+			actual:   `{"a":2,"b":2,"b":2}`,
+			want:     []leaf{{"b", OpChanged, 1.0, 2.0}},
+		},
+		{
+			name:     "keys with dots are escaped",
+			expected: `{"a":{"b.c":1}}`,
+			actual:   `{"a":{"b.c":2}}`,
+			want:     []leaf{{`a.b\.c`, OpChanged, 1.0, 2.0}},
+		},
+		{
+			name:     "equal documents",
+			expected: `{"a":1.0}`,
+			actual:   `{"a":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []leaf
+			for _, node := range buildDiffTree(gjson.Parse(tt.expected), gjson.Parse(tt.actual)).leaves() {
+				got = append(got, leaf{node.path, node.op, node.expected, node.actual})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("leaves = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeysAgree(t *testing.T) {
+	resp, err := CompareJSON([]byte(`{"a":1,"a":2,"b":1}`), []byte(`{"a":2,"b":2}`), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{{Path: "b", Op: OpChanged, Expected: 1.0, Actual: 2.0}}
+	if !reflect.DeepEqual(resp.Entries, want) {
+		t.Errorf("unexpected entries %+v", resp.Entries)
+	}
+	if strings.Contains(NormalizeForTest(resp.Expected), `"a": 1`) {
+		t.Errorf("expected the output to agree with the entries:\n%s", resp.Expected)
+	}
+}
@@ -0,0 +1,81 @@
+package colorisediff
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ellipsisLines is the number of lines of the ellipsis replacing the middle of a truncated difference.
+const ellipsisLines = 3
+
+// WithTruncation sets the line budget of a single rendered difference. A difference rendering to more visible lines
+// than the budget on either side has its middle replaced by a three-line ellipsis, at the same line positions on
+// both sides so the two columns stay comparable. 0 derives the budget from the average length of both sides, which
+// is the default, and a negative budget disables the truncation.
+func WithTruncation(lines int) Option {
+	return func(o *options) {
+		o.valueRenderers.truncateLines = lines
+	}
+}
+
+// truncateToMatchWithEllipsis shortens the rendered sides of a difference to the line budget by replacing the same
+// range of lines in the middle of both sides with an ellipsis. Lines are counted without their ANSI sequences, and
+// the colors active at the end of the elided range are re-opened after the ellipsis.
+// expectedText, actualText: The rendered sides of the difference.
+// budget: The maximum number of lines per side, 0 for the average length of both sides plus one, negative for no
+// limit.
+// p: The palette coloring the ellipsis.
+// Returns two strings: the truncated versions of the expected and actual texts.
+func truncateToMatchWithEllipsis(expectedText, actualText string, budget int, p palette) (string, string) {
+	expectedLines, expectedTail := splitVisibleLines(expectedText)
+	actualLines, actualTail := splitVisibleLines(actualText)
+	longest := max(len(expectedLines), len(actualLines))
+	if budget == 0 {
+		budget = (len(expectedLines)+len(actualLines))/2 + 1
+	}
+
+	// Only truncate if the ellipsis leaves room for lines around it and replaces at least as many lines as it takes.
+	if budget <= ellipsisLines || longest-budget < ellipsisLines {
+		return expectedText, actualText
+	}
+	top := (budget - ellipsisLines) / 2
+	bottom := budget - ellipsisLines - top
+	ellipsis := p.sprintFunc(color.FgYellow)(strings.Repeat(".\n", ellipsisLines-1) + ".")
+
+	// truncate replaces the lines from top up to the start of the bottom part of the longest side.
+	truncate := func(lines []string, tail string) string {
+		end := min(len(lines), longest-bottom)
+		if end <= top {
+			return strings.Join(lines, "\n") + tail
+		}
+		rest := lines[end:]
+		if active := activeSGR(strings.Join(lines[:end], "\n")); active != "" && len(rest) > 0 {
+			rest = append([]string{active + rest[0]}, rest[1:]...)
+		}
+		kept := append(append(append([]string{}, lines[:top]...), ellipsis), rest...)
+		return strings.Join(kept, "\n") + tail
+	}
+	return truncate(expectedLines, expectedTail), truncate(actualLines, actualTail)
+}
+
+// splitVisibleLines splits a rendered text into its lines and the invisible remainder after the last line break,
+// e.g. a trailing reset sequence, which is not counted as a line.
+func splitVisibleLines(text string) ([]string, string) {
+	lines := strings.Split(text, "\n")
+	last := lines[len(lines)-1]
+	if ansiRegex.ReplaceAllString(last, "") != "" {
+		return lines, ""
+	}
+	return lines[:len(lines)-1], "\n" + last
+}
+
+// activeSGR returns the escape sequence re-opening the colors still in effect at the end of a text, or "" if the
+// colors were reset.
+func activeSGR(text string) string {
+	var active sgrState
+	for _, sequence := range ansiRegex.FindAllString(text, -1) {
+		active = active.apply(sequence)
+	}
+	return active.sequence()
+}
@@ -0,0 +1,82 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateToMatchWithEllipsis(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		var out []string
+		for i := 0; i < n; i++ {
+			out = append(out, prefix+strings.Repeat("x", i%3))
+		}
+		return strings.Join(out, "\n") + "\n"
+	}
+	tests := []struct {
+		name         string
+		expected     string
+		actual       string
+		budget       int
+		wantExpected int
+		wantActual   int
+	}{
+		{name: "short sides are kept", expected: lines("e", 4), actual: lines("a", 5), wantExpected: 4, wantActual: 5},
+		{name: "default budget", expected: lines("e", 2), actual: lines("a", 20), wantExpected: 2, wantActual: 12},
+		{name: "same positions on both sides", expected: lines("e", 18), actual: lines("a", 20), budget: 10, wantExpected: 8, wantActual: 10},
+		{name: "disabled", expected: lines("e", 2), actual: lines("a", 20), budget: -1, wantExpected: 2, wantActual: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotExpected, gotActual := truncateToMatchWithEllipsis(tt.expected, tt.actual, tt.budget, palette{noColor: true})
+			if n := countLines(gotExpected); n != tt.wantExpected {
+				t.Errorf("expected side has %d lines, want %d:\n%s", n, tt.wantExpected, gotExpected)
+			}
+			if n := countLines(gotActual); n != tt.wantActual {
+				t.Errorf("actual side has %d lines, want %d:\n%s", n, tt.wantActual, gotActual)
+			}
+			if strings.Contains(gotExpected+gotActual, "\x1b") {
+				t.Errorf("expected no ANSI sequences without colors, got %q", gotActual)
+			}
+		})
+	}
+
+	// Both sides elide the same lines, so the lines after the ellipsis line up.
+	expected, actual := truncateToMatchWithEllipsis(lines("e", 18), lines("a", 20), 10, palette{noColor: true})
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	if expectedLines[3] != "." || actualLines[3] != "." || expectedLines[6] != "ex" || actualLines[6] != "ax" {
+		t.Errorf("expected the ellipsis at the same position, got:\n%s\n%s", expected, actual)
+	}
+}
+
+func TestTruncateReopensColors(t *testing.T) {
+	green := "\x1b[32m"
+	actual := green + "{\n" + strings.Repeat("  1,\n", 20) + "}" + ansiResetCode + "\n"
+	_, got := truncateToMatchWithEllipsis("{\n}\n", actual, 8, palette{})
+	lines := strings.Split(got, "\n")
+	if lines[2] != "\x1b[33m." || lines[4] != "."+ansiResetCode || lines[5] != green+"  1," {
+		t.Errorf("expected the color to be re-opened after the ellipsis, got %q", got)
+	}
+	if activeSGR(got) != "" {
+		t.Errorf("expected the colors to be reset at the end, got %q", got)
+	}
+}
+
+func TestWithTruncation(t *testing.T) {
+	expected := `{"list":[]}`
+	actual := `{"list":[{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8,"i":9,"j":10}]}`
+	full, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithTruncation(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := CompareJSON([]byte(expected), []byte(actual), nil, true, WithTruncation(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(full.Actual, "\n.\n") || !strings.Contains(full.Actual, `"j": 10`) {
+		t.Errorf("expected no truncation, got:\n%s", full.Actual)
+	}
+	if !strings.Contains(short.Actual, "\n.\n") || strings.Contains(short.Actual, `"e": 5`) || strings.Contains(short.Actual, "\x1b") {
+		t.Errorf("expected an uncolored ellipsis, got:\n%s", short.Actual)
+	}
+}
@@ -0,0 +1,219 @@
+package colorisediff
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueRenderer customizes how values are displayed in the colorized output, e.g. to show epoch timestamps as
+// dates or long opaque strings as digests. It only changes the display; values are still compared as they are.
+type ValueRenderer interface {
+	// RenderValue returns the text displayed for the value at the given gjson-style path and true, or false to
+	// leave the value to the next renderer and finally to the default JSON rendering.
+	RenderValue(path string, value interface{}) (string, bool)
+}
+
+// ValueRendererFunc adapts a function to the ValueRenderer interface.
+type ValueRendererFunc func(path string, value interface{}) (string, bool)
+
+// RenderValue calls f(path, value).
+func (f ValueRendererFunc) RenderValue(path string, value interface{}) (string, bool) {
+	return f(path, value)
+}
+
+// WithValueRenderer makes the colorizer display values through the given renderers, which are tried in order,
+// instead of their JSON encoding.
+func WithValueRenderer(renderers ...ValueRenderer) Option {
+	return func(o *options) {
+		o.valueRenderers.renderers = append(o.valueRenderers.renderers, renderers...)
+	}
+}
+
+// RenderEpochMillis returns a renderer displaying the numbers at paths matching the pattern, milliseconds since
+// the Unix epoch, as RFC 3339 dates in UTC. See SeverityRule for the pattern syntax.
+func RenderEpochMillis(pattern string) ValueRenderer {
+	return ValueRendererFunc(func(path string, value interface{}) (string, bool) {
+		millis, ok := value.(float64)
+		if !ok || !matchPathPattern(pattern, path) {
+			return "", false
+		}
+		return time.UnixMilli(int64(millis)).UTC().Format(time.RFC3339Nano), true
+	})
+}
+
+// RenderDigest returns a renderer displaying strings of at least minLength bytes, e.g. base64-encoded payloads,
+// as the start of their SHA-256 digest and their length, e.g. "sha256:1f2e3d4c5b6a (4096 bytes)".
+func RenderDigest(minLength int) ValueRenderer {
+	return ValueRendererFunc(func(_ string, value interface{}) (string, bool) {
+		s, ok := value.(string)
+		if !ok || len(s) < minLength {
+			return "", false
+		}
+		digest := sha256.Sum256([]byte(s))
+		return fmt.Sprintf("sha256:%x (%d bytes)", digest[:6], len(s)), true
+	})
+}
+
+// valueRenderers holds the renderers given with WithValueRenderer and the layout given with WithIndent and
+// WithCompact, which together decide how values are displayed.
+type valueRenderers struct {
+	renderers         []ValueRenderer
+	indent            string                                      // indent is the indentation unit, two spaces if empty.
+	compactWidth      int                                         // compactWidth is the width up to which objects and arrays are kept on one line.
+	keyOrder          keyOrder                                    // keyOrder is the order of the keys in the source documents, nil to sort them.
+	stringWindow      int                                         // stringWindow is the number of characters shown around changed words of strings, 0 for all.
+	fullBinary        bool                                        // fullBinary shows binary-looking strings in full instead of a summary.
+	redact            []func(path string, value interface{}) bool // redact decides which values are masked.
+	truncateLines     int                                         // truncateLines is the line budget of a rendered difference, 0 for the default.
+	chunkSize         int                                         // chunkSize is the length from which changed values are rendered in chunks, 0 to disable.
+	markers           Markers                                     // markers mark the text left out of values and the wrapped lines.
+	visibleWhitespace bool                                        // visibleWhitespace shows the invisible whitespace of changed strings.
+	numericDeltas     bool                                        // numericDeltas annotates changed numbers with their difference.
+	textSummaryLength int                                         // textSummaryLength is the length from which changed strings are summarized, 0 to disable.
+	warn              func(Warning)                               // warn reports the values that could not be rendered, nil to drop the reports.
+}
+
+// reportUnrenderable reports a value found at a path in the colorizer's ".key[0]" notation that could not be
+// rendered on a side.
+func (r valueRenderers) reportUnrenderable(legacyPath, side string, err error) {
+	if r.warn != nil {
+		r.warn(Warning{Kind: WarningUnrenderableValue, Side: side, Path: legacyToPath(legacyPath), Message: "value cannot be rendered: " + err.Error()})
+	}
+}
+
+// render offers a value found at a path in the colorizer's ".key[0]" notation to the renderers, and finally shows
+// binary-looking strings as a summary.
+func (r valueRenderers) render(legacyPath string, value interface{}) (string, bool) {
+	if len(r.renderers) == 0 && len(r.redact) == 0 {
+		return r.renderBinary(value)
+	}
+	return r.renderAt(legacyToPath(legacyPath), value)
+}
+
+// claims reports whether a renderer, e.g. a redaction, claims any of the values found at a path in the colorizer's
+// ".key[0]" notation, so no annotation may reveal more about them.
+func (r valueRenderers) claims(legacyPath string, values ...interface{}) bool {
+	for _, value := range values {
+		if _, claimed := r.render(legacyPath, value); claimed {
+			return true
+		}
+	}
+	return false
+}
+
+// renderAt offers a value found at a gjson-style path to the renderers.
+func (r valueRenderers) renderAt(path string, value interface{}) (string, bool) {
+	if r.redacted(path, value) {
+		return redactedValue, true
+	}
+	for _, renderer := range r.renderers {
+		if text, ok := renderer.RenderValue(path, value); ok {
+			return text, true
+		}
+	}
+	return r.renderBinary(value)
+}
+
+// serialize renders a value as indented JSON unless a renderer claims it.
+func (r valueRenderers) serialize(legacyPath string, value interface{}) string {
+	bytes, err := r.marshal(legacyPath, value)
+	if err != nil {
+		return "error"
+	}
+	return string(bytes)
+}
+
+// marshal renders a value as indented JSON, or on one line if it fits the compact width, unless a renderer claims
+// it. Right-to-left text is isolated with isolateBidi.
+func (r valueRenderers) marshal(legacyPath string, value interface{}) ([]byte, error) {
+	text, err := r.marshalValue(legacyPath, value)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(isolateBidi(string(text))), nil
+}
+
+// marshalValue implements marshal before the right-to-left text is isolated.
+func (r valueRenderers) marshalValue(legacyPath string, value interface{}) ([]byte, error) {
+	if text, ok := r.render(legacyPath, value); ok {
+		return []byte(text), nil
+	}
+	value = r.mask(legacyToPath(legacyPath), value)
+	if r.keyOrder != nil {
+		path := legacyToPath(legacyPath)
+		if r.compactWidth > 0 {
+			if compact, err := r.keyOrder.marshal(path, value, "", ""); err == nil && len(compact) <= r.compactWidth {
+				return compact, nil
+			}
+		}
+		return r.keyOrder.marshal(path, value, "", r.indentUnit())
+	}
+	if r.compactWidth > 0 {
+		if compact, err := json.Marshal(value); err == nil && len(compact) <= r.compactWidth {
+			return compact, nil
+		}
+	}
+	return json.MarshalIndent(value, "", r.indentUnit())
+}
+
+// keys returns the keys of an object found at a path in the colorizer's ".key[0]" notation in display order.
+func (r valueRenderers) keys(legacyPath string, object map[string]interface{}) []string {
+	keys := unionKeys(object, nil)
+	if r.keyOrder == nil {
+		return keys
+	}
+	return r.keyOrder.sort(legacyToPath(legacyPath), keys)
+}
+
+// indentUnit returns the indentation added per nesting level.
+func (r valueRenderers) indentUnit() string {
+	if r.indent == "" {
+		return "  "
+	}
+	return r.indent
+}
+
+// format renders the value of an entry like formatValue unless a renderer claims it. Right-to-left text is isolated
+// with isolateBidi.
+func (r valueRenderers) format(path string, value interface{}) string {
+	if text, ok := r.renderAt(path, value); ok {
+		return isolateBidi(text)
+	}
+	return isolateBidi(formatValue(r.mask(path, value)))
+}
+
+// legacyToPath converts a path in the colorizer's ".key[0]" notation into a gjson-style path.
+func legacyToPath(legacyPath string) string {
+	if legacyPath != "" && legacyPath[0] != '.' && legacyPath[0] != '[' {
+		legacyPath = "." + legacyPath
+	}
+	path := ""
+	for len(legacyPath) > 0 {
+		switch legacyPath[0] {
+		case '.':
+			end := strings.IndexAny(legacyPath[1:], ".[")
+			if end < 0 {
+				end = len(legacyPath) - 1
+			}
+			path = joinPath(path, escapePathKey(legacyPath[1:end+1]))
+			legacyPath = legacyPath[end+1:]
+		case '[':
+			end := strings.IndexByte(legacyPath, ']')
+			if end < 0 {
+				return joinPath(path, escapePathKey(legacyPath))
+			}
+			if _, err := strconv.Atoi(legacyPath[1:end]); err != nil {
+				return joinPath(path, escapePathKey(legacyPath))
+			}
+			path = joinPath(path, legacyPath[1:end])
+			legacyPath = legacyPath[end+1:]
+		default:
+			return joinPath(path, escapePathKey(legacyPath[1:]))
+		}
+	}
+	return path
+}
@@ -0,0 +1,49 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValueRenderer(t *testing.T) {
+	expected := `{"createdAt": 1700000000000, "user": {"updatedAt": 1700000000000, "avatar": "QUJDREVGR0hJSktMTU5PUA=="}}`
+	actual := `{"createdAt": 1700000001000, "user": {"updatedAt": 1700000002000, "avatar": "UVJTVFVWV1hZWjAxMjM0NQ=="}}`
+
+	resp, err := CompareJSON([]byte(expected), []byte(actual), nil, true,
+		WithValueRenderer(RenderEpochMillis("**.*At"), RenderDigest(16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2023-11-14T22:13:20Z", "user", "sha256:"} {
+		if !strings.Contains(resp.Expected, want) {
+			t.Errorf("expected %q in %q", want, resp.Expected)
+		}
+	}
+	for _, want := range []string{"2023-11-14T22:13:21Z", "2023-11-14T22:13:22Z"} {
+		if !strings.Contains(resp.Actual, want) {
+			t.Errorf("expected %q in %q", want, resp.Actual)
+		}
+	}
+	if strings.Contains(resp.Actual, "1700000001000") || strings.Contains(resp.Actual, "UVJTVFVW") {
+		t.Errorf("expected raw values to be rendered, got %q", resp.Actual)
+	}
+	if entry := resp.At("createdAt"); entry == nil || entry.Actual != float64(1700000001000) {
+		t.Errorf("expected the entries to keep the raw values, got %+v", entry)
+	}
+}
+
+func TestLegacyToPath(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		".a.b[0].c":    "a.b.0.c",
+		"[2].name":     "2.name",
+		"key":          "key",
+		".a[x]":        "a.[x]",
+		".user.e-mail": "user.e-mail",
+	}
+	for input, want := range tests {
+		if got := legacyToPath(input); got != want {
+			t.Errorf("legacyToPath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package colorisediff
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// waiverDateLayout is the layout of expiry dates given without a time.
+const waiverDateLayout = "2006-01-02"
+
+// Waiver accepts a known difference, so it no longer fails the comparison until the waiver expires.
+// Path: A gjson-style path pattern like those of SeverityRule, e.g. "items.*.price".
+// Value: A regular expression the differing value has to match, e.g. `^1\d\.\d+$`, empty to match any value. It is
+// matched against the actual value, or the expected one for removed values; strings are matched without quotes and
+// other values as JSON.
+// Reason: Why the difference is accepted, e.g. a ticket number.
+// Expires: When the waiver stops applying, the zero time for never.
+type Waiver struct {
+	Path    string
+	Value   string
+	Reason  string
+	Expires time.Time
+}
+
+// WithWaivers accepts the body differences matched by an unexpired waiver. Accepted entries are marked as noised
+// with the reason of their waiver in DiffEntry.Waiver, so they are excluded from the failure count, and they are
+// listed in a dimmed "accepted" section below the differences instead of among them.
+func WithWaivers(waivers ...Waiver) Option {
+	return func(o *options) {
+		for _, waiver := range waivers {
+			waiver.Path = o.pathPattern(waiver.Path)
+			o.waivers = append(o.waivers, waiver)
+		}
+	}
+}
+
+// LoadWaivers reads a waiver file in YAML or JSON. See ParseWaivers for its layout.
+func LoadWaivers(path string) ([]Waiver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	waivers, err := ParseWaivers(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return waivers, nil
+}
+
+// ParseWaivers parses a waiver file in YAML or JSON: a "waivers" list, or a bare list, of waivers with a "path", an
+// optional "value" pattern, a "reason" and an optional "expires" date such as "2026-12-31" or RFC 3339 time. A date
+// expires at the end of that day, UTC.
+func ParseWaivers(data []byte) ([]Waiver, error) {
+	type waiverFile struct {
+		Path    string `yaml:"path"`
+		Value   string `yaml:"value"`
+		Reason  string `yaml:"reason"`
+		Expires string `yaml:"expires"`
+	}
+	var list []waiverFile
+	var root struct {
+		Waivers []waiverFile `yaml:"waivers"`
+	}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("decoding waivers: %w", err)
+		}
+		list = root.Waivers
+	}
+
+	waivers := make([]Waiver, 0, len(list))
+	for i, item := range list {
+		if item.Path == "" {
+			return nil, fmt.Errorf("waiver %d: missing path", i+1)
+		}
+		if _, err := regexp.Compile(item.Value); err != nil {
+			return nil, fmt.Errorf("waiver %d: value: %w", i+1, err)
+		}
+		waiver := Waiver{Path: item.Path, Value: item.Value, Reason: item.Reason}
+		if item.Expires != "" {
+			expires, err := parseWaiverExpiry(item.Expires)
+			if err != nil {
+				return nil, fmt.Errorf("waiver %d: expires: %w", i+1, err)
+			}
+			waiver.Expires = expires
+		}
+		waivers = append(waivers, waiver)
+	}
+	return waivers, nil
+}
+
+// parseWaiverExpiry parses an RFC 3339 time, or a date standing for the last moment of that day in UTC.
+func parseWaiverExpiry(text string) (time.Time, error) {
+	if date, err := time.Parse(waiverDateLayout, text); err == nil {
+		return date.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, text)
+}
+
+// Expired reports whether the waiver no longer applies at the given time.
+func (w Waiver) Expired(now time.Time) bool {
+	return !w.Expires.IsZero() && now.After(w.Expires)
+}
+
+// matches reports whether the waiver accepts the entry. Invalid value patterns match nothing.
+func (w Waiver) matches(entry DiffEntry) bool {
+	if !matchPathPattern(w.Path, entry.Path) {
+		return false
+	}
+	if w.Value == "" {
+		return true
+	}
+	pattern, err := regexp.Compile(w.Value)
+	if err != nil {
+		return false
+	}
+	value := entry.Actual
+	if entry.Op == OpRemoved {
+		value = entry.Expected
+	}
+	text, ok := value.(string)
+	if !ok {
+		text = formatValue(value)
+	}
+	return pattern.MatchString(text)
+}
+
+// waive marks an entry accepted by the first unexpired waiver matching it.
+func (o *options) waive(entry DiffEntry) DiffEntry {
+	if entry.Noised || len(o.waivers) == 0 {
+		return entry
+	}
+	now := time.Now()
+	for _, waiver := range o.waivers {
+		if waiver.Expired(now) || !waiver.matches(entry) {
+			continue
+		}
+		entry.Noised = true
+		entry.Waiver = waiver.Reason
+		if entry.Waiver == "" {
+			entry.Waiver = "accepted"
+		}
+		break
+	}
+	return entry
+}
+
+// waivedPaths returns the paths of the accepted entries.
+func waivedPaths(entries []DiffEntry) map[string]bool {
+	var paths map[string]bool
+	for _, entry := range entries {
+		if entry.Waiver != "" {
+			if paths == nil {
+				paths = map[string]bool{}
+			}
+			paths[entry.Path] = true
+		}
+	}
+	return paths
+}
+
+// withoutWaived returns the tree without the leaves whose differences were all accepted, or nil if none are left.
+// Leaves without entries are kept.
+func (n *diffNode) withoutWaived(entries []DiffEntry, waived map[string]bool) *diffNode {
+	if n == nil {
+		return nil
+	}
+	if len(n.children) == 0 {
+		accepted := false
+		for _, entry := range entries {
+			if !isPathWithin(entry.Path, n.path) {
+				continue
+			}
+			if !waived[entry.Path] {
+				return n
+			}
+			accepted = true
+		}
+		if accepted {
+			return nil
+		}
+		return n
+	}
+	pruned := *n
+	pruned.children = nil
+	for _, child := range n.children {
+		if child = child.withoutWaived(entries, waived); child != nil {
+			pruned.children = append(pruned.children, child)
+		}
+	}
+	if len(pruned.children) == 0 {
+		return nil
+	}
+	return &pruned
+}
+
+// isPathWithin reports whether a path equals or lies below another path.
+func isPathWithin(path, parent string) bool {
+	return parent == "" || path == parent || strings.HasPrefix(path, parent+".")
+}
+
+// renderAccepted renders the dimmed section listing the accepted differences with the reasons of their waivers.
+func renderAccepted(entries []DiffEntry, p palette) string {
+	dim := p.sprintFunc(color.Faint)
+	var section string
+	for _, entry := range entries {
+		if entry.Waiver == "" {
+			continue
+		}
+		if section == "" {
+			label := " accepted:"
+			section = breakWithColor(label, dim, []colorRange{{Start: 0, End: len(label)}})
+		}
+		path := entry.Path
+		if path == "" {
+			path = "(root)"
+		}
+		line := "   " + path + "  # " + entry.Waiver
+		section += breakWithColor(line, dim, []colorRange{{Start: 0, End: len(line)}})
+	}
+	return section
+}
@@ -0,0 +1,77 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithWaivers(t *testing.T) {
+	json1 := `{"id":1,"name":"Cat","prices":{"a":10.5,"b":20}}`
+	json2 := `{"id":1,"name":"Dog","prices":{"a":10.49,"b":21}}`
+	waivers := []Waiver{
+		{Path: "prices.*", Value: `^10\.\d+$`, Reason: "price rounding"},
+		{Path: "name", Reason: "renamed", Expires: time.Now().Add(-time.Hour)},
+	}
+
+	resp, err := CompareJSON([]byte(json1), []byte(json2), nil, true, WithWaivers(waivers...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := resp.At("prices.a"); entry == nil || !entry.Noised || entry.Waiver != "price rounding" {
+		t.Errorf("expected the rounded price to be accepted, got %+v", entry)
+	}
+	if entry := resp.At("prices.b"); entry == nil || entry.Noised {
+		t.Errorf("expected the price outside the pattern to fail, got %+v", entry)
+	}
+	if entry := resp.At("name"); entry == nil || entry.Noised {
+		t.Errorf("expected the expired waiver not to apply, got %+v", entry)
+	}
+	if stats := resp.Stats(); stats.Total != 2 || stats.Accepted != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if !strings.Contains(resp.Actual, " accepted:\n   prices.a  # price rounding\n") || strings.Contains(resp.Actual, "10.49") {
+		t.Errorf("expected the accepted difference in its own section, got:\n%s", resp.Actual)
+	}
+
+	resp, err = CompareJSON([]byte(`{"a":1}`), []byte(`{"a":2}`), nil, true, WithWaivers(Waiver{Path: "a"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stats().Total != 0 || !strings.Contains(resp.Expected, "   a  # accepted\n") {
+		t.Errorf("expected the only difference to be accepted, got %+v:\n%s", resp.Entries, resp.Expected)
+	}
+}
+
+func TestParseWaivers(t *testing.T) {
+	waivers, err := ParseWaivers([]byte(`
+waivers:
+  - path: items.*.price
+    value: '^10\.\d+$'
+    reason: JIRA-123
+    expires: 2026-12-31
+  - path: ts
+    expires: 2026-06-01T12:00:00Z
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(waivers) != 2 || waivers[0].Reason != "JIRA-123" || waivers[0].Value != `^10\.\d+$` {
+		t.Fatalf("unexpected waivers %+v", waivers)
+	}
+	if waivers[0].Expired(time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC)) || !waivers[0].Expired(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the date to expire at the end of the day, got %v", waivers[0].Expires)
+	}
+	if !waivers[1].Expires.Equal(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiry %v", waivers[1].Expires)
+	}
+
+	if waivers, err := ParseWaivers([]byte(`[{"path": "a", "reason": "known"}]`)); err != nil || len(waivers) != 1 || !waivers[0].Expires.IsZero() {
+		t.Errorf("expected a bare JSON list to parse, got %+v, %v", waivers, err)
+	}
+	for _, data := range []string{`[{"reason": "no path"}]`, `[{"path": "a", "value": "("}]`, `[{"path": "a", "expires": "soon"}]`} {
+		if _, err := ParseWaivers([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package colorisediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExactExponent is the largest decimal exponent of a number literal checked exactly for precision loss; numbers
+// with larger exponents are beyond the range of float64 anyway.
+const maxExactExponent = 400
+
+// WarningKind classifies a Warning.
+type WarningKind string
+
+const (
+	// WarningLossyNumber marks a number that cannot be represented exactly as a float64, e.g. a 20-digit ID, and is
+	// compared with its nearest float64 instead.
+	WarningLossyNumber WarningKind = "lossyNumber"
+	// WarningDuplicateKey marks a key appearing more than once in an object; the last of its values is compared.
+	WarningDuplicateKey WarningKind = "duplicateKey"
+	// WarningTruncatedOutput marks a colorized output that leaves out differences, e.g. because of
+	// WithMaxOutputLines or WithMemoryBudget.
+	WarningTruncatedOutput WarningKind = "truncatedOutput"
+	// WarningInvalidEmbeddedJSON marks a string that looks like an embedded JSON document but does not decode, so
+	// WithNestedJSONStrings compares it as text.
+	WarningInvalidEmbeddedJSON WarningKind = "invalidEmbeddedJSON"
+	// WarningUnrenderableValue marks a value that could not be rendered, e.g. because a layout failed to encode
+	// it, and is left out of the colorized output.
+	WarningUnrenderableValue WarningKind = "unrenderableValue"
+)
+
+// Warning reports a data-quality issue that did not stop the comparison but may make its result misleading.
+// Kind: What went wrong.
+// Side: The document the issue was found in, "expected" or "actual", empty if it concerns the result.
+// Path: The gjson-style path of the value concerned, "" for the root.
+// Message: A description of the issue, e.g. "12345678901234567890 is compared as 12345678901234567000".
+type Warning struct {
+	Kind    WarningKind `json:"kind"`
+	Side    string      `json:"side,omitempty"`
+	Path    string      `json:"path"`
+	Message string      `json:"message"`
+}
+
+// String describes the warning, e.g. `actual "id": 12345678901234567890 is compared as 12345678901234567000`.
+func (w Warning) String() string {
+	location := quotePath(w.Path)
+	if w.Side != "" {
+		location = w.Side + " " + location
+	}
+	return location + ": " + w.Message
+}
+
+// scanWarnings walks the tokens of a JSON document and returns warnings for its numbers losing precision and its
+// duplicate keys. The messages leave out the numbers for which redacted returns true. Syntax errors end the scan;
+// they are reported by the decoding proper.
+func scanWarnings(data []byte, side string, redacted func(path string, value interface{}) bool) []Warning {
+	type frame struct {
+		path   string
+		object bool
+		key    string         // key is the last key read in an object.
+		isKey  bool           // isKey tells that the next string of an object is a key.
+		keys   map[string]int // keys counts the keys read in an object.
+		index  int            // index is the index of the next element of an array.
+	}
+	var stack []*frame
+	var warnings []Warning
+	// valuePath returns the path of the value starting at the current token.
+	valuePath := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.isKey = true
+			return joinPath(top.path, escapePathKey(top.key))
+		}
+		top.index++
+		return joinPath(top.path, strconv.Itoa(top.index-1))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return warnings
+		}
+		if len(stack) > 0 && stack[len(stack)-1].isKey {
+			if key, ok := token.(string); ok {
+				top := stack[len(stack)-1]
+				top.key, top.isKey = key, false
+				if top.keys[key]++; top.keys[key] == 2 {
+					warnings = append(warnings, Warning{
+						Kind: WarningDuplicateKey, Side: side, Path: joinPath(top.path, escapePathKey(key)),
+						Message: fmt.Sprintf("key %s appears more than once, the last value is compared", quoteKey(key)),
+					})
+				}
+				continue
+			}
+		}
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, &frame{path: valuePath(), object: true, isKey: true, keys: map[string]int{}})
+			case '[':
+				stack = append(stack, &frame{path: valuePath()})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case json.Number:
+			path := valuePath()
+			if rounded, lossy := lossyNumber(t.String()); lossy {
+				message := fmt.Sprintf("%s is compared as %s", t, rounded)
+				if f, _ := t.Float64(); redacted(path, f) {
+					message = "number is compared as its nearest float64"
+				}
+				warnings = append(warnings, Warning{Kind: WarningLossyNumber, Side: side, Path: path, Message: message})
+			}
+		default:
+			valuePath()
+		}
+	}
+}
+
+// lossyNumber reports whether a JSON number literal changes its value when decoded as a float64, and returns the
+// value it is decoded as, encoded like the values of the output.
+func lossyNumber(literal string) (string, bool) {
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	rounded := formatValue(f)
+	mantissa, exponent, _ := strings.Cut(strings.ToLower(literal), "e")
+	if e, err := strconv.Atoi(exponent); exponent != "" && (err != nil || e > maxExactExponent || e < -maxExactExponent) {
+		// Only zero survives such an exponent exactly.
+		return rounded, f != 0 || strings.Trim(mantissa, "-+0.") != ""
+	}
+	exact, ok := new(big.Rat).SetString(literal)
+	if !ok {
+		return rounded, false
+	}
+	approximation, ok := new(big.Rat).SetString(rounded)
+	return rounded, !ok || exact.Cmp(approximation) != 0
+}
+
+// reportInvalidEmbeddedJSON returns a transform that never applies but reports the differing strings that look like
+// embedded JSON documents, starting with "{" or "[", without decoding as one.
+func reportInvalidEmbeddedJSON(warn func(Warning)) pairTransform {
+	return func(path string, expected, actual interface{}) (interface{}, interface{}, string, bool) {
+		e, a, ok := differingStrings(expected, actual)
+		if !ok {
+			return nil, nil, "", false
+		}
+		for _, side := range []struct{ name, value string }{{"expected", e}, {"actual", a}} {
+			trimmed := strings.TrimSpace(side.value)
+			if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+				continue
+			}
+			if _, ok := decodeEmbeddedJSON(trimmed); !ok {
+				warn(Warning{
+					Kind: WarningInvalidEmbeddedJSON, Side: side.name, Path: path,
+					Message: "string looks like embedded JSON but does not decode, it is compared as text",
+				})
+			}
+		}
+		return nil, nil, "", false
+	}
+}
+
+// truncationWarnings returns the warnings for the differences left out of the colorized output.
+func truncationWarnings(omitted int, spilled []SpillSummary) []Warning {
+	var warnings []Warning
+	if omitted > 0 {
+		warnings = append(warnings, Warning{
+			Kind:    WarningTruncatedOutput,
+			Message: fmt.Sprintf("the output is truncated, %d differences are omitted", omitted),
+		})
+	}
+	for _, summary := range spilled {
+		warnings = append(warnings, Warning{
+			Kind: WarningTruncatedOutput, Path: summary.Path,
+			Message: fmt.Sprintf("the memory budget is exceeded, %d differences are only counted", summary.Differences+summary.Noised),
+		})
+	}
+	return warnings
+}
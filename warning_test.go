@@ -0,0 +1,80 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLossyNumber(t *testing.T) {
+	tests := []struct {
+		literal string
+		rounded string
+		lossy   bool
+	}{
+		{literal: "42", rounded: "42"},
+		{literal: "0.1", rounded: "0.1"},
+		{literal: "1.50", rounded: "1.5"},
+		{literal: "-2.5e3", rounded: "-2500"},
+		{literal: "12345678901234567890", rounded: "12345678901234567000", lossy: true},
+		{literal: "1.0000000000000000001", rounded: "1", lossy: true},
+		{literal: "1e400", rounded: "+Inf", lossy: true},
+		{literal: "1e-99999", rounded: "0", lossy: true},
+		{literal: "0e-99999", rounded: "0"},
+	}
+	for _, tt := range tests {
+		rounded, lossy := lossyNumber(tt.literal)
+		if rounded != tt.rounded || lossy != tt.lossy {
+			t.Errorf("lossyNumber(%s) = %s, %v, want %s, %v", tt.literal, rounded, lossy, tt.rounded, tt.lossy)
+		}
+	}
+}
+
+func TestScanWarnings(t *testing.T) {
+	data := []byte(`{"id":12345678901234567890,"a":1,"a":2,"list":[{"b":1,"b":1},0.1],"c":{"d":[1,2,99999999999999999]}}`)
+	want := []Warning{
+		{Kind: WarningLossyNumber, Side: "actual", Path: "id", Message: "12345678901234567890 is compared as 12345678901234567000"},
+		{Kind: WarningDuplicateKey, Side: "actual", Path: "a", Message: `key "a" appears more than once, the last value is compared`},
+		{Kind: WarningDuplicateKey, Side: "actual", Path: "list.0.b", Message: `key "b" appears more than once, the last value is compared`},
+		{Kind: WarningLossyNumber, Side: "actual", Path: "c.d.2", Message: "99999999999999999 is compared as 100000000000000000"},
+	}
+	if got := scanWarnings(data, "actual", valueRenderers{}.redacted); !reflect.DeepEqual(got, want) {
+		t.Errorf("scanWarnings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffWarnings(t *testing.T) {
+	expected := []byte(`{"id":1,"payload":"{\"a\":1}","items":[1,2,3,4]}`)
+	actual := []byte(`{"id":12345678901234567890,"payload":"{\"a\":","items":[5,6,7,8]}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithNestedJSONStrings(), WithMaxOutputLines(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[WarningKind]int{}
+	for _, warning := range resp.Warnings {
+		kinds[warning.Kind]++
+	}
+	want := map[WarningKind]int{WarningLossyNumber: 1, WarningInvalidEmbeddedJSON: 1, WarningTruncatedOutput: 1}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("got warnings %+v", resp.Warnings)
+	}
+	if got := resp.Warnings[0].String(); got != `actual "id": 12345678901234567890 is compared as 12345678901234567000` {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestDiffWarningsRedacted(t *testing.T) {
+	expected := []byte(`{"pin":1}`)
+	actual := []byte(`{"pin":12345678901234567891}`)
+	resp, err := CompareJSON(expected, actual, nil, true, WithRedact("pin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Kind != WarningLossyNumber {
+		t.Fatalf("got warnings %+v", resp.Warnings)
+	}
+	if message := resp.Warnings[0].Message; strings.Contains(message, "1234567890") {
+		t.Errorf("warning leaks the redacted value: %q", message)
+	}
+}
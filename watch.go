@@ -0,0 +1,67 @@
+package colorisediff
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// defaultWatchInterval is the polling interval used by WatchFiles when none is given.
+const defaultWatchInterval = 500 * time.Millisecond
+
+// fileState identifies a version of a watched file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	missing bool
+}
+
+// WatchFiles compares the files of the pair like CompareBatch and calls onChange with the diff, or with the error of
+// reading or comparing the files, right away and again whenever either file changes, until the context is done. The
+// files are polled every interval (500ms if it is not positive), so editors that replace the files on save are
+// handled like in-place writes. It returns the error of the context.
+func WatchFiles(ctx context.Context, pair FilePair, interval time.Duration, noise map[string][]string, disableColor bool, onChange func(Diff, error), opts ...Option) error {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last [2]fileState
+	first := true
+	for {
+		current := [2]fileState{statFile(pair.Expected), statFile(pair.Actual)}
+		if first || current != last {
+			first = false
+			last = current
+			onChange(compareFiles(pair, noise, disableColor, opts))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// statFile returns the current state of the named file.
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{missing: true}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
+
+// compareFiles reads and compares the files of the pair.
+func compareFiles(pair FilePair, noise map[string][]string, disableColor bool, opts []Option) (Diff, error) {
+	expected, err := os.ReadFile(pair.Expected)
+	if err != nil {
+		return Diff{}, err
+	}
+	actual, err := os.ReadFile(pair.Actual)
+	if err != nil {
+		return Diff{}, err
+	}
+	return CompareJSON(expected, actual, noise, disableColor, opts...)
+}
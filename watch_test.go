@@ -0,0 +1,54 @@
+package colorisediff
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	pair := FilePair{Expected: filepath.Join(dir, "expected.json"), Actual: filepath.Join(dir, "actual.json")}
+	if err := os.WriteFile(pair.Expected, []byte(`{"name":"Cat"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pair.Actual, []byte(`{"name":"Dog"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runs := make(chan Diff, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchFiles(ctx, pair, 10*time.Millisecond, nil, true, func(diff Diff, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			runs <- diff
+		})
+	}()
+
+	if diff := <-runs; diff.Stats().Changed != 1 {
+		t.Errorf("expected the initial comparison to report the change, got %+v", diff.Entries)
+	}
+	if err := os.WriteFile(pair.Actual, []byte(`{"name":"Cat"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case diff := <-runs:
+		if diff.Stats().Total != 0 {
+			t.Errorf("expected no differences after the fix, got %+v", diff.Entries)
+		}
+	case <-ctx.Done():
+		t.Fatal("the change was not picked up")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
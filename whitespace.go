@@ -0,0 +1,56 @@
+package colorisediff
+
+import "strings"
+
+// WithVisibleWhitespace shows invisible whitespace in changed string values with visible symbols: trailing spaces
+// as "·", tabs as "→" and non-breaking spaces as "⍽", so values that only differ in whitespace no longer look
+// identical. Values are still compared as they are.
+func WithVisibleWhitespace() Option {
+	return func(o *options) {
+		o.valueRenderers.visibleWhitespace = true
+	}
+}
+
+// serializeChanged serializes a changed value like serialize, showing the invisible whitespace of strings if asked
+// to.
+func (r valueRenderers) serializeChanged(legacyPath string, value interface{}) string {
+	text := r.serialize(legacyPath, value)
+	if _, isString := value.(string); isString && r.visibleWhitespace {
+		return showWhitespace(text)
+	}
+	return text
+}
+
+// showWhitespace replaces the invisible whitespace of a string value rendered as a JSON string with visible
+// symbols. Escaped tabs are replaced, but not escaped backslashes followed by a "t". The bidi isolate of
+// right-to-left values is kept.
+func showWhitespace(text string) string {
+	quoted, isolate := stripIsolate(text)
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return text
+	}
+	content := quoted[1 : len(quoted)-1]
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == '\\' && i+1 < len(content):
+			if content[i+1] == 't' {
+				builder.WriteString("→")
+			} else {
+				builder.WriteString(content[i : i+2])
+			}
+			i++
+		case strings.HasPrefix(content[i:], "\u00a0"):
+			builder.WriteString("⍽")
+			i += len("\u00a0") - 1
+		case content[i] == ' ' && strings.TrimRight(content[i:], " ") == "":
+			builder.WriteString(strings.Repeat("·", len(content)-i))
+			i = len(content)
+		default:
+			builder.WriteByte(content[i])
+		}
+	}
+	builder.WriteByte('"')
+	return isolate(builder.String())
+}
@@ -0,0 +1,51 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowWhitespace(t *testing.T) {
+	tests := []struct {
+		name   string
+		quoted string
+		want   string
+	}{
+		{name: "trailing spaces", quoted: `"abc  "`, want: `"abc··"`},
+		{name: "inner spaces are kept", quoted: `"a b "`, want: `"a b·"`},
+		{name: "tabs", quoted: `"a\tb"`, want: `"a→b"`},
+		{name: "escaped backslash", quoted: `"a\\tb"`, want: `"a\\tb"`},
+		{name: "non-breaking space", quoted: "\"a\u00a0b\"", want: `"a⍽b"`},
+		{name: "not a string", quoted: `42`, want: `42`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := showWhitespace(tt.quoted); got != tt.want {
+				t.Errorf("showWhitespace(%q) = %q, want %q", tt.quoted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithVisibleWhitespace(t *testing.T) {
+	expected := []byte(`{"name":"Alice","tags":["a\tb"]}`)
+	actual := []byte("{\"name\":\"Alice \",\"tags\":[\"a\u00a0b\"]}")
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithVisibleWhitespace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Alice"`, `"a→b"`} {
+		if !strings.Contains(resp.Expected, want) {
+			t.Errorf("expected %s in:\n%s", want, resp.Expected)
+		}
+	}
+	for _, want := range []string{`"Alice·"`, `"a⍽b"`} {
+		if !strings.Contains(resp.Actual, want) {
+			t.Errorf("expected %s in:\n%s", want, resp.Actual)
+		}
+	}
+	if resp.Entries[0].Actual != "Alice " {
+		t.Errorf("expected the entries to keep the raw value, got %q", resp.Entries[0].Actual)
+	}
+}
@@ -0,0 +1,79 @@
+package colorisediff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithStringWindow shortens changed string values in the colorized output to the words within the given number of
+// characters around each changed word. The words left out are replaced by "…", and every shown run of words that
+// does not start the value is preceded by its character offset in the rendered value, e.g. "…(@2048)", so a one-word
+// change in a long paragraph does not print the entire paragraph on both sides.
+func WithStringWindow(characters int) Option {
+	return func(o *options) {
+		o.valueRenderers.stringWindow = characters
+	}
+}
+
+// windowWords shortens a rendered value to the words within window characters of the changed words, whose indices
+// are given, and returns the shortened value with the indices of the changed words in it. The value is returned as
+// is if no word would be left out. The words left out are replaced by the ellipsis marker.
+func windowWords(s string, changed []int, window int, markers Markers) (string, []int) {
+	words := strings.Split(s, " ")
+	if window <= 0 || len(changed) == 0 {
+		return s, changed
+	}
+	starts := make([]int, len(words))
+	ends := make([]int, len(words))
+	position := 0
+	for i, word := range words {
+		starts[i] = position
+		ends[i] = position + utf8.RuneCountInString(word)
+		position = ends[i] + 1
+	}
+
+	keep := make([]bool, len(words))
+	isChanged := make(map[int]bool, len(changed))
+	omitted := false
+	for _, c := range changed {
+		isChanged[c] = true
+	}
+	// The changed words are in ascending order, so the first one whose window does not end before a word is the
+	// only one whose window can reach it.
+	next := 0
+	for i := range words {
+		for next < len(changed) && (changed[next] >= len(words) || ends[changed[next]]+window < starts[i]) {
+			next++
+		}
+		keep[i] = next < len(changed) && ends[i] >= starts[changed[next]]-window
+		omitted = omitted || !keep[i]
+	}
+	if !omitted {
+		return s, changed
+	}
+
+	var shown []string
+	var offsets []int
+	for i, word := range words {
+		if !keep[i] {
+			continue
+		}
+		if i > 0 && !keep[i-1] {
+			// The offset leaves out the opening quote of strings.
+			offset := starts[i]
+			if strings.HasPrefix(s, `"`) {
+				offset--
+			}
+			shown = append(shown, fmt.Sprintf("%s(@%d)", markers.ellipsis(), offset))
+		}
+		if isChanged[i] {
+			offsets = append(offsets, len(shown))
+		}
+		shown = append(shown, word)
+	}
+	if !keep[len(words)-1] {
+		shown = append(shown, markers.ellipsis())
+	}
+	return strings.Join(shown, " "), offsets
+}
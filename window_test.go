@@ -0,0 +1,50 @@
+package colorisediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWindowWords(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           string
+		changed     []int
+		window      int
+		want        string
+		wantChanged []int
+	}{
+		{name: "middle", s: `"one two three four five six seven"`, changed: []int{3}, window: 4, want: `…(@8) three four five …`, wantChanged: []int{2}},
+		{name: "start", s: `"one two three four five six seven"`, changed: []int{0}, window: 4, want: `"one two …`, wantChanged: []int{0}},
+		{name: "end", s: `"one two three four five six seven"`, changed: []int{6}, window: 3, want: `…(@24) six seven"`, wantChanged: []int{2}},
+		{name: "two regions", s: `"a b c d e f g h i j"`, changed: []int{1, 8}, window: 2, want: `"a b c …(@14) h i j"`, wantChanged: []int{1, 5}},
+		{name: "nothing left out", s: `"a b c"`, changed: []int{1}, window: 10, want: `"a b c"`, wantChanged: []int{1}},
+		{name: "disabled", s: `"a b c d e f"`, changed: []int{1}, window: 0, want: `"a b c d e f"`, wantChanged: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := windowWords(tt.s, tt.changed, tt.window, Markers{})
+			if got != tt.want || !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("windowWords() = %q, %v, want %q, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestWithStringWindow(t *testing.T) {
+	paragraph := strings.Repeat("lorem ipsum dolor sit amet ", 40)
+	expected := []byte(`{"text":"` + paragraph + `quick fox ` + paragraph + `"}`)
+	actual := []byte(`{"text":"` + paragraph + `quick dog ` + paragraph + `"}`)
+
+	resp, err := CompareJSON(expected, actual, nil, true, WithStringWindow(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range []struct{ text, word string }{{resp.Expected, "fox"}, {resp.Actual, "dog"}} {
+		want := "…(@1071) sit amet quick " + side.word + " lorem ipsum …"
+		if got := strings.Join(strings.Fields(side.text), " "); !strings.Contains(got, want) {
+			t.Errorf("expected a window around the change, got:\n%s", side.text)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package colorisediff
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WrapANSI breaks text into lines of at most width visible characters, for output that mixes text with ANSI color
+// sequences. Escape sequences are never split and, like other control characters, don't count towards the width.
+// The colors active at the end of a line are reset there and re-opened at the start of the next line, so every line
+// can be printed, padded or cut on its own, e.g. in a table cell. Existing line breaks are kept; a width of 0 or
+// less only re-opens the colors.
+func WrapANSI(text string, width int) string {
+	return wrapANSI(text, width, Markers{})
+}
+
+// wrapANSI implements WrapANSI, ending the lines it breaks with the line break marker and starting the lines
+// continuing them with the continuation marker. Lines are broken early to leave room for the line break marker
+// unless the rest of the line fits without one.
+func wrapANSI(text string, width int, markers Markers) string {
+	lineBreak, continuation := markers.fit(width)
+	var output strings.Builder
+	var active sgrState // active holds the color attributes in effect.
+	lineLength := 0     // lineLength counts the visible characters of the current line.
+	lineStart := false  // lineStart is set after a line break until the active colors are re-opened.
+	reopen := func() {
+		if lineStart {
+			output.WriteString(active.sequence())
+			lineStart = false
+		}
+	}
+	endLine := func() {
+		if len(active) > 0 && !lineStart {
+			output.WriteString(ansiResetCode)
+		}
+		output.WriteByte('\n')
+		lineLength, lineStart = 0, true
+	}
+
+	for i := 0; i < len(text); {
+		if sequence := escapeSequenceAt(text, i); sequence != "" {
+			if isResetSequence(sequence) {
+				// Nothing needs re-opening if the colors are reset at the start of a line anyway.
+				lineStart = false
+			} else {
+				reopen()
+			}
+			active = active.apply(sequence)
+			output.WriteString(sequence)
+			i += len(sequence)
+			continue
+		}
+
+		char, size := utf8.DecodeRuneInString(text[i:])
+		raw := text[i : i+size]
+		i += size
+		wrapped := false // wrapped is set if the line is broken before the character.
+		switch {
+		case char == '\n':
+			endLine()
+			continue
+		case isControlCharacter(char):
+			reopen()
+			output.WriteString(raw)
+			continue
+		case width > 0 && lineLength >= width:
+			endLine()
+			wrapped = true
+		case lineBreak > 0 && lineLength >= width-lineBreak && !restFits(text, i-size, width-lineLength):
+			if len(active) > 0 && !lineStart {
+				output.WriteString(ansiResetCode)
+				lineStart = true
+			}
+			output.WriteString(markers.LineBreak)
+			endLine()
+			wrapped = true
+		}
+		if wrapped && continuation > 0 {
+			output.WriteString(markers.Continuation)
+			lineLength = continuation
+		}
+		reopen()
+		output.WriteString(raw)
+		lineLength++
+	}
+	return output.String()
+}
+
+// restFits reports whether the visible characters from index i of the text up to the next line break number at
+// most n.
+func restFits(text string, i, n int) bool {
+	for count := 0; i < len(text) && text[i] != '\n'; {
+		if sequence := escapeSequenceAt(text, i); sequence != "" {
+			i += len(sequence)
+			continue
+		}
+		char, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		if !isControlCharacter(char) {
+			if count++; count > n {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// escapeSequenceAt returns the ANSI escape sequence starting at index i of the text, or "" if there is none.
+func escapeSequenceAt(text string, i int) string {
+	if text[i] != '\x1b' {
+		return ""
+	}
+	if loc := ansiRegex.FindStringIndex(text[i:]); loc != nil && loc[0] == 0 {
+		return text[i : i+loc[1]]
+	}
+	return ""
+}
+
+// isResetSequence reports whether an escape sequence resets all colors.
+func isResetSequence(sequence string) bool {
+	return sequence == ansiResetCode || sequence == "\x1b[m"
+}
+
+// sgrState holds the parameters of the SGR (color and style) attributes in effect, in the order they were set.
+type sgrState []string
+
+// sgrOff maps the SGR parameters turning attributes off to a check for the parameters they cancel.
+var sgrOff = map[int]func(int) bool{
+	22: func(p int) bool { return p == 1 || p == 2 },
+	23: func(p int) bool { return p == 3 },
+	24: func(p int) bool { return p == 4 },
+	25: func(p int) bool { return p == 5 || p == 6 },
+	27: func(p int) bool { return p == 7 },
+	28: func(p int) bool { return p == 8 },
+	29: func(p int) bool { return p == 9 },
+	39: func(p int) bool { return (p >= 30 && p <= 38) || (p >= 90 && p <= 97) },
+	49: func(p int) bool { return (p >= 40 && p <= 48) || (p >= 100 && p <= 107) },
+}
+
+// apply returns the state after an escape sequence. Sequences other than SGR ones leave the state unchanged.
+func (s sgrState) apply(sequence string) sgrState {
+	if !strings.HasPrefix(sequence, "\x1b[") || !strings.HasSuffix(sequence, "m") {
+		return s
+	}
+	for _, param := range strings.Split(sequence[2:len(sequence)-1], ";") {
+		code, err := strconv.Atoi(param)
+		switch {
+		case param == "" || code == 0:
+			s = nil
+		case err != nil:
+		case sgrOff[code] != nil:
+			cancels := sgrOff[code]
+			kept := sgrState{}
+			for _, active := range s {
+				if activeCode, _ := strconv.Atoi(active); !cancels(activeCode) {
+					kept = append(kept, active)
+				}
+			}
+			s = kept
+		default:
+			s = append(s, param)
+		}
+	}
+	return s
+}
+
+// sequence returns the escape sequence setting the attributes of the state, or "" if none are set.
+func (s sgrState) sequence() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(s, ";") + "m"
+}
+
+// breakLines breaks the input string into lines of at most maxLineLength visible characters with WrapANSI.
+func breakLines(input string) string {
+	return WrapANSI(input, maxLineLength)
+}
+
+// breakWithColor applies color to specific ranges within the input string and breaks the string into lines with
+// WrapANSI, ending the result with a line break.
+// input: The string to be processed.
+// paint: The function applying color to the specified ranges. If nil, no color is applied.
+// highlightRanges: A slice of Range structs specifying the start and end byte indices for color application.
+func breakWithColor(input string, paint func(a ...interface{}) string, highlightRanges []colorRange) string {
+	return valueRenderers{}.breakWithColor(input, paint, highlightRanges)
+}
+
+// paintRanges applies color to the given byte ranges of the input string.
+func paintRanges(input string, paint func(a ...interface{}) string, highlightRanges []colorRange) string {
+	var painted strings.Builder
+	start, highlighted := 0, false
+	flush := func(end int) {
+		if end == start {
+			return
+		}
+		if highlighted && paint != nil {
+			painted.WriteString(paint(input[start:end]))
+		} else {
+			painted.WriteString(input[start:end])
+		}
+		start = end
+	}
+	for i := range input {
+		inRange := false
+		for _, r := range highlightRanges {
+			if i >= r.Start && i < r.End {
+				inRange = true
+				break
+			}
+		}
+		if inRange != highlighted {
+			flush(i)
+			highlighted = inRange
+		}
+	}
+	flush(len(input))
+	return painted.String()
+}
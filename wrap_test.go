@@ -0,0 +1,57 @@
+package colorisediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapANSI(t *testing.T) {
+	const green, dim, undim = "\x1b[32m", "\x1b[2m", "\x1b[22m"
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "plain", text: "abcdefg", width: 3, want: "abc\ndef\ng"},
+		{name: "exact width", text: "abc\ndef\n", width: 3, want: "abc\ndef\n"},
+		{name: "sequences are not counted", text: green + "abcd" + ansiResetCode + "ef", width: 4, want: green + "abcd" + ansiResetCode + "\nef"},
+		{name: "colors are re-opened after a wrap", text: green + "abcdef" + ansiResetCode, width: 4, want: green + "abcd" + ansiResetCode + "\n" + green + "ef" + ansiResetCode},
+		{name: "colors are re-opened after a line break", text: green + "ab\ncd" + ansiResetCode, width: 0, want: green + "ab" + ansiResetCode + "\n" + green + "cd" + ansiResetCode},
+		{name: "reset at the start of a line", text: green + "ab\n" + ansiResetCode + "cd", width: 0, want: green + "ab" + ansiResetCode + "\n" + ansiResetCode + "cd"},
+		{name: "attributes turned off", text: dim + "ab" + undim + "\ncd", width: 0, want: dim + "ab" + undim + "\ncd"},
+		{name: "combined attributes", text: "\x1b[1;31mabcd", width: 2, want: "\x1b[1;31mab" + ansiResetCode + "\n\x1b[1;31mcd"},
+		{name: "control characters are not counted", text: "a\tbc", width: 3, want: "a\tbc"},
+		{name: "multi-byte characters", text: "äöüß", width: 2, want: "äö\nüß"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapANSI(tt.text, tt.width)
+			if got != tt.want {
+				t.Errorf("WrapANSI(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			if again := WrapANSI(got, tt.width); again != got {
+				t.Errorf("WrapANSI is not idempotent: %q became %q", got, again)
+			}
+		})
+	}
+}
+
+func TestBreakWithColor(t *testing.T) {
+	paint := palette{}.sprintFunc(31)
+	got := breakWithColor("key: value", paint, []colorRange{{Start: 5, End: 10}})
+	if want := "key: \x1b[31mvalue\x1b[0m\n"; got != want {
+		t.Errorf("breakWithColor() = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("x", maxLineLength+5)
+	got = breakWithColor(long, paint, []colorRange{{Start: 0, End: len(long)}})
+	want := "\x1b[31m" + strings.Repeat("x", maxLineLength) + ansiResetCode + "\n\x1b[31mxxxxx\x1b[0m\n"
+	if got != want {
+		t.Errorf("breakWithColor() = %q, want %q", got, want)
+	}
+
+	if got := breakWithColor("plain", nil, []colorRange{{Start: 0, End: 5}}); got != "plain\n" {
+		t.Errorf("breakWithColor() without paint = %q", got)
+	}
+}